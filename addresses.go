@@ -0,0 +1,137 @@
+// addresses.go contains the logic of --all-addresses, probing every resolved address of a hostname
+package main
+
+import (
+	"net"
+	"net/netip"
+	"slices"
+)
+
+// addressStats is the result of the probes of one address of a hostname.
+type addressStats struct {
+	Addr                    netip.Addr  `json:"addr"`
+	State                   targetState `json:"state"`
+	TotalSuccessfulProbes   uint        `json:"total_successful_probes"`
+	TotalUnsuccessfulProbes uint        `json:"total_unsuccessful_probes"`
+	// PacketLoss is in percent.
+	PacketLoss float64 `json:"total_packet_loss"`
+	// LatencyAvg is in ms, 0 if no probe succeeded.
+	LatencyAvg float32 `json:"latency_avg"`
+}
+
+// failing reports whether the address is down or flapping.
+func (a addressStats) failing() bool {
+	return a.State != stateUp
+}
+
+// addressSummary summarizes the addresses of a hostname probed by --all-addresses.
+type addressSummary struct {
+	Hostname  string         `json:"hostname"`
+	Port      uint16         `json:"port"`
+	Addresses []addressStats `json:"addresses"`
+}
+
+// target returns the "host:port" label of the hostname.
+func (s addressSummary) target() string {
+	return (userInput{hostname: s.Hostname, port: s.Port}).hostPort()
+}
+
+// failing returns the addresses that are down or flapping.
+func (s addressSummary) failing() []netip.Addr {
+	var failing []netip.Addr
+	for _, a := range s.Addresses {
+		if a.failing() {
+			failing = append(failing, a.Addr)
+		}
+	}
+	return failing
+}
+
+// addressTargets creates a target for every address of the hostname of target,
+// so that each of them is probed on every interval and has statistics of its own.
+func addressTargets(target *tcping, addrs []netip.Addr) []*tcping {
+	targets := make([]*tcping, 0, len(addrs))
+	for _, addr := range addrs {
+		t := newAddressTarget(target, addr)
+		t.userInput.allAddresses = true
+		targets = append(targets, t)
+	}
+
+	return targets
+}
+
+// newAddressTarget creates a target probing one address of the hostname of target.
+//
+// The address is fixed, so -r doesn't resolve the hostname again.
+func newAddressTarget(target *tcping, addr netip.Addr) *tcping {
+	t := &tcping{
+		printer:    target.printer,
+		exporter:   target.exporter,
+		alerts:     slices.Clone(target.alerts),
+		userInput:  target.userInput,
+		startTime:  target.startTime,
+		pendingDNS: target.pendingDNS,
+		dns:        target.dns,
+	}
+
+	t.userInput.ip = addr
+	t.userInput.shouldRetryResolve = false
+	t.userInput.resolveTTL = false
+	t.hostnameChanges = []hostnameChange{{addr, t.startTime}}
+
+	if t.userInput.networkInterface.use {
+		t.userInput.networkInterface.remoteAddr = &net.TCPAddr{
+			IP:   addr.AsSlice(),
+			Port: int(t.userInput.port),
+		}
+	}
+
+	return t
+}
+
+// newAddressStats creates the result of the probes of the address of t.
+func newAddressStats(t *tcping) addressStats {
+	a := addressStats{
+		Addr:                    t.userInput.ip,
+		State:                   t.state(),
+		TotalSuccessfulProbes:   t.totalSuccessfulProbes,
+		TotalUnsuccessfulProbes: t.totalUnsuccessfulProbes,
+	}
+
+	if total := a.TotalSuccessfulProbes + a.TotalUnsuccessfulProbes; total > 0 {
+		a.PacketLoss = float64(a.TotalUnsuccessfulProbes) / float64(total) * 100
+	}
+
+	if result := t.rtt.result(); result.hasResults {
+		a.LatencyAvg = result.average
+	}
+
+	return a
+}
+
+// addressSummaries groups the targets of --all-addresses by hostname and port,
+// in the order of the targets.
+func addressSummaries(targets []*tcping) []addressSummary {
+	var summaries []addressSummary
+	index := map[string]int{}
+
+	for _, t := range targets {
+		if !t.userInput.allAddresses {
+			continue
+		}
+
+		key := t.userInput.hostPort()
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, addressSummary{
+				Hostname: t.userInput.hostname,
+				Port:     t.userInput.port,
+			})
+		}
+		summaries[i].Addresses = append(summaries[i].Addresses, newAddressStats(t))
+	}
+
+	return summaries
+}
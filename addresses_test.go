@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressTargets(t *testing.T) {
+	base := createTestStats(t)
+	base.userInput.hostname = "example.com"
+	base.userInput.port = 443
+	base.userInput.shouldRetryResolve = true
+	base.startTime = time.Now()
+
+	addrs := []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("2001:db8::1")}
+	targets := addressTargets(base, addrs)
+	require.Len(t, targets, 2)
+
+	for i, target := range targets {
+		assert.Equal(t, addrs[i], target.userInput.ip)
+		assert.True(t, target.userInput.allAddresses)
+		assert.False(t, target.userInput.shouldRetryResolve, "the address of a target is fixed")
+		assert.False(t, target.destIsIP)
+		assert.Equal(t, "example.com:443", target.userInput.hostPort())
+		assert.Equal(t, []hostnameChange{{addrs[i], base.startTime}}, target.hostnameChanges)
+	}
+
+	assert.Equal(t, "example.com:443 (192.0.2.1)", targets[0].userInput.target())
+	assert.Equal(t, "example.com:443 (2001:db8::1)", targets[1].userInput.target())
+
+	// targets must not share their state
+	targets[0].totalSuccessfulProbes++
+	assert.Zero(t, targets[1].totalSuccessfulProbes)
+}
+
+func TestFilterResolvedIPs(t *testing.T) {
+	stats := createTestStats(t)
+	addrs := []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("::ffff:192.0.2.1"),
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("192.0.2.2"),
+	}
+
+	all, err := filterResolvedIPs(stats, addrs)
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("192.0.2.2"),
+	}, all)
+
+	stats.userInput.useIPv6 = true
+	v6, err := filterResolvedIPs(stats, addrs[1:3])
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("2001:db8::1")}, v6)
+
+	_, err = filterResolvedIPs(stats, addrs[3:])
+	assert.Error(t, err)
+}
+
+func TestAddressSummaries(t *testing.T) {
+	base := createTestStats(t)
+	base.userInput.hostname = "example.com"
+	base.userInput.port = 443
+
+	addrs := []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2")}
+	targets := addressTargets(base, addrs)
+
+	targets[0].totalSuccessfulProbes = 3
+	targets[0].rtt.add(10)
+	targets[1].totalSuccessfulProbes = 1
+	targets[1].totalUnsuccessfulProbes = 3
+	targets[1].destWasDown = true
+
+	// targets of other modes are not summarized
+	other := createTestStats(t)
+	summaries := addressSummaries(append(targets, other))
+	require.Len(t, summaries, 1)
+
+	summary := summaries[0]
+	assert.Equal(t, "example.com:443", summary.target())
+	assert.Equal(t, []addressStats{
+		{Addr: addrs[0], State: stateUp, TotalSuccessfulProbes: 3, LatencyAvg: 10},
+		{Addr: addrs[1], State: stateDown, TotalSuccessfulProbes: 1, TotalUnsuccessfulProbes: 3, PacketLoss: 75},
+	}, summary.Addresses)
+	assert.Equal(t, []netip.Addr{addrs[1]}, summary.failing())
+}
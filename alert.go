@@ -0,0 +1,320 @@
+// alert.go contains the alert hooks, running a command or posting a webhook
+// when a target goes down or comes back up
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultAlertThreshold is the default number of consecutive failures before a down alert.
+	defaultAlertThreshold = 3
+	// defaultAlertCooldown is the default minimum time between two down alerts of a hook, in seconds.
+	defaultAlertCooldown = 300
+	// alertTimeout bounds the time a command or a webhook may take.
+	alertTimeout = 30 * time.Second
+	// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint, used when no webhook URL is given.
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// alertState is the state of a target reported by an alert.
+type alertState string
+
+const (
+	alertStateDown alertState = "down"
+	alertStateUp   alertState = "up"
+)
+
+// Webhook formats of --alert-webhook-format.
+const (
+	webhookFormatGeneric   = "generic"
+	webhookFormatSlack     = "slack"
+	webhookFormatPagerDuty = "pagerduty"
+)
+
+// pendingAlerts tracks the alerts being sent, so that they are not cut off on exit.
+var pendingAlerts sync.WaitGroup
+
+// alertEvent describes a state change of a target.
+type alertEvent struct {
+	Target    string     `json:"target"`
+	Hostname  string     `json:"hostname,omitempty"`
+	IP        string     `json:"ip"`
+	Port      uint16     `json:"port"`
+	State     alertState `json:"state"`
+	Streak    uint       `json:"streak"`
+	Downtime  float64    `json:"downtime"` // Downtime is in seconds
+	ErrorKind errorKind  `json:"error_kind,omitempty"`
+	Message   string     `json:"message"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// alertNotifier delivers an alert.
+type alertNotifier interface {
+	notify(ctx context.Context, event alertEvent) error
+}
+
+// alertHook sends down alerts once a target failed threshold times in a row,
+// at most once per cooldown, and an up alert when such a target recovers.
+type alertHook struct {
+	notifier  alertNotifier
+	threshold uint
+	cooldown  time.Duration
+	lastAlert time.Time // lastAlert is the time of the last down alert
+	alerted   bool      // alerted is true when a down alert was sent for the current outage
+}
+
+// alertOptions holds the flags of the alert hooks.
+type alertOptions struct {
+	command       string
+	webhookURL    string
+	webhookFormat string
+	routingKey    string
+	threshold     uint
+	cooldown      float64
+}
+
+// newAlertHooks creates a hook for the command and for the webhook, if given.
+func newAlertHooks(opts alertOptions) ([]alertHook, error) {
+	if opts.threshold == 0 {
+		return nil, msgErr("alert.threshold")
+	}
+	if opts.cooldown < 0 {
+		return nil, msgErr("alert.cooldown")
+	}
+
+	var notifiers []alertNotifier
+
+	if opts.command != "" {
+		notifiers = append(notifiers, commandNotifier{command: opts.command})
+	}
+
+	switch opts.webhookFormat {
+	case webhookFormatGeneric, webhookFormatSlack:
+		if opts.webhookURL != "" {
+			notifiers = append(notifiers, webhookNotifier{url: opts.webhookURL, format: opts.webhookFormat})
+		}
+	case webhookFormatPagerDuty:
+		if opts.routingKey == "" {
+			return nil, msgErr("alert.routing-key")
+		}
+		url := opts.webhookURL
+		if url == "" {
+			url = pagerDutyEventsURL
+		}
+		notifiers = append(notifiers, webhookNotifier{url: url, format: opts.webhookFormat, routingKey: opts.routingKey})
+	default:
+		return nil, fmt.Errorf(msg("alert.webhook-format"), opts.webhookFormat)
+	}
+
+	hooks := make([]alertHook, 0, len(notifiers))
+	for _, n := range notifiers {
+		hooks = append(hooks, alertHook{
+			notifier:  n,
+			threshold: opts.threshold,
+			cooldown:  secondsToDuration(opts.cooldown),
+		})
+	}
+
+	return hooks, nil
+}
+
+// newAlertEvent creates the event of a state change of the target of t.
+func (t *tcping) newAlertEvent(state alertState, streak uint, downtime time.Duration, kind errorKind) alertEvent {
+	event := alertEvent{
+		Target:    t.userInput.target(),
+		Hostname:  t.userInput.hostname,
+		IP:        t.userInput.ip.String(),
+		Port:      t.userInput.port,
+		State:     state,
+		Streak:    streak,
+		Downtime:  downtime.Seconds(),
+		ErrorKind: kind,
+		Timestamp: time.Now(),
+	}
+
+	if state == alertStateDown {
+		event.Message = msgf("alert.down", event.Target, streak, kind.description())
+	} else {
+		event.Message = msgf("alert.up", event.Target, durationToString(downtime))
+	}
+
+	return event
+}
+
+// alertDown is called after every failed probe and sends
+// a down alert through every hook whose threshold is reached.
+//
+// The threshold is compared to consecutiveFailures, which
+// unlike ongoingUnsuccessfulProbes is not reset by -r.
+func (t *tcping) alertDown(kind errorKind) {
+	if !t.destWasDown {
+		return
+	}
+
+	for i := range t.alerts {
+		hook := &t.alerts[i]
+		if hook.alerted || t.consecutiveFailures < hook.threshold {
+			continue
+		}
+
+		now := time.Now()
+		if !hook.lastAlert.IsZero() && now.Sub(hook.lastAlert) < hook.cooldown {
+			continue
+		}
+
+		hook.alerted = true
+		hook.lastAlert = now
+		t.sendAlert(hook.notifier, t.newAlertEvent(alertStateDown, t.consecutiveFailures, now.Sub(t.startOfDowntime), kind))
+	}
+}
+
+// alertUp is called when the target recovers and sends an up
+// alert through every hook that sent a down alert for the outage.
+func (t *tcping) alertUp(downtime time.Duration) {
+	for i := range t.alerts {
+		hook := &t.alerts[i]
+		if !hook.alerted {
+			continue
+		}
+
+		hook.alerted = false
+		t.sendAlert(hook.notifier, t.newAlertEvent(alertStateUp, t.ongoingSuccessfulProbes, downtime, ""))
+	}
+}
+
+// sendAlert delivers event in the background, so that probing is not delayed.
+func (t *tcping) sendAlert(n alertNotifier, event alertEvent) {
+	pendingAlerts.Add(1)
+	go func() {
+		defer pendingAlerts.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+
+		if err := n.notify(ctx, event); err != nil {
+			t.printWarning(msg("alert.send-failed"), err)
+		}
+	}()
+}
+
+// waitForAlerts waits until every pending alert is sent, or the timeout expires.
+func waitForAlerts(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		pendingAlerts.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// commandNotifier runs a shell command with the event in TCPING_* environment variables.
+type commandNotifier struct {
+	command string
+}
+
+func (n commandNotifier) notify(ctx context.Context, event alertEvent) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.command)
+	}
+
+	cmd.Env = append(os.Environ(),
+		"TCPING_TARGET="+event.Target,
+		"TCPING_HOSTNAME="+event.Hostname,
+		"TCPING_IP="+event.IP,
+		"TCPING_PORT="+strconv.Itoa(int(event.Port)),
+		"TCPING_STATE="+string(event.State),
+		"TCPING_STREAK="+strconv.FormatUint(uint64(event.Streak), 10),
+		"TCPING_DOWNTIME="+strconv.FormatFloat(event.Downtime, 'f', 3, 64),
+		"TCPING_ERROR_KIND="+string(event.ErrorKind),
+		"TCPING_MESSAGE="+event.Message,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf(msg("alert.command-failed"), n.command, err, bytes.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// webhookNotifier POSTs the event as JSON in one of the webhook formats.
+type webhookNotifier struct {
+	url        string
+	format     string
+	routingKey string // routingKey is the integration key of the pagerduty format
+}
+
+func (n webhookNotifier) notify(ctx context.Context, event alertEvent) error {
+	body, err := json.Marshal(n.payload(event))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tcping/"+version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf(msg("alert.webhook-status"), n.url, resp.Status)
+	}
+
+	return nil
+}
+
+// payload returns the body of the webhook in the format of n.
+func (n webhookNotifier) payload(event alertEvent) any {
+	switch n.format {
+	case webhookFormatSlack:
+		return map[string]string{"text": event.Message}
+
+	case webhookFormatPagerDuty:
+		action := "trigger"
+		if event.State == alertStateUp {
+			action = "resolve"
+		}
+		return map[string]any{
+			"routing_key":  n.routingKey,
+			"event_action": action,
+			// the same key resolves the incident triggered by the down alert
+			"dedup_key": "tcping-" + event.Target,
+			"payload": map[string]any{
+				"summary":        event.Message,
+				"source":         event.Target,
+				"severity":       "critical",
+				"timestamp":      event.Timestamp.Format(time.RFC3339),
+				"custom_details": event,
+			},
+		}
+
+	default:
+		return event
+	}
+}
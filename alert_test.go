@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier sends every event it's notified of to a channel.
+type recordingNotifier chan alertEvent
+
+func (n recordingNotifier) notify(_ context.Context, event alertEvent) error {
+	n <- event
+	return nil
+}
+
+// receiveAlert waits for the next event of n.
+func receiveAlert(t *testing.T, n recordingNotifier) alertEvent {
+	t.Helper()
+
+	select {
+	case event := <-n:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert was sent")
+		return alertEvent{}
+	}
+}
+
+// assertNoAlert checks that n has not been notified.
+func assertNoAlert(t *testing.T, n recordingNotifier) {
+	t.Helper()
+
+	waitForAlerts(time.Second)
+	select {
+	case event := <-n:
+		t.Fatalf("unexpected alert: %+v", event)
+	default:
+	}
+}
+
+func TestAlertThreshold(t *testing.T) {
+	n := make(recordingNotifier, 10)
+	stats := createTestStats(t)
+	stats.alerts = []alertHook{{notifier: n, threshold: 3}}
+
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	assertNoAlert(t, n)
+
+	stats.handleConnError(time.Now(), time.Second, errorKindRefused, probeInfo{})
+	down := receiveAlert(t, n)
+	assert.Equal(t, alertStateDown, down.State)
+	assert.Equal(t, uint(3), down.Streak)
+	assert.Equal(t, errorKindRefused, down.ErrorKind)
+	assert.Equal(t, "127.0.0.1:12345", down.Target)
+
+	// only one alert is sent per outage
+	stats.handleConnError(time.Now(), time.Second, errorKindRefused, probeInfo{})
+	assertNoAlert(t, n)
+
+	stats.handleConnSuccess("", 1, time.Now().Add(time.Minute), time.Second, probeInfo{})
+	up := receiveAlert(t, n)
+	assert.Equal(t, alertStateUp, up.State)
+	assert.Positive(t, up.Downtime)
+}
+
+func TestAlertThresholdWithRetryResolve(t *testing.T) {
+	n := make(recordingNotifier, 10)
+	stats := createTestStats(t)
+	stats.userInput.hostname = "127.0.0.1"
+	stats.userInput.shouldRetryResolve = true
+	stats.userInput.retryHostnameLookupAfter = 3
+	stats.alerts = []alertHook{{notifier: n, threshold: 5}}
+
+	// -r resets the streak printed with the probes after 3 failures
+	for range 4 {
+		resolveBeforeProbe(stats)
+		stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	}
+	assertNoAlert(t, n)
+
+	resolveBeforeProbe(stats)
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	down := receiveAlert(t, n)
+	assert.Equal(t, alertStateDown, down.State)
+	assert.Equal(t, uint(5), down.Streak)
+	assert.Equal(t, uint(1), stats.retriedHostnameLookups)
+}
+
+func TestAlertSkipsShortOutages(t *testing.T) {
+	n := make(recordingNotifier, 10)
+	stats := createTestStats(t)
+	stats.alerts = []alertHook{{notifier: n, threshold: 2}}
+
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+
+	assertNoAlert(t, n)
+}
+
+func TestAlertCooldown(t *testing.T) {
+	n := make(recordingNotifier, 10)
+	stats := createTestStats(t)
+	stats.alerts = []alertHook{{notifier: n, threshold: 1, cooldown: time.Hour}}
+
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	assert.Equal(t, alertStateDown, receiveAlert(t, n).State)
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	assert.Equal(t, alertStateUp, receiveAlert(t, n).State)
+
+	// the second outage is within the cooldown, so neither alert is sent
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	assertNoAlert(t, n)
+}
+
+func TestAlertAfterReset(t *testing.T) {
+	n := make(recordingNotifier, 10)
+	stats := createTestStats(t)
+	stats.alerts = []alertHook{{notifier: n, threshold: 1}}
+
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	assert.Equal(t, alertStateDown, receiveAlert(t, n).State)
+
+	// the outage is still alerted as over after a reset
+	stats.resetStats()
+	assert.Equal(t, stateDown, stats.state())
+	stats.handleConnSuccess("", 1, time.Now().Add(time.Minute), time.Second, probeInfo{})
+	up := receiveAlert(t, n)
+	assert.Equal(t, alertStateUp, up.State)
+	assert.Positive(t, up.Downtime)
+
+	// and the next outage is alerted again
+	stats.handleConnError(time.Now(), time.Second, errorKindRefused, probeInfo{})
+	assert.Equal(t, alertStateDown, receiveAlert(t, n).State)
+}
+
+func TestWebhookFormats(t *testing.T) {
+	bodies := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+
+	event := alertEvent{Target: "example.com:443", State: alertStateUp, Message: "recovered"}
+
+	tests := []struct {
+		format string
+		check  func(body map[string]any)
+	}{
+		{webhookFormatGeneric, func(body map[string]any) {
+			assert.Equal(t, "example.com:443", body["target"])
+			assert.Equal(t, "up", body["state"])
+		}},
+		{webhookFormatSlack, func(body map[string]any) {
+			assert.Equal(t, map[string]any{"text": "recovered"}, body)
+		}},
+		{webhookFormatPagerDuty, func(body map[string]any) {
+			assert.Equal(t, "key", body["routing_key"])
+			assert.Equal(t, "resolve", body["event_action"])
+			assert.Equal(t, "tcping-example.com:443", body["dedup_key"])
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			n := webhookNotifier{url: srv.URL, format: tt.format, routingKey: "key"}
+			require.NoError(t, n.notify(context.Background(), event))
+			tt.check(<-bodies)
+		})
+	}
+}
+
+func TestWebhookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	n := webhookNotifier{url: srv.URL, format: webhookFormatGeneric}
+	assert.Error(t, n.notify(context.Background(), alertEvent{}))
+}
+
+// warningPrinter sends every warning it prints to a channel and fails the test on errors.
+type warningPrinter struct {
+	dummyPrinter
+	t        *testing.T
+	warnings chan string
+}
+
+func (p *warningPrinter) printWarning(format string, args ...any) {
+	p.warnings <- fmt.Sprintf(format, args...)
+}
+
+func (p *warningPrinter) printError(format string, args ...any) {
+	p.t.Errorf("unexpected error: "+format, args...)
+}
+
+func TestAlertSendFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	p := &warningPrinter{t: t, warnings: make(chan string, 1)}
+	stats := createTestStats(t)
+	stats.printer = p
+	stats.alerts = []alertHook{{notifier: webhookNotifier{url: srv.URL, format: webhookFormatGeneric}, threshold: 1}}
+
+	// a failed alert is only a warning, since printError exits with --db
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	waitForAlerts(5 * time.Second)
+	select {
+	case warning := <-p.warnings:
+		assert.Contains(t, warning, "500")
+	default:
+		t.Fatal("the failed alert was not reported")
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	out := filepath.Join(t.TempDir(), "alert.txt")
+	n := commandNotifier{command: `echo "$TCPING_TARGET $TCPING_STATE $TCPING_STREAK $TCPING_ERROR_KIND" > ` + out}
+
+	event := alertEvent{Target: "example.com:443", State: alertStateDown, Streak: 3, ErrorKind: errorKindTimeout}
+	require.NoError(t, n.notify(context.Background(), event))
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com:443 down 3 timeout", strings.TrimSpace(string(content)))
+
+	assert.Error(t, commandNotifier{command: "exit 1"}.notify(context.Background(), event))
+}
+
+func TestNewAlertHooks(t *testing.T) {
+	hooks, err := newAlertHooks(alertOptions{
+		command:       "true",
+		webhookURL:    "http://localhost/hook",
+		webhookFormat: webhookFormatSlack,
+		threshold:     2,
+		cooldown:      1.5,
+	})
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, uint(2), hooks[1].threshold)
+	assert.Equal(t, 1500*time.Millisecond, hooks[1].cooldown)
+
+	hooks, err = newAlertHooks(alertOptions{webhookFormat: webhookFormatPagerDuty, routingKey: "key", threshold: 1})
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, pagerDutyEventsURL, hooks[0].notifier.(webhookNotifier).url)
+
+	for _, opts := range []alertOptions{
+		{command: "true", webhookFormat: webhookFormatGeneric},
+		{webhookFormat: webhookFormatPagerDuty, threshold: 1},
+		{webhookFormat: "teams", threshold: 1},
+		{command: "true", webhookFormat: webhookFormatGeneric, threshold: 1, cooldown: -1},
+	} {
+		_, err := newAlertHooks(opts)
+		assert.Error(t, err, opts)
+	}
+}
@@ -0,0 +1,124 @@
+// check.go contains the logic of --check, exiting with a status telling whether the target is healthy
+package main
+
+const (
+	// defaultCheckProbes is the number of probes of --check when -c is not given.
+	defaultCheckProbes = 5
+	// defaultMaxLoss is the default packet loss threshold of --check, in percent.
+	// A loss of 100% is never above it, so only targets without any successful probe fail.
+	defaultMaxLoss = 100
+)
+
+// checkStatus is the outcome of --check for a target.
+//
+// Its value is the exit code of tcping, and a smaller non-zero
+// value is a more severe failure.
+type checkStatus int
+
+const (
+	checkHealthy   checkStatus = 0
+	checkNoSuccess checkStatus = 2
+	checkLoss      checkStatus = 3
+	checkAvgRTT    checkStatus = 4
+	checkP95RTT    checkStatus = 5
+)
+
+// String returns the stable name of the status, used in JSON, CSV and the database.
+func (s checkStatus) String() string {
+	switch s {
+	case checkHealthy:
+		return "healthy"
+	case checkNoSuccess:
+		return "no_success"
+	case checkLoss:
+		return "loss"
+	case checkAvgRTT:
+		return "avg_rtt"
+	case checkP95RTT:
+		return "p95_rtt"
+	default:
+		return "unknown"
+	}
+}
+
+// worse returns the more severe of s and other.
+func (s checkStatus) worse(other checkStatus) checkStatus {
+	switch {
+	case s == checkHealthy:
+		return other
+	case other == checkHealthy:
+		return s
+	default:
+		return min(s, other)
+	}
+}
+
+// checkOptions holds the thresholds of --check.
+type checkOptions struct {
+	maxLoss   float64 // maxLoss is the packet loss threshold in percent
+	maxAvgRTT float64 // maxAvgRTT is the average RTT threshold in ms, 0 disables it
+	maxP95RTT float64 // maxP95RTT is the p95 RTT threshold in ms, 0 disables it
+}
+
+// validate returns an error if a threshold is out of range.
+func (o checkOptions) validate() error {
+	if o.maxLoss < 0 || o.maxLoss > 100 {
+		return msgErr("check.max-loss")
+	}
+	if o.maxAvgRTT < 0 || o.maxP95RTT < 0 {
+		return msgErr("check.negative-rtt")
+	}
+	return nil
+}
+
+// checkResult is the outcome of --check for a target,
+// with the measured value and the threshold it exceeded.
+type checkResult struct {
+	status    checkStatus
+	value     float64
+	threshold float64
+}
+
+// description returns a human-readable outcome.
+func (r checkResult) description() string {
+	switch r.status {
+	case checkHealthy:
+		return msg("check.healthy")
+	case checkNoSuccess:
+		return msg("check.no-success")
+	case checkLoss:
+		return msgf("check.loss", r.value, r.threshold)
+	case checkAvgRTT:
+		return msgf("check.avg-rtt", r.value, r.threshold)
+	case checkP95RTT:
+		return msgf("check.p95-rtt", r.value, r.threshold)
+	default:
+		return msg("check.failed")
+	}
+}
+
+// healthCheck compares the statistics of t with the thresholds of --check.
+// The RTT results should be calculated beforehand, as in printStats.
+func (t *tcping) healthCheck() checkResult {
+	opts := t.userInput.check
+	if opts == nil {
+		return checkResult{status: checkHealthy}
+	}
+	if t.totalSuccessfulProbes == 0 {
+		return checkResult{status: checkNoSuccess}
+	}
+
+	totalPackets := t.totalSuccessfulProbes + t.totalUnsuccessfulProbes
+	loss := float64(t.totalUnsuccessfulProbes) / float64(totalPackets) * 100
+
+	switch {
+	case loss > opts.maxLoss:
+		return checkResult{status: checkLoss, value: loss, threshold: opts.maxLoss}
+	case opts.maxAvgRTT > 0 && float64(t.rttResults.average) > opts.maxAvgRTT:
+		return checkResult{status: checkAvgRTT, value: float64(t.rttResults.average), threshold: opts.maxAvgRTT}
+	case opts.maxP95RTT > 0 && float64(t.rttResults.p95) > opts.maxP95RTT:
+		return checkResult{status: checkP95RTT, value: float64(t.rttResults.p95), threshold: opts.maxP95RTT}
+	default:
+		return checkResult{status: checkHealthy}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newCheckStats creates a target with the given probe results and thresholds.
+func newCheckStats(t *testing.T, opts checkOptions, rtts []float32, failures int) *tcping {
+	stats := createTestStats(t)
+	stats.userInput.check = &opts
+
+	for _, rtt := range rtts {
+		stats.handleConnSuccess("", rtt, time.Now(), time.Second, probeInfo{})
+	}
+	for range failures {
+		stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	}
+	stats.rttResults = stats.rtt.result()
+
+	return stats
+}
+
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     checkOptions
+		rtts     []float32
+		failures int
+		want     checkResult
+	}{
+		{"healthy", checkOptions{maxLoss: 100}, []float32{10, 20}, 1, checkResult{status: checkHealthy}},
+		{"no success", checkOptions{maxLoss: 100}, nil, 3, checkResult{status: checkNoSuccess}},
+		{"no probes", checkOptions{maxLoss: 100}, nil, 0, checkResult{status: checkNoSuccess}},
+		{"loss", checkOptions{maxLoss: 20}, []float32{10, 10, 10}, 1, checkResult{status: checkLoss, value: 25, threshold: 20}},
+		{"loss at threshold", checkOptions{maxLoss: 25}, []float32{10, 10, 10}, 1, checkResult{status: checkHealthy}},
+		{"avg rtt", checkOptions{maxLoss: 100, maxAvgRTT: 15}, []float32{10, 30}, 0, checkResult{status: checkAvgRTT, value: 20, threshold: 15}},
+		{"p95 rtt", checkOptions{maxLoss: 100, maxAvgRTT: 150, maxP95RTT: 50}, []float32{100, 100, 100}, 0, checkResult{status: checkP95RTT, value: 100, threshold: 50}},
+		{"loss before rtt", checkOptions{maxLoss: 0, maxAvgRTT: 1}, []float32{10}, 1, checkResult{status: checkLoss, value: 50, threshold: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := newCheckStats(t, tt.opts, tt.rtts, tt.failures)
+			got := stats.healthCheck()
+
+			assert.Equal(t, tt.want.status, got.status)
+			assert.InDelta(t, tt.want.value, got.value, 0.01)
+			assert.Equal(t, tt.want.threshold, got.threshold)
+		})
+	}
+}
+
+func TestHealthCheckSlowestProbe(t *testing.T) {
+	// with the default 5 probes, p95 lies between the two slowest RTTs
+	stats := newCheckStats(t, checkOptions{maxLoss: 100, maxP95RTT: 100}, []float32{10, 10, 10, 10, 500}, 0)
+	got := stats.healthCheck()
+
+	assert.Equal(t, checkP95RTT, got.status)
+	assert.InEpsilon(t, 402, got.value, sketchRelativeAccuracy)
+}
+
+func TestHealthCheckDisabled(t *testing.T) {
+	stats := createTestStats(t)
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+
+	assert.Equal(t, checkHealthy, stats.healthCheck().status)
+}
+
+func TestCheckStatusWorse(t *testing.T) {
+	assert.Equal(t, checkHealthy, checkHealthy.worse(checkHealthy))
+	assert.Equal(t, checkP95RTT, checkHealthy.worse(checkP95RTT))
+	assert.Equal(t, checkLoss, checkLoss.worse(checkHealthy))
+	assert.Equal(t, checkNoSuccess, checkAvgRTT.worse(checkNoSuccess))
+	assert.Equal(t, checkLoss, checkLoss.worse(checkP95RTT))
+}
+
+func TestCheckOptionsValidate(t *testing.T) {
+	assert.NoError(t, checkOptions{maxLoss: 0}.validate())
+	assert.NoError(t, checkOptions{maxLoss: 100, maxAvgRTT: 10, maxP95RTT: 20}.validate())
+	assert.Error(t, checkOptions{maxLoss: -1}.validate())
+	assert.Error(t, checkOptions{maxLoss: 101}.validate())
+	assert.Error(t, checkOptions{maxLoss: 10, maxP95RTT: -1}.validate())
+}
@@ -0,0 +1,217 @@
+// config.go contains the YAML configuration file and its profiles
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are the file names looked up in the tcping configuration directory.
+var configFileNames = []string{"config.yaml", "config.yml"}
+
+// configAliases maps readable option names of the configuration file to their flags.
+// Every other option uses the name of its flag, e.g. "show-failures-only" or "tls".
+var configAliases = map[string]string{
+	"ipv4":          "4",
+	"ipv6":          "6",
+	"retry-resolve": "r",
+	"count":         "c",
+	"json":          "j",
+	"timestamp":     "D",
+	"interval":      "i",
+	"timeout":       "t",
+	"interface":     "I",
+	"targets-file":  "f",
+}
+
+// configIgnoredFlags are the flags that make no sense in a configuration file.
+var configIgnoredFlags = []string{"config", "p", "v", "u", "h"}
+
+// configFile is the YAML configuration file.
+//
+// Options at the top level apply to every run. A profile, chosen with -p,
+// bundles its own targets and options, which take precedence over the
+// top-level ones. Flags given on the command line override both.
+//
+//	interval: 2
+//	profiles:
+//	  prod-db:
+//	    host: db.example.com
+//	    port: 5432
+//	    timeout: 3
+//	    json: true
+type configFile struct {
+	Options  map[string]any           `yaml:",inline"`
+	Profiles map[string]configProfile `yaml:"profiles"`
+}
+
+// configProfile is a named set of targets and options.
+type configProfile struct {
+	Host    string         `yaml:"host"`
+	Port    uint16         `yaml:"port"`
+	Targets []string       `yaml:"targets"` // Targets are "<host> <port>" pairs, probed in addition to Host and Port
+	Options map[string]any `yaml:",inline"`
+}
+
+// defaultConfigPath returns the path of the configuration file
+// in $XDG_CONFIG_HOME/tcping, or in the user's configuration directory
+// on systems without it. It returns an empty string if there is none.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserConfigDir(); err != nil {
+			return ""
+		}
+	}
+
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, "tcping", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// loadConfig reads the configuration file at path, or the default one when path is empty.
+// It returns nil without an error when no path is given and there is no default file.
+func loadConfig(path string) (*configFile, error) {
+	if path == "" {
+		if path = defaultConfigPath(); path == "" {
+			return nil, nil
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf(msg("config.not-found"), path)
+		}
+		return nil, err
+	}
+
+	var cfg configFile
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// apply sets every flag of flagSet that was not given on the command line
+// from the top-level options and from the options of the profile, if any.
+//
+// It returns the targets of the profile, if any, followed by the targets
+// given on the command line, in the same shape as flag.Args().
+func (c *configFile) apply(flagSet *flag.FlagSet, profileName string) ([]string, error) {
+	options := map[string]any{}
+	var targets []string
+
+	if c != nil {
+		for name, value := range c.Options {
+			options[name] = value
+		}
+	}
+
+	if profileName != "" {
+		if c == nil {
+			return nil, fmt.Errorf(msg("config.no-file"), profileName)
+		}
+
+		profile, ok := c.Profiles[profileName]
+		if !ok {
+			return nil, fmt.Errorf(msg("config.no-profile"), profileName)
+		}
+
+		for name, value := range profile.Options {
+			options[name] = value
+		}
+
+		var err error
+		targets, err = profile.targets()
+		if err != nil {
+			return nil, fmt.Errorf(msg("config.profile"), profileName, err)
+		}
+	}
+
+	setOnCommandLine := map[string]bool{}
+	flagSet.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	for name, value := range options {
+		flagName := name
+		if alias, ok := configAliases[name]; ok {
+			flagName = alias
+		}
+
+		f := flagSet.Lookup(flagName)
+		if f == nil || slices.Contains(configIgnoredFlags, flagName) {
+			return nil, fmt.Errorf(msg("config.unknown-option"), name)
+		}
+		if setOnCommandLine[flagName] {
+			continue
+		}
+
+		if err := setConfigFlag(f, value); err != nil {
+			return nil, fmt.Errorf(msg("config.option"), name, err)
+		}
+	}
+
+	return append(targets, flagSet.Args()...), nil
+}
+
+// targets returns the targets of the profile as host and port pairs, and SRV names.
+func (p configProfile) targets() ([]string, error) {
+	var args []string
+
+	if p.Host != "" || p.Port != 0 {
+		if p.Host == "" || p.Port == 0 {
+			return nil, msgErr("config.host-port")
+		}
+		args = append(args, p.Host, strconv.Itoa(int(p.Port)))
+	}
+
+	for _, target := range p.Targets {
+		fields := strings.Fields(target)
+		if len(fields) != 2 && (len(fields) != 1 || !isSRVName(fields[0])) {
+			return nil, fmt.Errorf(msg("config.target"), target)
+		}
+		args = append(args, fields...)
+	}
+
+	return args, nil
+}
+
+// setConfigFlag sets f to a value of the configuration file.
+// Lists are only allowed for flags that can be given multiple times.
+func setConfigFlag(f *flag.Flag, value any) error {
+	values, isList := value.([]any)
+	if !isList {
+		values = []any{value}
+	} else if _, repeatable := f.Value.(*stringsFlag); !repeatable {
+		return msgErr("config.no-list")
+	}
+
+	for _, v := range values {
+		if v == nil {
+			return msgErr("config.missing-value")
+		}
+		if err := f.Value.Set(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+interval: 2
+timeout: 3
+json: true
+http-header:
+  - "X-A: 1"
+  - "X-B: 2"
+profiles:
+  prod-db:
+    host: db.example.com
+    port: 5432
+    targets:
+      - "10.0.0.1 5432"
+    timeout: 5
+    show-failures-only: true
+`
+
+// newTestFlagSet creates a flag set with a few of the flags of processUserInput.
+func newTestFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("tcping", flag.ContinueOnError)
+	fs.Float64("i", 1, "")
+	fs.Float64("t", 1, "")
+	fs.Bool("j", false, "")
+	fs.Bool("show-failures-only", false, "")
+	fs.String("p", "", "")
+	var headers stringsFlag
+	fs.Var(&headers, "http-header", "")
+	return fs
+}
+
+// writeTestConfig writes content to a config file in a temporary directory.
+func writeTestConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigApply(t *testing.T) {
+	cfg, err := loadConfig(writeTestConfig(t, testConfig))
+	require.NoError(t, err)
+
+	fs := newTestFlagSet()
+	require.NoError(t, fs.Parse([]string{"-i", "0.5"}))
+
+	targets, err := cfg.apply(fs, "prod-db")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"db.example.com", "5432", "10.0.0.1", "5432"}, targets)
+	// flags on the command line override the file
+	assert.Equal(t, "0.5", fs.Lookup("i").Value.String())
+	// profile options override the top-level ones
+	assert.Equal(t, "5", fs.Lookup("t").Value.String())
+	assert.Equal(t, "true", fs.Lookup("j").Value.String())
+	assert.Equal(t, "true", fs.Lookup("show-failures-only").Value.String())
+	assert.Equal(t, "X-A: 1, X-B: 2", fs.Lookup("http-header").Value.String())
+}
+
+func TestConfigApplyWithTargets(t *testing.T) {
+	cfg, err := loadConfig(writeTestConfig(t, testConfig))
+	require.NoError(t, err)
+
+	// the targets on the command line are probed along with those of the profile
+	fs := newTestFlagSet()
+	require.NoError(t, fs.Parse([]string{"-i", "0.5", "example.com", "80"}))
+
+	targets, err := cfg.apply(fs, "prod-db")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.example.com", "5432", "10.0.0.1", "5432", "example.com", "80"}, targets)
+	assert.Equal(t, "5", fs.Lookup("t").Value.String())
+
+	fs = newTestFlagSet()
+	require.NoError(t, fs.Parse([]string{"example.com", "80"}))
+
+	targets, err = cfg.apply(fs, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "80"}, targets)
+}
+
+func TestConfigApplyWithoutProfile(t *testing.T) {
+	cfg, err := loadConfig(writeTestConfig(t, testConfig))
+	require.NoError(t, err)
+
+	fs := newTestFlagSet()
+	targets, err := cfg.apply(fs, "")
+	require.NoError(t, err)
+
+	assert.Empty(t, targets)
+	assert.Equal(t, "3", fs.Lookup("t").Value.String())
+	assert.Equal(t, "false", fs.Lookup("show-failures-only").Value.String())
+}
+
+func TestConfigApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+	}{
+		{"unknown profile", testConfig, "staging"},
+		{"unknown option", "colour: true", ""},
+		{"ignored option", "p: prod-db", ""},
+		{"list for a single flag", "timeout: [1, 2]", ""},
+		{"invalid value", "json: maybe", ""},
+		{"host without port", "profiles: {db: {host: db.example.com}}", "db"},
+		{"invalid target", "profiles: {db: {targets: [db.example.com]}}", "db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(writeTestConfig(t, tt.content))
+			require.NoError(t, err)
+
+			_, err = cfg.apply(newTestFlagSet(), tt.profile)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConfigProfileWithoutFile(t *testing.T) {
+	var cfg *configFile
+	_, err := cfg.apply(newTestFlagSet(), "prod-db")
+	assert.Error(t, err)
+
+	targets, err := cfg.apply(newTestFlagSet(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, targets)
+}
+
+func TestLoadConfigDefaultPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := loadConfig("")
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tcping"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tcping", "config.yml"), []byte("interval: 2"), 0o600))
+
+	cfg, err = loadConfig("")
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, 2, cfg.Options["interval"])
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	_, err = loadConfig(writeTestConfig(t, "interval: [1"))
+	assert.Error(t, err)
+}
@@ -21,6 +21,8 @@ type csvPrinter struct {
 	statsHeaderDone   bool
 	showTimestamp     *bool
 	showSourceAddress *bool
+	showTLS           *bool
+	showHTTP          *bool
 	cleanup           func()
 }
 
@@ -32,6 +34,17 @@ const (
 	colPort          = "Port"
 	colTCPConn       = "TCP_Conn"
 	colLatency       = "Latency(ms)"
+	colErrorKind     = "Error Kind"
+	colDNS           = "DNS(ms)"
+	colConnect       = "Connect(ms)"
+	colTLSHandshake  = "TLS Handshake(ms)"
+	colTLSVersion    = "TLS Version"
+	colTLSCipher     = "TLS Cipher"
+	colCertSubject   = "Certificate Subject"
+	colCertDaysLeft  = "Certificate Days Left"
+	colHTTPStatus    = "HTTP Status"
+	colHTTPTTFB      = "TTFB(ms)"
+	colHTTPTotal     = "HTTP Total(ms)"
 	colSourceAddress = "Source Address"
 )
 
@@ -49,12 +62,12 @@ func addCSVExtension(filename string, withStats bool) string {
 	return filename + ".csv"
 }
 
-func newCSVPrinter(filename string, showTimestamp *bool, showSourceAddress *bool) (*csvPrinter, error) {
+func newCSVPrinter(filename string, showTimestamp *bool, showSourceAddress *bool, showTLS *bool, showHTTP *bool) (*csvPrinter, error) {
 	filename = addCSVExtension(filename, false)
 
 	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermission)
 	if err != nil {
-		return nil, fmt.Errorf("error creating data CSV file: %w", err)
+		return nil, fmt.Errorf(msg("csv.create-data"), err)
 	}
 
 	statsFilename := addCSVExtension(filename, true)
@@ -65,6 +78,8 @@ func newCSVPrinter(filename string, showTimestamp *bool, showSourceAddress *bool
 		statsFilename:     statsFilename,
 		showTimestamp:     showTimestamp,
 		showSourceAddress: showSourceAddress,
+		showTLS:           showTLS,
+		showHTTP:          showHTTP,
 	}
 
 	cp.cleanup = func() {
@@ -93,6 +108,17 @@ func (cp *csvPrinter) writeHeader() error {
 		colPort,
 		colTCPConn,
 		colLatency,
+		colErrorKind,
+		colDNS,
+		colConnect,
+	}
+
+	if *cp.showTLS {
+		headers = append(headers, colTLSHandshake, colTLSVersion, colTLSCipher, colCertSubject, colCertDaysLeft)
+	}
+
+	if *cp.showHTTP {
+		headers = append(headers, colHTTPStatus, colHTTPTTFB, colHTTPTotal)
 	}
 
 	if *cp.showSourceAddress {
@@ -104,7 +130,7 @@ func (cp *csvPrinter) writeHeader() error {
 	}
 
 	if err := cp.probeWriter.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
+		return fmt.Errorf(msg("csv.write-headers"), err)
 	}
 
 	cp.probeWriter.Flush()
@@ -116,7 +142,7 @@ func (cp *csvPrinter) writeRecord(record []string) error {
 	if _, err := os.Stat(cp.probeFilename); os.IsNotExist(err) {
 		file, err := os.OpenFile(cp.probeFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermission)
 		if err != nil {
-			return fmt.Errorf("failed to recreate data CSV file: %w", err)
+			return fmt.Errorf(msg("csv.recreate-data"), err)
 		}
 		cp.probeFile = file
 		cp.probeWriter = csv.NewWriter(file)
@@ -135,7 +161,7 @@ func (cp *csvPrinter) writeRecord(record []string) error {
 	}
 
 	if err := cp.probeWriter.Write(record); err != nil {
-		return fmt.Errorf("failed to write record: %w", err)
+		return fmt.Errorf(msg("csv.write-record"), err)
 	}
 
 	cp.probeWriter.Flush()
@@ -144,10 +170,10 @@ func (cp *csvPrinter) writeRecord(record []string) error {
 }
 
 func (cp *csvPrinter) printStart(hostname string, port uint16) {
-	fmt.Printf("TCPing results for %s on port %d being written to: %s\n", hostname, port, cp.probeFilename)
+	fmt.Printf(msg("csv.start")+"\n", hostname, port, cp.probeFilename)
 }
 
-func (cp *csvPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32) {
+func (cp *csvPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
 	record := []string{
 		"Reply",
 		userInput.hostname,
@@ -155,6 +181,27 @@ func (cp *csvPrinter) printProbeSuccess(sourceAddr string, userInput userInput,
 		fmt.Sprint(userInput.port),
 		fmt.Sprint(streak),
 		fmt.Sprintf("%.3f", rtt),
+		"",
+		formatPhase(info.timings[phaseDNS]),
+		formatPhase(info.timings[phaseConnect]),
+	}
+
+	if *cp.showTLS {
+		if info.tls != nil {
+			record = append(record,
+				fmt.Sprintf("%.3f", info.tls.handshake),
+				info.tls.version,
+				info.tls.cipher,
+				info.tls.subject,
+				fmt.Sprint(info.tls.daysLeft()),
+			)
+		} else {
+			record = append(record, "", "", "", "", "")
+		}
+	}
+
+	if *cp.showHTTP {
+		record = append(record, httpRecord(info)...)
 	}
 
 	if *cp.showSourceAddress {
@@ -162,11 +209,11 @@ func (cp *csvPrinter) printProbeSuccess(sourceAddr string, userInput userInput,
 	}
 
 	if err := cp.writeRecord(record); err != nil {
-		cp.printError("failed to write success record: %v", err)
+		cp.printError(msg("csv.write-success"), err)
 	}
 }
 
-func (cp *csvPrinter) printProbeFail(userInput userInput, streak uint) {
+func (cp *csvPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
 	record := []string{
 		"No reply",
 		userInput.hostname,
@@ -174,6 +221,17 @@ func (cp *csvPrinter) printProbeFail(userInput userInput, streak uint) {
 		fmt.Sprint(userInput.port),
 		fmt.Sprint(streak),
 		"",
+		string(kind),
+		formatPhase(info.timings[phaseDNS]),
+		formatPhase(info.timings[phaseConnect]),
+	}
+
+	if *cp.showTLS {
+		record = append(record, "", "", "", "", "")
+	}
+
+	if *cp.showHTTP {
+		record = append(record, httpRecord(info)...)
 	}
 
 	if *cp.showSourceAddress {
@@ -181,7 +239,30 @@ func (cp *csvPrinter) printProbeFail(userInput userInput, streak uint) {
 	}
 
 	if err := cp.writeRecord(record); err != nil {
-		cp.printError("failed to write failure record: %v", err)
+		cp.printError(msg("csv.write-failure"), err)
+	}
+}
+
+// formatPhase formats the duration of a probe phase,
+// leaving it empty if the phase did not happen.
+func formatPhase(ms float32) string {
+	if ms == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", ms)
+}
+
+// httpRecord returns the HTTP columns of a probe record.
+// They are empty when no response was received.
+func httpRecord(info probeInfo) []string {
+	if info.http == nil {
+		return []string{"", "", ""}
+	}
+
+	return []string{
+		fmt.Sprint(info.http.status),
+		fmt.Sprintf("%.3f", info.http.ttfb),
+		fmt.Sprintf("%.3f", info.http.total),
 	}
 }
 
@@ -193,15 +274,40 @@ func (cp *csvPrinter) printRetryingToResolve(hostname string) {
 		"",
 		"",
 		"",
+		"",
+		"",
+		"",
 	}
 
 	if err := cp.writeRecord(record); err != nil {
-		cp.printError("failed to write resolve record: %v", err)
+		cp.printError(msg("csv.write-resolve"), err)
+	}
+}
+
+func (cp *csvPrinter) printStateChange(userInput userInput, state targetState) {
+	record := []string{
+		"State " + string(state),
+		userInput.hostname,
+		userInput.ip.String(),
+		fmt.Sprint(userInput.port),
+		"",
+		"",
+		"",
+		"",
+		"",
+	}
+
+	if err := cp.writeRecord(record); err != nil {
+		cp.printError(msg("csv.write-state-change"), err)
 	}
 }
 
 func (cp *csvPrinter) printError(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "CSV Error: "+format+"\n", args...)
+	fmt.Fprintf(os.Stderr, msg("csv.error")+format+"\n", args...)
+}
+
+func (cp *csvPrinter) printWarning(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
 }
 
 func (cp *csvPrinter) writeStatsHeader() error {
@@ -211,7 +317,7 @@ func (cp *csvPrinter) writeStatsHeader() error {
 	}
 
 	if err := cp.statsWriter.Write(headers); err != nil {
-		return fmt.Errorf("failed to write statistics headers: %w", err)
+		return fmt.Errorf(msg("csv.write-stats-headers"), err)
 	}
 
 	cp.statsWriter.Flush()
@@ -223,7 +329,7 @@ func (cp *csvPrinter) writeStatsRecord(record []string) error {
 	if _, err := os.Stat(cp.statsFilename); os.IsNotExist(err) {
 		statsFile, err := os.OpenFile(cp.statsFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermission)
 		if err != nil {
-			return fmt.Errorf("failed to recreate statistics CSV file: %w", err)
+			return fmt.Errorf(msg("csv.recreate-stats"), err)
 		}
 		cp.statsFile = statsFile
 		cp.statsWriter = csv.NewWriter(statsFile)
@@ -238,7 +344,7 @@ func (cp *csvPrinter) writeStatsRecord(record []string) error {
 	}
 
 	if err := cp.statsWriter.Write(record); err != nil {
-		return fmt.Errorf("failed to write statistics record: %w", err)
+		return fmt.Errorf(msg("csv.write-stats-record"), err)
 	}
 
 	cp.statsWriter.Flush()
@@ -250,7 +356,7 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 	if cp.statsFile == nil {
 		statsFile, err := os.OpenFile(cp.statsFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, filePermission)
 		if err != nil {
-			cp.printError("failed to create statistics CSV file: %v", err)
+			cp.printError(msg("csv.create-stats"), err)
 			return
 		}
 		cp.statsFile = statsFile
@@ -267,6 +373,7 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 	// Collect statistics data
 	timestamp := time.Now().Format(timeFormat)
 	statistics := [][]string{
+		{"Target", t.userInput.target()},
 		{"Timestamp", timestamp},
 		{"Total Packets", fmt.Sprint(totalPackets)},
 		{"Successful Probes", fmt.Sprint(t.totalSuccessfulProbes)},
@@ -274,6 +381,12 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 		{"Packet Loss", fmt.Sprintf("%.2f%%", packetLoss)},
 	}
 
+	for _, kind := range errorKinds {
+		if count := t.failuresByKind[kind]; count > 0 {
+			statistics = append(statistics, []string{fmt.Sprintf("Failures (%s)", kind), fmt.Sprint(count)})
+		}
+	}
+
 	if t.lastSuccessfulProbe.IsZero() {
 		statistics = append(statistics, []string{"Last Successful Probe", "Never succeeded"})
 	} else {
@@ -286,12 +399,17 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 		statistics = append(statistics, []string{"Last Unsuccessful Probe", t.lastUnsuccessfulProbe.Format(timeFormat)})
 	}
 
-	statistics = append(statistics, []string{"Total Uptime", durationToString(t.totalUptime)})
-	statistics = append(statistics, []string{"Total Downtime", durationToString(t.totalDowntime)})
+	statistics = append(statistics, []string{"Total Uptime (s)", durationToSeconds(t.totalUptime)})
+	statistics = append(statistics, []string{"Total Downtime (s)", durationToSeconds(t.totalDowntime)})
+	statistics = append(statistics,
+		[]string{"State", string(t.state())},
+		[]string{"State Changes", fmt.Sprint(t.stateChanges)},
+		[]string{"Flaps", fmt.Sprint(t.flaps)},
+	)
 
 	if t.longestUptime.duration != 0 {
 		statistics = append(statistics,
-			[]string{"Longest Uptime Duration", durationToString(t.longestUptime.duration)},
+			[]string{"Longest Uptime Duration (s)", durationToSeconds(t.longestUptime.duration)},
 			[]string{"Longest Uptime From", t.longestUptime.start.Format(timeFormat)},
 			[]string{"Longest Uptime To", t.longestUptime.end.Format(timeFormat)},
 		)
@@ -299,14 +417,28 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 
 	if t.longestDowntime.duration != 0 {
 		statistics = append(statistics,
-			[]string{"Longest Downtime Duration", durationToString(t.longestDowntime.duration)},
+			[]string{"Longest Downtime Duration (s)", durationToSeconds(t.longestDowntime.duration)},
 			[]string{"Longest Downtime From", t.longestDowntime.start.Format(timeFormat)},
 			[]string{"Longest Downtime To", t.longestDowntime.end.Format(timeFormat)},
 		)
 	}
 
 	if !t.destIsIP {
-		statistics = append(statistics, []string{"Retried Hostname Lookups", fmt.Sprint(t.retriedHostnameLookups)})
+		statistics = append(statistics,
+			[]string{"Retried Hostname Lookups", fmt.Sprint(t.retriedHostnameLookups)},
+			[]string{"DNS Lookups", fmt.Sprint(t.dns.lookups)},
+			[]string{"DNS Lookup Failures", fmt.Sprint(t.dns.failures)},
+		)
+		if t.userInput.resolver != nil {
+			statistics = append(statistics, []string{"Resolver", t.userInput.resolver.String()})
+		}
+		if result := t.dns.latency.result(); result.hasResults {
+			statistics = append(statistics,
+				[]string{"DNS Lookup Min (ms)", fmt.Sprintf("%.3f", result.min)},
+				[]string{"DNS Lookup Avg (ms)", fmt.Sprintf("%.3f", result.average)},
+				[]string{"DNS Lookup Max (ms)", fmt.Sprintf("%.3f", result.max)},
+			)
+		}
 
 		if len(t.hostnameChanges) >= 2 {
 			for i := 0; i < len(t.hostnameChanges)-1; i++ {
@@ -319,11 +451,70 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 		}
 	}
 
+	if t.userInput.srvName != "" {
+		statistics = append(statistics,
+			[]string{"SRV Name", t.userInput.srvName},
+			[]string{"SRV Priority", fmt.Sprint(t.srvRecord.Priority)},
+			[]string{"SRV Weight", fmt.Sprint(t.srvRecord.Weight)},
+		)
+
+		for _, change := range t.srvChanges {
+			kind := "Removed"
+			if change.Added {
+				kind = "Added"
+			}
+			statistics = append(statistics,
+				[]string{"SRV Change", kind},
+				[]string{"At", change.When.Format(timeFormat)},
+			)
+		}
+	}
+
 	if t.rttResults.hasResults {
 		statistics = append(statistics,
-			[]string{"RTT Min", fmt.Sprintf("%.3f ms", t.rttResults.min)},
-			[]string{"RTT Avg", fmt.Sprintf("%.3f ms", t.rttResults.average)},
-			[]string{"RTT Max", fmt.Sprintf("%.3f ms", t.rttResults.max)},
+			[]string{"RTT Min (ms)", fmt.Sprintf("%.3f", t.rttResults.min)},
+			[]string{"RTT Avg (ms)", fmt.Sprintf("%.3f", t.rttResults.average)},
+			[]string{"RTT Max (ms)", fmt.Sprintf("%.3f", t.rttResults.max)},
+			[]string{"RTT Median (ms)", fmt.Sprintf("%.3f", t.rttResults.median)},
+			[]string{"RTT P90 (ms)", fmt.Sprintf("%.3f", t.rttResults.p90)},
+			[]string{"RTT P95 (ms)", fmt.Sprintf("%.3f", t.rttResults.p95)},
+			[]string{"RTT P99 (ms)", fmt.Sprintf("%.3f", t.rttResults.p99)},
+			[]string{"RTT Standard Deviation (ms)", fmt.Sprintf("%.3f", t.rttResults.stdDev)},
+			[]string{"RTT Jitter (ms)", fmt.Sprintf("%.3f", t.rttResults.jitter)},
+		)
+	}
+
+	for p := range phaseCount {
+		if result := t.phases[p].result(); result.hasResults {
+			statistics = append(statistics,
+				[]string{p.label() + " Min (ms)", fmt.Sprintf("%.3f", result.min)},
+				[]string{p.label() + " Avg (ms)", fmt.Sprintf("%.3f", result.average)},
+				[]string{p.label() + " Max (ms)", fmt.Sprintf("%.3f", result.max)},
+			)
+		}
+	}
+
+	if t.lastTLS != nil {
+		statistics = append(statistics,
+			[]string{"TLS Version", t.lastTLS.version},
+			[]string{"TLS Cipher", t.lastTLS.cipher},
+			[]string{"Certificate Subject", t.lastTLS.subject},
+			[]string{"Certificate Expires At", t.lastTLS.notAfter.Format(timeFormat)},
+			[]string{"Certificate Days Left", fmt.Sprint(t.lastTLS.daysLeft())},
+		)
+	}
+
+	if t.userInput.happyEyeballs != nil {
+		race := t.race.summary()
+		statistics = append(statistics,
+			[]string{"Races", fmt.Sprint(race.Races)},
+			[]string{"IPv4 Race Wins", fmt.Sprint(race.IPv4Wins)},
+			[]string{"IPv6 Race Wins", fmt.Sprint(race.IPv6Wins)},
+			[]string{"IPv6 Race Losses", fmt.Sprint(race.IPv6Losses)},
+			[]string{"Race Fallbacks", fmt.Sprint(race.Fallbacks)},
+			[]string{"Race Fallback Avg (ms)", fmt.Sprintf("%.3f", race.FallbackAvg)},
+			[]string{"Race Fallback Max (ms)", fmt.Sprintf("%.3f", race.FallbackMax)},
+			[]string{"IPv6 Losing", fmt.Sprint(race.IPv6Losing)},
 		)
 	}
 
@@ -333,20 +524,122 @@ func (cp *csvPrinter) printStatistics(t tcping) {
 		statistics = append(statistics, []string{"TCPing Ended At", t.endTime.Format(timeFormat)})
 	}
 
-	durationTime := time.Time{}.Add(t.totalDowntime + t.totalUptime)
-	statistics = append(statistics, []string{"Duration (HH:MM:SS)", durationTime.Format(hourFormat)})
+	statistics = append(statistics, []string{"Total Duration (s)", durationToSeconds(t.totalDowntime + t.totalUptime)})
+
+	for _, record := range statistics {
+		if err := cp.writeStatsRecord(record); err != nil {
+			cp.printError(msg("csv.write-stats"), err)
+			return
+		}
+	}
+
+	fmt.Printf(msg("csv.stats-written")+"\n", cp.statsFilename)
+}
+
+// printSessions is not used by the report subcommand, which doesn't write CSV files.
+func (cp *csvPrinter) printSessions(_ string, _ []reportSession) {}
+
+// printReport is not used by the report subcommand, which doesn't write CSV files.
+func (cp *csvPrinter) printReport(_ sessionReport) {}
+
+// printCheckResult appends the health check of --check to the statistics file.
+func (cp *csvPrinter) printCheckResult(userInput userInput, result checkResult) {
+	statistics := [][]string{
+		{"Check Target", userInput.target()},
+		{"Check Status", result.status.String()},
+		{"Check Exit Code", fmt.Sprint(int(result.status))},
+	}
+
+	if result.status != checkHealthy && result.status != checkNoSuccess {
+		statistics = append(statistics,
+			[]string{"Check Value", fmt.Sprintf("%.3f", result.value)},
+			[]string{"Check Threshold", fmt.Sprintf("%.3f", result.threshold)},
+		)
+	}
+
+	for _, record := range statistics {
+		if err := cp.writeStatsRecord(record); err != nil {
+			cp.printError(msg("csv.write-check"), err)
+			return
+		}
+	}
+}
+
+// printAddressSummary appends the addresses of a hostname in --all-addresses mode to the statistics file.
+func (cp *csvPrinter) printAddressSummary(summary addressSummary) {
+	statistics := [][]string{
+		{"Addresses Target", summary.target()},
+	}
+
+	for _, a := range summary.Addresses {
+		statistics = append(statistics,
+			[]string{"Address", a.Addr.String()},
+			[]string{"Address State", string(a.State)},
+			[]string{"Address Packet Loss", fmt.Sprintf("%.2f%%", a.PacketLoss)},
+		)
+		if a.TotalSuccessfulProbes > 0 {
+			statistics = append(statistics, []string{"Address RTT Avg (ms)", fmt.Sprintf("%.3f", a.LatencyAvg)})
+		}
+	}
+
+	failing := make([]string, 0, len(summary.Addresses))
+	for _, addr := range summary.failing() {
+		failing = append(failing, addr.String())
+	}
+	statistics = append(statistics, []string{"Failing Addresses", strings.Join(failing, " ")})
 
 	for _, record := range statistics {
 		if err := cp.writeStatsRecord(record); err != nil {
-			cp.printError("failed to write statistics record: %v", err)
+			cp.printError(msg("csv.write-addresses"), err)
 			return
 		}
 	}
+}
+
+// printDualStackSummary appends the comparison of IPv4 and IPv6 of a hostname in --dual-stack mode to the statistics file.
+func (cp *csvPrinter) printDualStackSummary(summary dualStackSummary) {
+	statistics := [][]string{
+		{"Dual Stack Target", summary.target()},
+	}
 
-	fmt.Printf("TCPing statistics written to: %s\n", cp.statsFilename)
+	for _, f := range summary.Families {
+		label := f.Family.label()
+		statistics = append(statistics,
+			[]string{label + " Address", f.Addr.String()},
+			[]string{label + " State", string(f.State)},
+			[]string{label + " Packet Loss", fmt.Sprintf("%.2f%%", f.PacketLoss)},
+		)
+		if f.TotalSuccessfulProbes > 0 {
+			statistics = append(statistics, []string{label + " RTT Avg (ms)", fmt.Sprintf("%.3f", f.LatencyAvg)})
+		}
+		statistics = append(statistics, []string{label + " Outages", fmt.Sprint(len(f.Outages))})
+	}
+
+	statistics = append(statistics,
+		[]string{"Cycles", fmt.Sprint(summary.Cycles)},
+		[]string{"Cycles Both Up", fmt.Sprint(summary.BothUp)},
+		[]string{"Cycles Only IPv4 Down", fmt.Sprint(summary.OnlyIPv4Down)},
+		[]string{"Cycles Only IPv6 Down", fmt.Sprint(summary.OnlyIPv6Down)},
+		[]string{"Cycles Both Down", fmt.Sprint(summary.BothDown)},
+	)
+	if summary.RTTDelta != nil {
+		statistics = append(statistics, []string{"RTT Delta IPv6 - IPv4 (ms)", fmt.Sprintf("%.3f", *summary.RTTDelta)})
+	}
+
+	for _, o := range summary.SingleFamilyOutages {
+		statistics = append(statistics, []string{"Only " + o.Family.label() + " Down",
+			fmt.Sprintf("%s - %s", o.Start.Format(timeFormat), o.End.Format(timeFormat))})
+	}
+
+	for _, record := range statistics {
+		if err := cp.writeStatsRecord(record); err != nil {
+			cp.printError(msg("csv.write-dual-stack"), err)
+			return
+		}
+	}
 }
 
 // Satisfying remaining printer interface methods
-func (cp *csvPrinter) printTotalDownTime(_ time.Duration) {}
-func (cp *csvPrinter) printVersion()                      {}
-func (cp *csvPrinter) printInfo(_ string, _ ...any)       {}
+func (cp *csvPrinter) printTotalDownTime(_ userInput, _ time.Duration) {}
+func (cp *csvPrinter) printVersion()                                   {}
+func (cp *csvPrinter) printInfo(_ string, _ ...any)                    {}
@@ -13,8 +13,10 @@ func TestNewCSVPrinter(t *testing.T) {
 	dataFilename := "test_data.csv"
 	showTimestamp := true
 	showSourceAddress := true
+	showTLS := false
+	showHTTP := false
 
-	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress)
+	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress, &showTLS, &showHTTP)
 	assert.NoError(t, err)
 	assert.NotNil(t, cp)
 	assert.Equal(t, dataFilename, cp.probeFilename)
@@ -29,12 +31,14 @@ func TestWriteRecord(t *testing.T) {
 	dataFilename := "test_data.csv"
 	showTimestamp := false
 	showSourceAddress := true
+	showTLS := false
+	showHTTP := false
 
-	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress)
+	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress, &showTLS, &showHTTP)
 	assert.NoError(t, err)
 	assert.NotNil(t, cp)
 
-	record := []string{"Success", "hostname", "127.0.0.1", "80", "1", "10.123", "sourceAddr"}
+	record := []string{"Success", "hostname", "127.0.0.1", "80", "1", "10.123", "", "", "10.123", "sourceAddr"}
 	err = cp.writeRecord(record)
 	assert.NoError(t, err)
 
@@ -46,7 +50,7 @@ func TestWriteRecord(t *testing.T) {
 	reader := csv.NewReader(file)
 	headers, err := reader.Read()
 	assert.NoError(t, err)
-	assert.Equal(t, []string{"Status", "Hostname", "IP", "Port", "TCP_Conn", "Latency(ms)", "Source Address"}, headers)
+	assert.Equal(t, []string{"Status", "Hostname", "IP", "Port", "TCP_Conn", "Latency(ms)", "Error Kind", "DNS(ms)", "Connect(ms)", "Source Address"}, headers)
 
 	readRecord, err := reader.Read()
 	assert.NoError(t, err)
@@ -62,8 +66,10 @@ func TestWriteStatistics(t *testing.T) {
 	dataFilename := "test_data.csv"
 	showTimestamp := true
 	showSourceAddress := false
+	showTLS := false
+	showHTTP := false
 
-	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress)
+	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress, &showTLS, &showHTTP)
 	assert.NoError(t, err)
 	assert.NotNil(t, cp)
 
@@ -72,6 +78,7 @@ func TestWriteStatistics(t *testing.T) {
 		totalUnsuccessfulProbes: 0,
 		lastSuccessfulProbe:     time.Now(),
 		startTime:               time.Now(),
+		totalUptime:             90500 * time.Millisecond,
 	}
 
 	cp.printStatistics(tcping)
@@ -85,14 +92,21 @@ func TestWriteStatistics(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"Metric", "Value"}, headers)
 
+	values := map[string]string{}
 	for {
 		record, err := reader.Read()
 		if err != nil {
 			break
 		}
 		assert.NotEmpty(t, record)
+		values[record[0]] = record[1]
 	}
 
+	// durations are written in seconds, whatever the language of the messages
+	assert.Equal(t, "90.500", values["Total Uptime (s)"])
+	assert.Equal(t, "0.000", values["Total Downtime (s)"])
+	assert.Equal(t, "90.500", values["Total Duration (s)"])
+
 	cp.cleanup()
 	os.Remove(dataFilename)
 	os.Remove(cp.statsFilename)
@@ -102,8 +116,10 @@ func TestCleanup(t *testing.T) {
 	dataFilename := "test_data.csv"
 	showTimestamp := true
 	showSourceAddress := false
+	showTLS := false
+	showHTTP := false
 
-	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress)
+	cp, err := newCSVPrinter(dataFilename, &showTimestamp, &showSourceAddress, &showTLS, &showHTTP)
 	assert.NoError(t, err)
 	assert.NotNil(t, cp)
 
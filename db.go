@@ -2,26 +2,77 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"net"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
-	"unicode"
 
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
 )
 
 type database struct {
-	conn      *sqlite.Conn
-	dbPath    string
+	mu         sync.Mutex // mu guards conn and pending, as the statistics may be saved while probing
+	conn       *sqlite.Conn
+	dbPath     string
+	tableName  string                // tableName is the table of the first target
+	tables     map[string]string     // tables maps userInput.hostPort() to its table name
+	resets     map[probeFilter]int64 // resets maps a target to the id of the last probe row before its statistics were reset
+	pending    []probeRow            // pending are the probes not written yet
+	batchStart time.Time             // batchStart is the time of the first pending probe
+}
+
+// probeRow is a probe waiting to be written to the database.
+type probeRow struct {
+	tableName  string
+	timestamp  string
+	addr       string
+	sourceAddr string
+	hostname   string
+	port       uint16
+	success    bool
+	latency    any // latency is nil for failed probes
+	errorKind  errorKind
+}
+
+// probeFilter selects the probe rows of a target.
+// The rows are only filtered by addr, or by hostname and port, when they aren't empty.
+type probeFilter struct {
 	tableName string
+	addr      string
+	hostname  string
+	port      uint16
+}
+
+// probeSummary is the part of the statistics computed from the probe rows of a target.
+type probeSummary struct {
+	successfulProbes      uint
+	unsuccessfulProbes    uint
+	rtt                   rttResult // rtt is computed from the latencies of the successful probes, like the printed statistics
+	lastSuccessfulProbe   string
+	lastUnsuccessfulProbe string
+	sourceAddr            string // sourceAddr is the source address of the last successful probe
 }
 
 const (
 	eventTypeStatistics     = "statistics"
 	eventTypeHostnameChange = "hostname change"
+	eventTypeStateChange    = "state change"
+	eventTypeCheck          = "check"
+	eventTypeAddress        = "address"
+	eventTypeDualStack      = "dual stack"
+	eventTypeSRVChange      = "srv change"
+	eventTypeProbe          = "probe"
+
+	// dbBatchSize is the number of probes written in a single transaction.
+	dbBatchSize = 100
+	// dbFlushInterval is the longest time a probe waits before being written.
+	dbFlushInterval = time.Second
 
 	tableSchema = `
 CREATE TABLE %s (
@@ -34,14 +85,40 @@ CREATE TABLE %s (
     port INTEGER,
     hostname_resolve_retries INTEGER,
 
+    -- result of a single probe, only set in rows with event_type = probe
+    success INTEGER,
+    latency REAL, -- empty for failed probes
+    error_kind TEXT, -- empty for successful probes
+
     hostname_changed_to TEXT,
     hostname_change_time DATETIME,
 
     latency_min REAL,
     latency_avg REAL,
     latency_max REAL,
-
-	total_duration TEXT,
+    latency_median REAL,
+    latency_p90 REAL,
+    latency_p95 REAL,
+    latency_p99 REAL,
+    latency_stddev REAL,
+    latency_jitter REAL,
+
+    -- min/avg/max duration of every probe phase, empty if the phase never happened
+    dns_min REAL,
+    dns_avg REAL,
+    dns_max REAL,
+    connect_min REAL,
+    connect_avg REAL,
+    connect_max REAL,
+    tls_min REAL,
+    tls_avg REAL,
+    tls_max REAL,
+    first_byte_min REAL,
+    first_byte_avg REAL,
+    first_byte_max REAL,
+
+    -- durations are in seconds
+    total_duration REAL,
     start_time DATETIME,
     end_time DATETIME,
 
@@ -50,11 +127,11 @@ CREATE TABLE %s (
     last_successful_probe DATETIME,
     last_unsuccessful_probe DATETIME,
 
-    longest_uptime TEXT,
+    longest_uptime REAL,
     longest_uptime_start DATETIME,
     longest_uptime_end DATETIME,
 
-    longest_downtime TEXT,
+    longest_downtime REAL,
     longest_downtime_start DATETIME,
     longest_downtime_end DATETIME,
 
@@ -63,8 +140,52 @@ CREATE TABLE %s (
     total_successful_probes INTEGER,
     total_unsuccessful_probes INTEGER,
 
-    total_uptime TEXT,
-    total_downtime TEXT
+    total_uptime REAL,
+    total_downtime REAL,
+
+    failures_by_kind TEXT, -- JSON object counting failed probes per error kind, e.g. {"timeout":3}
+
+    state TEXT, -- up, down or flapping
+    state_changes INTEGER,
+    flaps INTEGER,
+
+    -- result of --check, only set in rows with event_type = check
+    check_status TEXT, -- healthy, or the failed check, e.g. loss or p95_rtt
+    check_value REAL,
+    check_threshold REAL,
+    exit_code INTEGER,
+
+    -- TLS connection of the last successful probe, only set in --tls mode
+    tls_version TEXT,
+    tls_cipher TEXT,
+    cert_subject TEXT,
+    cert_not_after DATETIME,
+    cert_days_left INTEGER,
+
+    -- connection races, only set in --happy-eyeballs mode
+    races INTEGER,
+    ipv4_race_wins INTEGER,
+    ipv6_race_wins INTEGER,
+    ipv6_race_losses INTEGER, -- races won by IPv4 although an IPv6 address was raced
+    race_fallbacks INTEGER, -- races not won by the first address
+    race_fallback_avg REAL, -- ms until the winning attempt was started
+    race_fallback_max REAL,
+
+    -- lookups of the hostname, empty if the destination is an IP address
+    dns_lookups INTEGER,
+    dns_lookup_failures INTEGER,
+    dns_lookup_min REAL, -- ms, of the successful lookups
+    dns_lookup_avg REAL,
+    dns_lookup_max REAL,
+
+    -- SRV record of the target, only set when an SRV name was given
+    srv_name TEXT,
+    srv_priority INTEGER,
+    srv_weight INTEGER,
+    srv_change TEXT, -- added or removed, only set in rows with event_type = srv change
+
+    -- JSON comparison of IPv4 and IPv6, only set in rows with event_type = dual stack
+    dual_stack TEXT
 );`
 
 	// %s will be replaced by the table name
@@ -95,138 +216,454 @@ CREATE TABLE %s (
 	latency_min,
 	latency_avg,
 	latency_max,
+	latency_median,
+	latency_p90,
+	latency_p95,
+	latency_p99,
+	latency_stddev,
+	latency_jitter,
+	dns_min,
+	dns_avg,
+	dns_max,
+	connect_min,
+	connect_avg,
+	connect_max,
+	tls_min,
+	tls_avg,
+	tls_max,
+	first_byte_min,
+	first_byte_avg,
+	first_byte_max,
 	start_time,
 	end_time,
-	total_duration) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	total_duration,
+	failures_by_kind,
+	state,
+	state_changes,
+	flaps,
+	tls_version,
+	tls_cipher,
+	cert_subject,
+	cert_not_after,
+	cert_days_left,
+	races,
+	ipv4_race_wins,
+	ipv6_race_wins,
+	ipv6_race_losses,
+	race_fallbacks,
+	race_fallback_avg,
+	race_fallback_max,
+	dns_lookups,
+	dns_lookup_failures,
+	dns_lookup_min,
+	dns_lookup_avg,
+	dns_lookup_max,
+	srv_name,
+	srv_priority,
+	srv_weight) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 )
 
-// newDB creates a newDB with the given path and returns a pointer to the `database` struct
+// newDB creates a newDB with the given path and returns a pointer to the `database` struct.
+//
+// args holds host and port pairs and SRV names, and a separate table is created for each of them.
+// The targets of an SRV name share its table.
 func newDB(dbPath string, args []string) *database {
-	tableName := newTableName(args)
-	tableSchema := fmt.Sprintf(tableSchema, tableName)
-
 	conn, err := sqlite.OpenConn(dbPath, sqlite.OpenCreate, sqlite.OpenReadWrite)
 	if err != nil {
-		colorRed("\nError while creating the database %q: %s\n", dbPath, err)
+		colorRed("\n"+msg("db.create-failed")+"\n", dbPath, err)
 		os.Exit(1)
 	}
 
-	err = sqlitex.Execute(conn, tableSchema, &sqlitex.ExecOptions{})
-	if err != nil {
-		colorRed("\nError writing to the database %q \nerr: %s\n", dbPath, err)
-		os.Exit(1)
+	db := &database{
+		conn:   conn,
+		dbPath: dbPath,
+		tables: map[string]string{},
+		resets: map[probeFilter]int64{},
 	}
-	return &database{conn, dbPath, tableName}
+
+	tableNames := map[string]bool{}
+	targets, _ := splitTargets(args)
+	for _, target := range targets {
+		key := target[0]
+		if len(target) == 1 {
+			target = []string{target[0], "srv"}
+		} else {
+			// the key must match userInput.hostPort(), in which a port like 0443 is 443
+			if port, err := strconv.ParseUint(target[1], 10, 16); err == nil {
+				target = []string{target[0], strconv.FormatUint(port, 10)}
+			}
+			key = net.JoinHostPort(target[0], target[1])
+		}
+
+		// a target given twice is probed twice, but only gets one table
+		if _, ok := db.tables[key]; ok {
+			continue
+		}
+
+		// different targets like a-b.com and a.b.com can have the same table name
+		name := newTableName(target)
+		tableName := name
+		for i := 2; tableNames[tableName]; i++ {
+			tableName = fmt.Sprintf("%s_%d", name, i)
+		}
+		tableNames[tableName] = true
+
+		err = sqlitex.Execute(conn, fmt.Sprintf(tableSchema, tableName), &sqlitex.ExecOptions{})
+		if err != nil {
+			colorRed("\n"+msg("db.write-failed")+"\n", dbPath, err)
+			os.Exit(1)
+		}
+
+		if db.tableName == "" {
+			db.tableName = tableName
+		}
+		db.tables[key] = tableName
+	}
+
+	return db
+}
+
+// table returns the name of the table that belongs to the given target.
+// It falls back to the table of the first target.
+func (db *database) table(userInput userInput) string {
+	key := userInput.hostPort()
+	if userInput.srvName != "" {
+		key = userInput.srvName
+	}
+	if tableName, ok := db.tables[key]; ok {
+		return tableName
+	}
+	return db.tableName
 }
 
 // newTableName will return correctly formatted table name
 // formatting the table name as "example_com_port_hour_minute_sec_day_month_year"
-// table name can't have '.','-' and can't start with numbers
+// table name can only have letters, digits and '_' and can't start with numbers,
+// so every other character, like the '.' of a hostname or the ':' of an IPv6 address, is replaced by '_'
 func newTableName(args []string) string {
-	sanitizedHost := strings.ReplaceAll(args[0], ".", "_")
-	sanitizedHost = strings.ReplaceAll(sanitizedHost, "-", "_")
-	tableName := fmt.Sprintf("%s_%s_%s", sanitizedHost, args[1], time.Now().Format("15_04_05_01_02_2006"))
+	tableName := fmt.Sprintf("%s_%s_%s", args[0], args[1], time.Now().Format("15_04_05_01_02_2006"))
+	tableName = strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, tableName)
 
-	if unicode.IsNumber(rune(tableName[0])) {
+	if '0' <= tableName[0] && tableName[0] <= '9' {
 		tableName = "_" + tableName
 	}
 
 	return tableName
 }
 
-// saveStats saves stats to the database with proper formatting
+// addProbe queues a probe and writes the queue once it's full,
+// or when the oldest probe has waited long enough.
+func (db *database) addProbe(row probeRow) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(db.pending) == 0 {
+		db.batchStart = time.Now()
+	}
+	db.pending = append(db.pending, row)
+
+	if len(db.pending) < dbBatchSize && time.Since(db.batchStart) < dbFlushInterval {
+		return
+	}
+
+	if err := db.flush(); err != nil {
+		db.printError("\n"+msg("db.write-probes-failed"), db.dbPath, err)
+	}
+}
+
+// flush writes the pending probes in a single transaction.
+// db.mu should be held by the caller.
+func (db *database) flush() (err error) {
+	if len(db.pending) == 0 {
+		return nil
+	}
+
+	defer sqlitex.Transaction(db.conn)(&err)
+
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, addr, sourceAddr, hostname, port, success, latency, error_kind)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for _, row := range db.pending {
+		err = sqlitex.Execute(db.conn, fmt.Sprintf(schema, row.tableName), &sqlitex.ExecOptions{
+			Args: []interface{}{eventTypeProbe, row.timestamp, row.addr, row.sourceAddr, row.hostname,
+				row.port, row.success, row.latency, string(row.errorKind)}})
+		if err != nil {
+			return err
+		}
+	}
+
+	db.pending = db.pending[:0]
+
+	return nil
+}
+
+// close writes the pending probes and closes the database.
+func (db *database) close() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "\n"+msg("db.write-probes-failed")+"\n", db.dbPath, err)
+	}
+	db.conn.Close()
+}
+
+// probeFilter returns the filter of the probe rows of the target of userInput.
+func (db *database) probeFilter(userInput userInput) probeFilter {
+	filter := probeFilter{tableName: db.table(userInput)}
+
+	// the addresses of --all-addresses and --dual-stack share the table of their hostname
+	if userInput.allAddresses || userInput.family != "" {
+		filter.addr = userInput.ip.String()
+	}
+
+	// the targets of an SRV name share the table of the name
+	if userInput.srvName != "" {
+		filter.hostname = userInput.hostname
+		filter.port = userInput.port
+	}
+
+	return filter
+}
+
+// markReset records the last probe row of the target of userInput when its statistics are reset,
+// so that the rows of the probes before the reset are left out of them.
+func (db *database) markReset(userInput userInput) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.flush(); err != nil {
+		return err
+	}
+
+	filter := db.probeFilter(userInput)
+	return sqlitex.Execute(db.conn, fmt.Sprintf("SELECT IFNULL(MAX(id), 0) FROM %s", filter.tableName), &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			db.resets[filter] = stmt.ColumnInt64(0)
+			return nil
+		},
+	})
+}
+
+// probeSummary computes the probe counts, the RTT statistics and the last probes
+// of a target from its probe rows after the row with id afterID.
+func (db *database) probeSummary(filter probeFilter, afterID int64) (probeSummary, error) {
+	// %[1]s will be replaced by the table name
+	where := `event_type = ? AND id > ? AND (? = '' OR addr = ?) AND (? = '' OR (hostname = ? AND port = ?))`
+	args := []interface{}{eventTypeProbe, afterID, filter.addr, filter.addr, filter.hostname, filter.hostname, filter.port}
+
+	query := `SELECT
+	IFNULL(SUM(success), 0),
+	IFNULL(SUM(NOT success), 0),
+	IFNULL(MAX(CASE WHEN success THEN timestamp END), ''),
+	IFNULL(MAX(CASE WHEN NOT success THEN timestamp END), ''),
+	IFNULL((SELECT sourceAddr FROM %[1]s WHERE ` + where + ` AND success ORDER BY id DESC LIMIT 1), '')
+	FROM %[1]s WHERE ` + where
+
+	var summary probeSummary
+	err := sqlitex.Execute(db.conn, fmt.Sprintf(query, filter.tableName), &sqlitex.ExecOptions{
+		Args: append(args, args...),
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			summary.successfulProbes = uint(stmt.ColumnInt64(0))
+			summary.unsuccessfulProbes = uint(stmt.ColumnInt64(1))
+			summary.lastSuccessfulProbe = stmt.ColumnText(2)
+			summary.lastUnsuccessfulProbe = stmt.ColumnText(3)
+			summary.sourceAddr = stmt.ColumnText(4)
+			return nil
+		},
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	// the latencies are added in the order of the probes for the jitter
+	var rtt rttStats
+	err = sqlitex.Execute(db.conn, fmt.Sprintf(`SELECT latency FROM %s WHERE `+where+` AND success ORDER BY id`, filter.tableName), &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			rtt.add(float32(stmt.ColumnFloat(0)))
+			return nil
+		},
+	})
+	summary.rtt = rtt.result()
+
+	return summary, err
+}
+
+// saveStats saves stats to the database with proper formatting.
+//
+// The probe counts, the RTT statistics and the last probes are all computed from
+// the probe rows of the target after the last reset of its statistics, see markReset.
 func (db *database) saveStats(tcping tcping) error {
-	totalPackets := tcping.totalSuccessfulProbes + tcping.totalUnsuccessfulProbes
-	packetLoss := (float32(tcping.totalUnsuccessfulProbes) / float32(totalPackets)) * 100
-	if math.IsNaN(float64(packetLoss)) {
-		packetLoss = 0
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.flush(); err != nil {
+		return err
 	}
 
-	// If the time is zero, that means it never failed.
-	// In this case, the time should be empty instead of "0001-01-01 00:00:00".
-	// Rather, it should be left empty.
-	lastSuccessfulProbe := tcping.lastSuccessfulProbe.Format(timeFormat)
-	var neverSucceedProbe, neverFailedProbe bool
-	if tcping.lastSuccessfulProbe.IsZero() {
-		lastSuccessfulProbe = ""
-		neverSucceedProbe = true
+	filter := db.probeFilter(tcping.userInput)
+	tableName := filter.tableName
+	summary, err := db.probeSummary(filter, db.resets[filter])
+	if err != nil {
+		return err
 	}
-	lastUnsuccessfulProbe := tcping.lastUnsuccessfulProbe.Format(timeFormat)
-	if tcping.lastUnsuccessfulProbe.IsZero() {
-		lastUnsuccessfulProbe = ""
-		neverFailedProbe = true
+
+	totalPackets := summary.successfulProbes + summary.unsuccessfulProbes
+	packetLoss := (float32(summary.unsuccessfulProbes) / float32(totalPackets)) * 100
+	if math.IsNaN(float64(packetLoss)) {
+		packetLoss = 0
 	}
 
+	// If a probe never succeeded or failed, the time is left empty
+	// instead of "0001-01-01 00:00:00".
+	neverSucceedProbe := summary.lastSuccessfulProbe == ""
+	neverFailedProbe := summary.lastUnsuccessfulProbe == ""
+
 	// if the longest uptime is empty, then the column should also be empty
-	var longestUptimeDuration, longestUptimeStart, longestUptimeEnd string
-	var longestDowntimeDuration, longestDowntimeStart, longestDowntimeEnd string
-	longestUptimeDuration = "0s"
-	longestDowntimeDuration = "0s"
+	var longestUptimeStart, longestUptimeEnd string
+	var longestDowntimeStart, longestDowntimeEnd string
 
 	if !tcping.longestUptime.start.IsZero() {
-		longestUptimeDuration = tcping.longestUptime.duration.String()
 		longestUptimeStart = tcping.longestUptime.start.Format(timeFormat)
 		longestUptimeEnd = tcping.longestUptime.end.Format(timeFormat)
 	}
 
 	if !tcping.longestDowntime.start.IsZero() {
-		longestDowntimeDuration = tcping.longestDowntime.duration.String()
 		longestDowntimeStart = tcping.longestDowntime.start.Format(timeFormat)
 		longestDowntimeEnd = tcping.longestDowntime.end.Format(timeFormat)
 	}
 
-	var totalDuration string
+	var totalDuration time.Duration
 	if tcping.endTime.IsZero() {
-		totalDuration = time.Since(tcping.startTime).String()
+		totalDuration = time.Since(tcping.startTime)
 	} else {
-		totalDuration = tcping.endTime.Sub(tcping.startTime).String()
+		totalDuration = tcping.endTime.Sub(tcping.startTime)
+	}
+
+	failuresByKind := "{}"
+	if len(tcping.failuresByKind) > 0 {
+		b, err := json.Marshal(tcping.failuresByKind)
+		if err != nil {
+			return err
+		}
+		failuresByKind = string(b)
+	}
+
+	var tlsVersion, tlsCipher, certSubject, certNotAfter, certDaysLeft any
+	if tcping.lastTLS != nil {
+		tlsVersion = tcping.lastTLS.version
+		tlsCipher = tcping.lastTLS.cipher
+		certSubject = tcping.lastTLS.subject
+		certNotAfter = tcping.lastTLS.notAfter.Format(timeFormat)
+		certDaysLeft = tcping.lastTLS.daysLeft()
+	}
+
+	race := make([]any, 7)
+	if tcping.userInput.happyEyeballs != nil {
+		s := tcping.race.summary()
+		race = []any{s.Races, s.IPv4Wins, s.IPv6Wins, s.IPv6Losses, s.Fallbacks,
+			fmt.Sprintf("%.3f", s.FallbackAvg), fmt.Sprintf("%.3f", s.FallbackMax)}
+	}
+
+	srv := make([]any, 3)
+	if tcping.userInput.srvName != "" {
+		srv = []any{tcping.userInput.srvName, tcping.srvRecord.Priority, tcping.srvRecord.Weight}
+	}
+
+	dns := make([]any, 5)
+	if !tcping.destIsIP {
+		dns[0], dns[1] = tcping.dns.lookups, tcping.dns.failures
+		if result := tcping.dns.latency.result(); result.hasResults {
+			dns[2] = fmt.Sprintf("%.3f", result.min)
+			dns[3] = fmt.Sprintf("%.3f", result.average)
+			dns[4] = fmt.Sprintf("%.3f", result.max)
+		}
+	}
+
+	phases := make([]any, 0, 3*phaseCount)
+	for p := range phaseCount {
+		if result := tcping.phases[p].result(); result.hasResults {
+			phases = append(phases,
+				fmt.Sprintf("%.3f", result.min),
+				fmt.Sprintf("%.3f", result.average),
+				fmt.Sprintf("%.3f", result.max),
+			)
+		} else {
+			phases = append(phases, nil, nil, nil)
+		}
 	}
 
-	// TODO: Find a clean way to include source address
-	// other printers utilize printProbeSuccess which takes the net.Conn
-	// whereas DB is having its own way
 	args := []interface{}{
 		eventTypeStatistics,
 		time.Now().Format(timeFormat),
 		tcping.userInput.ip.String(),
-		"source address",
+		summary.sourceAddr,
 		tcping.userInput.hostname,
 		tcping.userInput.port,
 		tcping.retriedHostnameLookups,
-		tcping.totalSuccessfulProbes,
-		tcping.totalUnsuccessfulProbes,
+		summary.successfulProbes,
+		summary.unsuccessfulProbes,
 		neverSucceedProbe,
 		neverFailedProbe,
-		lastSuccessfulProbe,
-		lastUnsuccessfulProbe,
+		summary.lastSuccessfulProbe,
+		summary.lastUnsuccessfulProbe,
 		totalPackets,
 		packetLoss,
-		tcping.totalUptime.String(),
-		tcping.totalDowntime.String(),
-		longestUptimeDuration,
+		durationToSeconds(tcping.totalUptime),
+		durationToSeconds(tcping.totalDowntime),
+		durationToSeconds(tcping.longestUptime.duration),
 		longestUptimeStart,
 		longestUptimeEnd,
-		longestDowntimeDuration,
+		durationToSeconds(tcping.longestDowntime.duration),
 		longestDowntimeStart,
 		longestDowntimeEnd,
-		fmt.Sprintf("%.3f", tcping.rttResults.min),
-		fmt.Sprintf("%.3f", tcping.rttResults.average),
-		fmt.Sprintf("%.3f", tcping.rttResults.max),
+		fmt.Sprintf("%.3f", summary.rtt.min),
+		fmt.Sprintf("%.3f", summary.rtt.average),
+		fmt.Sprintf("%.3f", summary.rtt.max),
+		fmt.Sprintf("%.3f", summary.rtt.median),
+		fmt.Sprintf("%.3f", summary.rtt.p90),
+		fmt.Sprintf("%.3f", summary.rtt.p95),
+		fmt.Sprintf("%.3f", summary.rtt.p99),
+		fmt.Sprintf("%.3f", summary.rtt.stdDev),
+		fmt.Sprintf("%.3f", summary.rtt.jitter),
+	}
+	args = append(args, phases...)
+	args = append(args,
 		tcping.startTime.Format(timeFormat),
 		tcping.endTime.Format(timeFormat),
-		totalDuration,
-	}
+		durationToSeconds(totalDuration),
+		failuresByKind,
+		string(tcping.state()),
+		tcping.stateChanges,
+		tcping.flaps,
+		tlsVersion,
+		tlsCipher,
+		certSubject,
+		certNotAfter,
+		certDaysLeft,
+	)
+	args = append(args, race...)
+	args = append(args, dns...)
+	args = append(args, srv...)
 
 	return sqlitex.Execute(
 		db.conn,
-		fmt.Sprintf(statSaveSchema, db.tableName),
+		fmt.Sprintf(statSaveSchema, tableName),
 		&sqlitex.ExecOptions{Args: args},
 	)
 }
 
 // saveHostNameChang saves the hostname changes
 // in multiple rows with event_type = eventTypeHostnameChange
-func (db *database) saveHostNameChange(h []hostnameChange) error {
+func (db *database) saveHostNameChange(tableName string, h []hostnameChange) error {
 	// %s will be replaced by the table name
 	schema := `INSERT INTO %s
 	(event_type, hostname_changed_to, hostname_change_time)
@@ -236,7 +673,7 @@ func (db *database) saveHostNameChange(h []hostnameChange) error {
 		if host.Addr.String() == "" {
 			continue
 		}
-		err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, db.tableName), &sqlitex.ExecOptions{
+		err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, tableName), &sqlitex.ExecOptions{
 			Args: []interface{}{eventTypeHostnameChange, host.Addr.String(), host.When.Format(timeFormat)}})
 		if err != nil {
 			return err
@@ -246,10 +683,137 @@ func (db *database) saveHostNameChange(h []hostnameChange) error {
 	return nil
 }
 
+// saveSRVChanges saves the times the target was added to
+// or removed from the records of its SRV name
+// in multiple rows with event_type = eventTypeSRVChange
+func (db *database) saveSRVChanges(tableName string, tcping tcping) error {
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, hostname, port, srv_name, srv_change)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	for _, change := range tcping.srvChanges {
+		srvChange := "removed"
+		if change.Added {
+			srvChange = "added"
+		}
+		err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, tableName), &sqlitex.ExecOptions{
+			Args: []interface{}{eventTypeSRVChange, change.When.Format(timeFormat), tcping.userInput.hostname,
+				tcping.userInput.port, tcping.userInput.srvName, srvChange}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printStateChange saves the state of the target
+// when it starts or stops flapping.
+func (db *database) printStateChange(userInput userInput, state targetState) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, addr, hostname, port, state)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, db.table(userInput)), &sqlitex.ExecOptions{
+		Args: []interface{}{eventTypeStateChange, time.Now().Format(timeFormat), userInput.ip.String(), userInput.hostname, userInput.port, string(state)}})
+	if err != nil {
+		db.printError("\n"+msg("db.write-state-change-failed"), db.dbPath, err)
+	}
+}
+
+// printCheckResult saves the health check of a target in --check mode.
+func (db *database) printCheckResult(userInput userInput, result checkResult) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, addr, hostname, port, check_status, check_value, check_threshold, exit_code)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var value, threshold any
+	if result.status != checkHealthy && result.status != checkNoSuccess {
+		value = result.value
+		threshold = result.threshold
+	}
+
+	err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, db.table(userInput)), &sqlitex.ExecOptions{
+		Args: []interface{}{eventTypeCheck, time.Now().Format(timeFormat), userInput.ip.String(), userInput.hostname, userInput.port,
+			result.status.String(), value, threshold, int(result.status)}})
+	if err != nil {
+		db.printError("\n"+msg("db.write-check-failed"), db.dbPath, err)
+	}
+}
+
+// printAddressSummary saves the result of every address of a hostname in --all-addresses mode.
+func (db *database) printAddressSummary(summary addressSummary) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, addr, hostname, port, state, total_packets,
+	total_successful_probes, total_unsuccessful_probes, total_packet_loss, latency_avg)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tableName := db.table(userInput{hostname: summary.Hostname, port: summary.Port})
+	timestamp := time.Now().Format(timeFormat)
+
+	for _, a := range summary.Addresses {
+		var latencyAvg any
+		if a.TotalSuccessfulProbes > 0 {
+			latencyAvg = fmt.Sprintf("%.3f", a.LatencyAvg)
+		}
+
+		err := sqlitex.Execute(db.conn, fmt.Sprintf(schema, tableName), &sqlitex.ExecOptions{
+			Args: []interface{}{eventTypeAddress, timestamp, a.Addr.String(), summary.Hostname, summary.Port, string(a.State),
+				a.TotalSuccessfulProbes + a.TotalUnsuccessfulProbes, a.TotalSuccessfulProbes, a.TotalUnsuccessfulProbes,
+				fmt.Sprintf("%.2f", a.PacketLoss), latencyAvg}})
+		if err != nil {
+			db.printError("\n"+msg("db.write-addresses-failed"), db.dbPath, err)
+			return
+		}
+	}
+}
+
+// printDualStackSummary saves the comparison of IPv4 and IPv6 of a hostname in --dual-stack mode.
+func (db *database) printDualStackSummary(summary dualStackSummary) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	dualStack, err := json.Marshal(summary)
+	if err != nil {
+		db.printError("\n"+msg("db.write-dual-stack-failed"), db.dbPath, err)
+		return
+	}
+
+	// %s will be replaced by the table name
+	schema := `INSERT INTO %s
+	(event_type, timestamp, hostname, port, dual_stack)
+	VALUES (?, ?, ?, ?, ?)`
+
+	err = sqlitex.Execute(db.conn, fmt.Sprintf(schema, db.table(userInput{hostname: summary.Hostname, port: summary.Port})), &sqlitex.ExecOptions{
+		Args: []interface{}{eventTypeDualStack, time.Now().Format(timeFormat), summary.Hostname, summary.Port, string(dualStack)}})
+	if err != nil {
+		db.printError("\n"+msg("db.write-dual-stack-failed"), db.dbPath, err)
+	}
+}
+
+// printSessions is not used by the report subcommand, which only reads databases.
+func (db *database) printSessions(_ string, _ []reportSession) {}
+
+// printReport is not used by the report subcommand, which only reads databases.
+func (db *database) printReport(_ sessionReport) {}
+
 // printStart will let the user know the program is running by
 // printing a msg with the hostname, and port number to stdout
 func (db *database) printStart(hostname string, port uint16) {
-	fmt.Printf("TCPinging %s on port %d\n", hostname, port)
+	fmt.Printf(msg("probe.start")+"\n", hostname, port)
 }
 
 // printStatistics saves the statistics to the given database
@@ -257,19 +821,25 @@ func (db *database) printStart(hostname string, port uint16) {
 func (db *database) printStatistics(tcping tcping) {
 	err := db.saveStats(tcping)
 	if err != nil {
-		db.printError("\nError while writing stats to the database %q\nerr: %s", db.dbPath, err)
+		db.printError("\n"+msg("db.write-stats-failed"), db.dbPath, err)
 	}
 
 	// Hostname changes should be written during the final call.
 	// If the endTime is 0, it indicates that this is not the last call.
 	if !tcping.endTime.IsZero() {
-		err = db.saveHostNameChange(tcping.hostnameChanges)
+		db.mu.Lock()
+		err = db.saveHostNameChange(db.table(tcping.userInput), tcping.hostnameChanges)
+		srvErr := db.saveSRVChanges(db.table(tcping.userInput), tcping)
+		db.mu.Unlock()
 		if err != nil {
-			db.printError("\nError while writing hostname changes to the database %q\nerr: %s", db.dbPath, err)
+			db.printError("\n"+msg("db.write-hostname-changes-failed"), db.dbPath, err)
+		}
+		if srvErr != nil {
+			db.printError("\n"+msg("db.write-srv-changes-failed"), db.dbPath, srvErr)
 		}
 	}
 
-	colorYellow("\nStatistics for %q have been saved to %q in the table %q\n", tcping.userInput.hostname, db.dbPath, db.tableName)
+	colorYellow("\n"+msg("db.stats-saved")+"\n", tcping.userInput.hostname, db.dbPath, db.table(tcping.userInput))
 }
 
 // printError prints the err to the stderr and exits with status code 1
@@ -278,10 +848,39 @@ func (db *database) printError(format string, args ...any) {
 	os.Exit(1)
 }
 
+// printWarning prints the message to the stderr without exiting
+func (db *database) printWarning(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// printProbeSuccess saves a successful probe.
+func (db *database) printProbeSuccess(sourceAddr string, userInput userInput, _ uint, rtt float32, _ probeInfo) {
+	db.addProbe(probeRow{
+		tableName:  db.table(userInput),
+		timestamp:  time.Now().Format(timeFormat),
+		addr:       userInput.ip.String(),
+		sourceAddr: sourceAddr,
+		hostname:   userInput.hostname,
+		port:       userInput.port,
+		success:    true,
+		latency:    fmt.Sprintf("%.3f", rtt),
+	})
+}
+
+// printProbeFail saves a failed probe with its reason.
+func (db *database) printProbeFail(userInput userInput, _ uint, kind errorKind, _ probeInfo) {
+	db.addProbe(probeRow{
+		tableName: db.table(userInput),
+		timestamp: time.Now().Format(timeFormat),
+		addr:      userInput.ip.String(),
+		hostname:  userInput.hostname,
+		port:      userInput.port,
+		errorKind: kind,
+	})
+}
+
 // Satisfying the "printer" interface.
-func (db *database) printProbeSuccess(_ string, _ userInput, _ uint, _ float32) {}
-func (db *database) printProbeFail(_ userInput, _ uint)                         {}
-func (db *database) printRetryingToResolve(_ string)                            {}
-func (db *database) printTotalDownTime(_ time.Duration)                         {}
-func (db *database) printVersion()                                              {}
-func (db *database) printInfo(_ string, _ ...any)                               {}
+func (db *database) printRetryingToResolve(_ string)                 {}
+func (db *database) printTotalDownTime(_ userInput, _ time.Duration) {}
+func (db *database) printVersion()                                   {}
+func (db *database) printInfo(_ string, _ ...any)                    {}
@@ -37,6 +37,14 @@ func TestDbSaveStats(t *testing.T) {
 	defer db.conn.Close()
 
 	stat := mockStats()
+
+	// the probe counts, the latencies and the last probes are computed from these
+	probeStart := time.Now().Truncate(time.Second)
+	for _, rtt := range []float32{2, 3, 4} {
+		db.printProbeSuccess("192.168.1.2:50000", stat.userInput, 1, rtt, probeInfo{})
+	}
+	db.printProbeFail(stat.userInput, 1, errorKindTimeout, probeInfo{})
+
 	err := db.saveStats(stat)
 	isNil(t, err)
 
@@ -75,17 +83,17 @@ FROM ` + fmt.Sprintf("%s WHERE event_type = '%s'", db.tableName, eventTypeStatis
 		hostNameResolveTries                           int
 		totalSuccessfulProbes, totalUnsuccessfulProbes uint
 		neverSucceedProbe, neverFailedProbe            bool
-		lastSuccessfulProbe                            time.Time
+		lastSuccessfulProbe, lastUnsuccessfulProbe     time.Time
 		totalPackets                                   uint
 		totalPacketsLoss                               float32
-		totalUptime, totalDowntime                     string
-		longestUptime                                  string
+		totalUptime, totalDowntime                     float64
+		longestUptime                                  float64
 		longestUptimeStart, longestUptimeEnd           string
-		longestDowntime                                string
+		longestDowntime                                float64
 		longestDowntimeStart, longestDowntimeEnd       string
 		lMin, lAvg, lMax                               float32
 		startTimestamp, endTimestamp                   time.Time
-		totalDuration                                  string
+		totalDuration                                  float64
 	)
 
 	resFunc := func(stmt *sqlite.Stmt) error {
@@ -111,27 +119,27 @@ FROM ` + fmt.Sprintf("%s WHERE event_type = '%s'", db.tableName, eventTypeStatis
 		// never_failed_probe
 		neverFailedProbe = stmt.ColumnBool(8)
 		// last_successful_probe
-		lastSuccessfulProbe, err = time.Parse(timeFormat, stmt.ColumnText(9))
+		lastSuccessfulProbe, err = time.ParseInLocation(timeFormat, stmt.ColumnText(9), time.Local)
 		isNil(t, err)
 		// last_unsuccessful_probe
-		Equals(t, "", stmt.ColumnText(10)) // simulating never failed
-		// isNil(t, err)
+		lastUnsuccessfulProbe, err = time.ParseInLocation(timeFormat, stmt.ColumnText(10), time.Local)
+		isNil(t, err)
 		// total_packets
 		totalPackets = uint(stmt.ColumnInt(11))
 		// total_packet_loss
 		totalPacketsLoss = float32(stmt.ColumnFloat(12))
 		// total_uptime
-		totalUptime = stmt.ColumnText(13)
+		totalUptime = stmt.ColumnFloat(13)
 		// total_downtime
-		totalDowntime = stmt.ColumnText(14)
+		totalDowntime = stmt.ColumnFloat(14)
 		// longest_uptime
-		longestUptime = stmt.ColumnText(15)
+		longestUptime = stmt.ColumnFloat(15)
 		// longest_uptime_start
 		longestUptimeStart = stmt.ColumnText(16)
 		// longest_uptime_end
 		longestUptimeEnd = stmt.ColumnText(17)
 		// longest_downtime
-		longestDowntime = stmt.ColumnText(18)
+		longestDowntime = stmt.ColumnFloat(18)
 		// longest_downtime_start
 		longestDowntimeStart = stmt.ColumnText(19)
 		// longest_downtime_end
@@ -149,7 +157,7 @@ FROM ` + fmt.Sprintf("%s WHERE event_type = '%s'", db.tableName, eventTypeStatis
 		endTimestamp, err = time.Parse(timeFormat, stmt.ColumnText(25))
 		isNil(t, err)
 		// total_duration
-		totalDuration = stmt.ColumnText(26)
+		totalDuration = stmt.ColumnFloat(26)
 		return nil
 	}
 
@@ -158,47 +166,88 @@ FROM ` + fmt.Sprintf("%s WHERE event_type = '%s'", db.tableName, eventTypeStatis
 	})
 	isNil(t, err)
 
-	stat.rttResults.min = toFixedFloat(stat.rttResults.min, 3)
-	stat.rttResults.average = toFixedFloat(stat.rttResults.average, 3)
-	stat.rttResults.max = toFixedFloat(stat.rttResults.max, 3)
-
 	Equals(t, addr, stat.userInput.ip.String())
-	Equals(t, sourceAddr, "source address")
+	Equals(t, sourceAddr, "192.168.1.2:50000")
 	Equals(t, hostname, stat.userInput.hostname)
-	Equals(t, totalUnsuccessfulProbes, stat.totalUnsuccessfulProbes)
-	Equals(t, totalSuccessfulProbes, stat.totalSuccessfulProbes)
+	Equals(t, totalUnsuccessfulProbes, 1)
+	Equals(t, totalSuccessfulProbes, 3)
 	Equals(t, port, strconv.Itoa(int(stat.userInput.port)))
 
 	Equals(t, hostNameResolveTries, int(stat.retriedHostnameLookups))
-	packetLoss := (float32(stat.totalUnsuccessfulProbes) / float32(stat.totalSuccessfulProbes+stat.totalUnsuccessfulProbes)) * 100
-	Equals(t, totalPacketsLoss, packetLoss)
+	Equals(t, totalPacketsLoss, 25)
 
-	Equals(t, neverSucceedProbe, stat.lastSuccessfulProbe.IsZero())
-	Equals(t, neverFailedProbe, stat.lastUnsuccessfulProbe.IsZero())
+	Equals(t, neverSucceedProbe, false)
+	Equals(t, neverFailedProbe, false)
 
-	Equals(t, lastSuccessfulProbe.Format(timeFormat), stat.lastSuccessfulProbe.Format(timeFormat))
+	Equals(t, lastSuccessfulProbe.Before(probeStart), false)
+	Equals(t, lastUnsuccessfulProbe.Before(probeStart), false)
 
-	Equals(t, lMin, stat.rttResults.min)
-	Equals(t, lAvg, stat.rttResults.average)
-	Equals(t, lMax, stat.rttResults.max)
+	Equals(t, lMin, 2)
+	Equals(t, lAvg, 3)
+	Equals(t, lMax, 4)
 	Equals(t, startTimestamp.Format(timeFormat), stat.startTime.Format(timeFormat))
 	Equals(t, endTimestamp.Format(timeFormat), stat.endTime.Format(timeFormat))
 
-	actualDuration := stat.endTime.Sub(stat.startTime).String()
-	Equals(t, totalDuration, actualDuration)
-	Equals(t, totalUptime, stat.totalUptime.String())
-	Equals(t, totalDowntime, stat.totalDowntime.String())
-	Equals(t, totalPackets, stat.totalSuccessfulProbes+stat.totalUnsuccessfulProbes)
+	// durations are stored in seconds with millisecond precision
+	Equals(t, totalDuration, math.Round(stat.endTime.Sub(stat.startTime).Seconds()*1000)/1000)
+	Equals(t, totalUptime, stat.totalUptime.Seconds())
+	Equals(t, totalDowntime, stat.totalDowntime.Seconds())
+	Equals(t, totalPackets, 4)
 
-	Equals(t, longestUptime, stat.longestUptime.duration.String())
+	Equals(t, longestUptime, stat.longestUptime.duration.Seconds())
 	Equals(t, longestUptimeStart, stat.longestUptime.start.Format(timeFormat))
 	Equals(t, longestUptimeEnd, stat.longestUptime.end.Format(timeFormat))
 
-	Equals(t, longestDowntime, stat.longestDowntime.duration.String())
+	Equals(t, longestDowntime, stat.longestDowntime.duration.Seconds())
 	Equals(t, longestDowntimeStart, stat.longestDowntime.start.Format(timeFormat))
 	Equals(t, longestDowntimeEnd, stat.longestDowntime.end.Format(timeFormat))
 }
 
+func TestDbProbeBatching(t *testing.T) {
+	arg := []string{"localhost", "8001"}
+	db := newDB(":memory:", arg)
+	defer db.conn.Close()
+
+	userInput := mockStats().userInput
+	countProbes := func() int {
+		count := -1
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE event_type = '%s'", db.tableName, eventTypeProbe)
+		err := sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				count = stmt.ColumnInt(0)
+				return nil
+			},
+		})
+		isNil(t, err)
+		return count
+	}
+
+	for range dbBatchSize - 1 {
+		db.printProbeSuccess("192.168.1.2:50000", userInput, 1, 1, probeInfo{})
+	}
+	Equals(t, countProbes(), 0)
+
+	// a full batch is written in a single transaction
+	db.printProbeFail(userInput, 1, errorKindRefused, probeInfo{})
+	Equals(t, countProbes(), dbBatchSize)
+
+	query := fmt.Sprintf("SELECT success, latency IS NULL, error_kind FROM %s WHERE event_type = '%s' ORDER BY id DESC LIMIT 1", db.tableName, eventTypeProbe)
+	err := sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			Equals(t, stmt.ColumnBool(0), false)
+			Equals(t, stmt.ColumnBool(1), true)
+			Equals(t, stmt.ColumnText(2), string(errorKindRefused))
+			return nil
+		},
+	})
+	isNil(t, err)
+
+	// pending probes are written when the statistics are saved
+	db.printProbeSuccess("192.168.1.2:50000", userInput, 1, 1, probeInfo{})
+	isNil(t, db.saveStats(mockStats()))
+	Equals(t, countProbes(), dbBatchSize+1)
+}
+
 func TestSaveHostname(t *testing.T) {
 	// There are many fields, so many things could go wrong; that's why this elaborate test.
 	arg := []string{"local-.host", "8001"}
@@ -220,7 +269,7 @@ func TestSaveHostname(t *testing.T) {
 
 	stat := mockStats()
 
-	err = db.saveHostNameChange(stat.hostnameChanges)
+	err = db.saveHostNameChange(db.tableName, stat.hostnameChanges)
 	isNil(t, err)
 
 	// testing the host names if they are properly written
@@ -245,6 +294,133 @@ func TestSaveHostname(t *testing.T) {
 	Equals(t, idx, len(stat.hostnameChanges))
 }
 
+func TestDbSaveStatsAfterReset(t *testing.T) {
+	db := newDB(":memory:", []string{"localhost", "8001"})
+	defer db.conn.Close()
+
+	stat := mockStats()
+
+	// the probes before the reset of the statistics are left out,
+	// even when they were made within the same second
+	db.printProbeFail(stat.userInput, 1, errorKindTimeout, probeInfo{})
+	db.printProbeSuccess("", stat.userInput, 1, 100, probeInfo{})
+	target := stat
+	target.printer = db
+	target.resetStats()
+	db.printProbeSuccess("", stat.userInput, 1, 2, probeInfo{})
+	db.printProbeSuccess("", stat.userInput, 1, 4, probeInfo{})
+	isNil(t, db.saveStats(stat))
+
+	// all the RTT statistics come from the same probes, not from stat.rttResults
+	var rtt rttStats
+	rtt.add(2)
+	rtt.add(4)
+	want := rtt.result()
+	saved := func(value float32) float64 {
+		f, _ := strconv.ParseFloat(fmt.Sprintf("%.3f", value), 64)
+		return f
+	}
+
+	query := fmt.Sprintf(`SELECT total_successful_probes, total_unsuccessful_probes, latency_min, latency_max,
+		latency_median, latency_p99, latency_jitter, last_unsuccessful_probe
+		FROM %s WHERE event_type = '%s'`, db.tableName, eventTypeStatistics)
+	err := sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			Equals(t, stmt.ColumnInt(0), 2)
+			Equals(t, stmt.ColumnInt(1), 0)
+			Equals(t, stmt.ColumnFloat(2), 2)
+			Equals(t, stmt.ColumnFloat(3), 4)
+			Equals(t, stmt.ColumnFloat(4), saved(want.median))
+			Equals(t, stmt.ColumnFloat(5), saved(want.p99))
+			Equals(t, stmt.ColumnFloat(6), 2)
+			Equals(t, stmt.ColumnText(7), "")
+			return nil
+		},
+	})
+	isNil(t, err)
+}
+
+func TestDbTableOfTarget(t *testing.T) {
+	db := newDB(":memory:", []string{"localhost", "8001", "localhost", "0443"})
+	defer db.conn.Close()
+
+	Equals(t, len(db.tables), 2)
+	Equals(t, db.table(userInput{hostname: "localhost", port: 8001}), db.tableName)
+
+	// the port is normalized, as in userInput.hostPort()
+	tableName := db.table(userInput{hostname: "localhost", port: 443})
+	Equals(t, tableName, fmt.Sprintf("localhost_443_%s", time.Now().Format("15_04_05_01_02_2006")))
+}
+
+func TestDbRepeatedTargets(t *testing.T) {
+	db := newDB(":memory:", []string{"localhost", "80", "localhost", "080", "a-b.com", "80", "a.b.com", "80"})
+	defer db.conn.Close()
+
+	// a target given twice shares its table, while targets with the same table name don't
+	now := time.Now().Format("15_04_05_01_02_2006")
+	Equals(t, len(db.tables), 3)
+	Equals(t, db.tables["localhost:80"], fmt.Sprintf("localhost_80_%s", now))
+	Equals(t, db.tables["a-b.com:80"], fmt.Sprintf("a_b_com_80_%s", now))
+	Equals(t, db.tables["a.b.com:80"], fmt.Sprintf("a_b_com_80_%s_2", now))
+}
+
+func TestNewTableNameIPv6(t *testing.T) {
+	tableName := newTableName([]string{"2001:db8::1", "443"})
+	Equals(t, tableName, fmt.Sprintf("_2001_db8__1_443_%s", time.Now().Format("15_04_05_01_02_2006")))
+
+	db := newDB(":memory:", []string{"::1", "80"})
+	defer db.conn.Close()
+	Equals(t, db.table(userInput{hostname: "::1", port: 80}), fmt.Sprintf("__1_80_%s", time.Now().Format("15_04_05_01_02_2006")))
+}
+
+func TestDbSRVTargets(t *testing.T) {
+	db := newDB(":memory:", []string{"_sip._tcp.example.com", "localhost", "8001"})
+	defer db.conn.Close()
+
+	// the targets of an SRV name share its table
+	expectedTableName := fmt.Sprintf("_sip__tcp_example_com_srv_%s", time.Now().Format("15_04_05_01_02_2006"))
+	Equals(t, db.tableName, expectedTableName)
+	Equals(t, len(db.tables), 2)
+
+	first, second := mockStats(), mockStats()
+	first.userInput.srvName, second.userInput.srvName = "_sip._tcp.example.com", "_sip._tcp.example.com"
+	second.userInput.hostname, second.userInput.port = "sip.example.com", 5060
+	second.srvRecord = srvRecord{Target: "sip.example.com", Port: 5060, Priority: 10, Weight: 20}
+	second.srvChanges = []srvChange{{Added: false, When: time.Now()}}
+	Equals(t, db.table(second.userInput), expectedTableName)
+
+	db.printProbeSuccess("", first.userInput, 1, 1, probeInfo{})
+	db.printProbeFail(second.userInput, 1, errorKindTimeout, probeInfo{})
+	db.printProbeFail(second.userInput, 2, errorKindTimeout, probeInfo{})
+	isNil(t, db.saveStats(second))
+	isNil(t, db.saveSRVChanges(db.tableName, second))
+
+	query := fmt.Sprintf(`SELECT total_successful_probes, total_unsuccessful_probes, srv_name, srv_priority, srv_weight
+		FROM %s WHERE event_type = '%s'`, db.tableName, eventTypeStatistics)
+	err := sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			Equals(t, stmt.ColumnInt(0), 0)
+			Equals(t, stmt.ColumnInt(1), 2)
+			Equals(t, stmt.ColumnText(2), "_sip._tcp.example.com")
+			Equals(t, stmt.ColumnInt(3), 10)
+			Equals(t, stmt.ColumnInt(4), 20)
+			return nil
+		},
+	})
+	isNil(t, err)
+
+	query = fmt.Sprintf("SELECT hostname, port, srv_change FROM %s WHERE event_type = '%s'", db.tableName, eventTypeSRVChange)
+	err = sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			Equals(t, stmt.ColumnText(0), "sip.example.com")
+			Equals(t, stmt.ColumnInt(1), 5060)
+			Equals(t, stmt.ColumnText(2), "removed")
+			return nil
+		},
+	})
+	isNil(t, err)
+}
+
 func hostNameChange() []hostnameChange {
 	ipAddresses := []string{
 		"192.168.1.1",
@@ -0,0 +1,16 @@
+//go:build !windows
+
+// dialerror_other.go contains the error codes of failed dials on the systems other than Windows
+package main
+
+import "syscall"
+
+// The errnos of a failed connection attempt that are mapped to an errorKind by classifyDialError.
+var (
+	timeoutErrnos        = []error{syscall.ETIMEDOUT}
+	refusedErrnos        = []error{syscall.ECONNREFUSED}
+	resetErrnos          = []error{syscall.ECONNRESET}
+	netUnreachableErrnos = []error{syscall.ENETUNREACH}
+	noRouteErrnos        = []error{syscall.EHOSTUNREACH}
+	permissionErrnos     = []error{syscall.EACCES, syscall.EPERM}
+)
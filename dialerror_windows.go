@@ -0,0 +1,19 @@
+//go:build windows
+
+// dialerror_windows.go contains the error codes of failed dials on Windows
+package main
+
+import "golang.org/x/sys/windows"
+
+// The errors of a failed connection attempt that are mapped to an errorKind by classifyDialError.
+//
+// Windows reports the WSA error codes of Winsock, or the system error codes
+// of the overlapped connect, instead of the errnos of syscall, which it only emulates.
+var (
+	timeoutErrnos        = []error{windows.WSAETIMEDOUT}
+	refusedErrnos        = []error{windows.WSAECONNREFUSED, windows.ERROR_CONNECTION_REFUSED}
+	resetErrnos          = []error{windows.WSAECONNRESET}
+	netUnreachableErrnos = []error{windows.WSAENETUNREACH, windows.ERROR_NETWORK_UNREACHABLE}
+	noRouteErrnos        = []error{windows.WSAEHOSTUNREACH, windows.ERROR_HOST_UNREACHABLE}
+	permissionErrnos     = []error{windows.WSAEACCES}
+)
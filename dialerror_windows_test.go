@@ -0,0 +1,31 @@
+//go:build windows
+
+package main
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestClassifyDialErrorWindows(t *testing.T) {
+	tests := []struct {
+		err  error
+		want errorKind
+	}{
+		{windows.WSAECONNREFUSED, errorKindRefused},
+		{windows.ERROR_CONNECTION_REFUSED, errorKindRefused},
+		{windows.WSAECONNRESET, errorKindReset},
+		{windows.WSAENETUNREACH, errorKindNetUnreachable},
+		{windows.WSAEHOSTUNREACH, errorKindNoRoute},
+		{windows.WSAEACCES, errorKindPermission},
+		{windows.WSAETIMEDOUT, errorKindTimeout},
+	}
+	for _, tt := range tests {
+		err := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connectex", tt.err)}
+		assert.Equal(t, tt.want, classifyDialError(err), tt.err.Error())
+	}
+}
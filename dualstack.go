@@ -0,0 +1,254 @@
+// dualstack.go contains the logic of --dual-stack, probing IPv4 and IPv6 side by side
+package main
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// ipFamily is the IP version of a target in --dual-stack mode.
+type ipFamily string
+
+const (
+	familyIPv4 ipFamily = "ipv4"
+	familyIPv6 ipFamily = "ipv6"
+)
+
+// label returns the name of the IP version, e.g. "IPv4".
+func (f ipFamily) label() string {
+	if f == familyIPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// dualStack probes an IPv4 and an IPv6 address of a hostname in lockstep.
+//
+// It's shared by both targets, but only the loop of the IPv4 target
+// probes them, so that every cycle has a result for both families.
+type dualStack struct {
+	mu   sync.Mutex // mu guards the results below, as the statistics may be printed while probing
+	ipv4 *tcping
+	ipv6 *tcping
+
+	cycles       uint
+	bothUp       uint
+	onlyIPv4Down uint
+	onlyIPv6Down uint
+	bothDown     uint
+	rttDeltaSum  float64 // rttDeltaSum adds up the IPv6 RTT minus the IPv4 RTT of the cycles both succeeded
+
+	outages     [2][]outage // outages are the outages of IPv4 and IPv6
+	onlyOutages [2][]outage // onlyOutages are the periods in which only IPv4 or only IPv6 was down
+}
+
+// familyStats is the result of the probes of one IP version of a hostname.
+type familyStats struct {
+	Family ipFamily `json:"family"`
+	addressStats
+	Outages []outage `json:"outages"`
+}
+
+// familyOutage is a period in which only one IP version was down.
+type familyOutage struct {
+	Family ipFamily `json:"family"`
+	outage
+}
+
+// dualStackSummary compares the IPv4 and IPv6 results of a hostname probed by --dual-stack.
+type dualStackSummary struct {
+	Hostname string        `json:"hostname"`
+	Port     uint16        `json:"port"`
+	Families []familyStats `json:"families"`
+
+	// Cycles counts the cycles, in which both IP versions were probed once.
+	// The other counters split them by the IP versions that were down.
+	Cycles       uint `json:"cycles"`
+	BothUp       uint `json:"cycles_both_up"`
+	OnlyIPv4Down uint `json:"cycles_only_ipv4_down"`
+	OnlyIPv6Down uint `json:"cycles_only_ipv6_down"`
+	BothDown     uint `json:"cycles_both_down"`
+
+	// RTTDelta is the average IPv6 RTT minus the IPv4 RTT in ms
+	// of the cycles in which both succeeded, nil if there are none.
+	RTTDelta *float64 `json:"rtt_delta,omitempty"`
+
+	// SingleFamilyOutages are the periods in which only one IP version was down.
+	SingleFamilyOutages []familyOutage `json:"single_family_outages"`
+}
+
+// target returns the "host:port" label of the hostname.
+func (s dualStackSummary) target() string {
+	return (userInput{hostname: s.Hostname, port: s.Port}).hostPort()
+}
+
+// dualStackTargets resolves an IPv4 and an IPv6 address of the hostname of target
+// and creates a target for each, sharing a dualStack.
+func dualStackTargets(target *tcping) ([]*tcping, error) {
+	ipAddrs, err := lookupNetIP(target)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dualStack{}
+	for _, family := range []ipFamily{familyIPv4, familyIPv6} {
+		t := *target
+		t.userInput.useIPv4 = family == familyIPv4
+		t.userInput.useIPv6 = family == familyIPv6
+
+		addr, err := selectResolvedIP(&t, ipAddrs)
+		if err != nil {
+			return nil, err
+		}
+
+		familyTarget := newAddressTarget(&t, addr)
+		familyTarget.userInput.family = family
+		familyTarget.dualStack = d
+
+		if family == familyIPv4 {
+			d.ipv4 = familyTarget
+		} else {
+			d.ipv6 = familyTarget
+		}
+	}
+
+	return []*tcping{d.ipv4, d.ipv6}, nil
+}
+
+// probe probes both IP versions at the same time and records the result of the cycle.
+func (d *dualStack) probe() {
+	cycleStart := time.Now()
+	successes := [2]uint{d.ipv4.totalSuccessfulProbes, d.ipv6.totalSuccessfulProbes}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		probe(d.ipv6)
+	}()
+	probe(d.ipv4)
+	wg.Wait()
+
+	up := [2]bool{
+		d.ipv4.totalSuccessfulProbes > successes[0],
+		d.ipv6.totalSuccessfulProbes > successes[1],
+	}
+	d.record(cycleStart, up, [2]float32{d.ipv4.rtt.last, d.ipv6.rtt.last})
+}
+
+// record adds the result of a cycle started at t.
+// rtt is only used for the IP versions that are up.
+func (d *dualStack) record(t time.Time, up [2]bool, rtt [2]float32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cycles++
+	switch {
+	case up[0] && up[1]:
+		d.bothUp++
+		d.rttDeltaSum += float64(rtt[1] - rtt[0])
+	case up[1]:
+		d.onlyIPv4Down++
+	case up[0]:
+		d.onlyIPv6Down++
+	default:
+		d.bothDown++
+	}
+
+	for i := range up {
+		d.outages[i] = addToOutages(d.outages[i], t, !up[i])
+		d.onlyOutages[i] = addToOutages(d.onlyOutages[i], t, !up[i] && up[1-i])
+	}
+}
+
+// reset starts the comparison and the statistics of the IPv6 target over.
+// The IPv4 target resets its own statistics.
+func (d *dualStack) reset() {
+	d.ipv6.resetStats()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cycles, d.bothUp, d.onlyIPv4Down, d.onlyIPv6Down, d.bothDown = 0, 0, 0, 0, 0
+	d.rttDeltaSum = 0
+	d.outages = [2][]outage{}
+	d.onlyOutages = [2][]outage{}
+}
+
+// summary returns the comparison of both IP versions.
+func (d *dualStack) summary() dualStackSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := dualStackSummary{
+		Hostname:            d.ipv4.userInput.hostname,
+		SingleFamilyOutages: []familyOutage{},
+		Port:                d.ipv4.userInput.port,
+		Cycles:              d.cycles,
+		BothUp:              d.bothUp,
+		OnlyIPv4Down:        d.onlyIPv4Down,
+		OnlyIPv6Down:        d.onlyIPv6Down,
+		BothDown:            d.bothDown,
+	}
+
+	for i, t := range []*tcping{d.ipv4, d.ipv6} {
+		s.Families = append(s.Families, familyStats{
+			Family:       t.userInput.family,
+			addressStats: newAddressStats(t),
+			Outages:      append([]outage{}, d.outages[i]...),
+		})
+
+		for _, o := range d.onlyOutages[i] {
+			s.SingleFamilyOutages = append(s.SingleFamilyOutages, familyOutage{t.userInput.family, o})
+		}
+	}
+
+	slices.SortStableFunc(s.SingleFamilyOutages, func(a, b familyOutage) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	if d.bothUp > 0 {
+		delta := d.rttDeltaSum / float64(d.bothUp)
+		s.RTTDelta = &delta
+	}
+
+	return s
+}
+
+// dualStackSummaries returns the comparison of every hostname of --dual-stack,
+// in the order of the targets.
+func dualStackSummaries(targets []*tcping) []dualStackSummary {
+	var summaries []dualStackSummary
+	for _, t := range targets {
+		if t.dualStack != nil && t.dualStack.ipv4 == t {
+			summaries = append(summaries, t.dualStack.summary())
+		}
+	}
+	return summaries
+}
+
+// addToOutages adds the result of a probe at t to outages.
+//
+// A failure after a success starts an ongoing outage, which lasts
+// until the next success, like the outages of the report subcommand.
+func addToOutages(outages []outage, t time.Time, failed bool) []outage {
+	ongoing := len(outages) > 0 && outages[len(outages)-1].Ongoing
+
+	switch {
+	case failed && !ongoing:
+		return append(outages, outage{Start: t, End: t, Probes: 1, Ongoing: true})
+	case failed:
+		o := &outages[len(outages)-1]
+		o.End = t
+		o.Probes++
+		o.Duration = o.End.Sub(o.Start).Seconds()
+	case ongoing:
+		o := &outages[len(outages)-1]
+		o.End = t
+		o.Ongoing = false
+		o.Duration = o.End.Sub(o.Start).Seconds()
+	}
+
+	return outages
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToOutages(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	var outages []outage
+	for i, failed := range []bool{false, true, true, false, false, true} {
+		outages = addToOutages(outages, at(i), failed)
+	}
+
+	assert.Equal(t, []outage{
+		{Start: at(1), End: at(3), Duration: 2, Probes: 2},
+		{Start: at(5), End: at(5), Probes: 1, Ongoing: true},
+	}, outages)
+}
+
+func TestDualStackSummary(t *testing.T) {
+	base := createTestStats(t)
+	base.userInput.hostname = "example.com"
+	base.userInput.port = 443
+
+	d := &dualStack{ipv4: createTestStats(t), ipv6: createTestStats(t)}
+	for family, target := range map[ipFamily]*tcping{familyIPv4: d.ipv4, familyIPv6: d.ipv6} {
+		target.userInput = base.userInput
+		target.userInput.family = family
+		target.dualStack = d
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	d.record(at(0), [2]bool{true, true}, [2]float32{10, 14})
+	d.record(at(1), [2]bool{true, false}, [2]float32{10, 0})
+	d.record(at(2), [2]bool{true, false}, [2]float32{10, 0})
+	d.record(at(3), [2]bool{false, false}, [2]float32{0, 0})
+	d.record(at(4), [2]bool{true, true}, [2]float32{12, 14})
+
+	s := d.summary()
+	assert.Equal(t, "example.com:443", s.target())
+	assert.Equal(t, uint(5), s.Cycles)
+	assert.Equal(t, uint(2), s.BothUp)
+	assert.Equal(t, uint(2), s.OnlyIPv6Down)
+	assert.Zero(t, s.OnlyIPv4Down)
+	assert.Equal(t, uint(1), s.BothDown)
+
+	require.NotNil(t, s.RTTDelta)
+	assert.InDelta(t, 3, *s.RTTDelta, 0.001)
+
+	require.Len(t, s.Families, 2)
+	assert.Equal(t, familyIPv4, s.Families[0].Family)
+	assert.Equal(t, []outage{{Start: at(3), End: at(4), Duration: 1, Probes: 1}}, s.Families[0].Outages)
+	assert.Equal(t, familyIPv6, s.Families[1].Family)
+	assert.Equal(t, []outage{{Start: at(1), End: at(4), Duration: 3, Probes: 3}}, s.Families[1].Outages)
+
+	// the cycle in which both were down ends the period in which only IPv6 was down
+	assert.Equal(t, []familyOutage{
+		{familyIPv6, outage{Start: at(1), End: at(3), Duration: 2, Probes: 2}},
+	}, s.SingleFamilyOutages)
+
+	assert.Equal(t, []dualStackSummary{s}, dualStackSummaries([]*tcping{d.ipv4, d.ipv6}))
+
+	d.ipv6.totalSuccessfulProbes = 2
+	d.reset()
+	s = d.summary()
+	assert.Zero(t, s.Cycles)
+	assert.Nil(t, s.RTTDelta)
+	assert.Empty(t, s.SingleFamilyOutages)
+	assert.Zero(t, d.ipv6.totalSuccessfulProbes)
+	assert.Same(t, d, d.ipv6.dualStack, "the targets keep sharing the comparison")
+}
+
+func TestDualStackTarget(t *testing.T) {
+	u := userInput{hostname: "example.com", port: 443, family: familyIPv6}
+	assert.Equal(t, "example.com:443 (IPv6)", u.target())
+	assert.Equal(t, "example.com:443", u.hostPort())
+}
@@ -0,0 +1,105 @@
+// flap.go contains the hysteresis of the state of a target and the detection of flapping
+package main
+
+import "time"
+
+// defaultFlapWindow is the default time window of flap detection, in seconds.
+const defaultFlapWindow = 60
+
+// targetState is the declared state of a target.
+type targetState string
+
+const (
+	stateUp       targetState = "up"
+	stateDown     targetState = "down"
+	stateFlapping targetState = "flapping"
+)
+
+// description returns a human-readable state.
+func (s targetState) description() string {
+	switch s {
+	case stateUp:
+		return msg("state.up")
+	case stateDown:
+		return msg("state.down")
+	case stateFlapping:
+		return msg("state.flapping")
+	default:
+		return msg("state.unknown")
+	}
+}
+
+// stateOptions holds the flags of the state thresholds and of flap detection.
+type stateOptions struct {
+	downAfter     uint
+	upAfter       uint
+	flapThreshold uint
+	flapWindow    float64 // flapWindow is in seconds
+}
+
+// flappingMarker returns a marker for the output of a probe of a flapping target.
+func flappingMarker(info probeInfo) string {
+	if info.state == stateFlapping {
+		return msg("state.flapping-marker")
+	}
+	return ""
+}
+
+// state returns the declared state of the target.
+//
+// A target changes between up and down only after --down-after failures
+// or --up-after successes in a row, and is flapping when it changed
+// its state --flap-threshold times within --flap-window.
+func (t *tcping) state() targetState {
+	switch {
+	case t.flapping:
+		return stateFlapping
+	case t.destWasDown:
+		return stateDown
+	default:
+		return stateUp
+	}
+}
+
+// recordStateChange records a change between up and down for flap detection.
+func (t *tcping) recordStateChange(now time.Time) {
+	t.stateChanges++
+
+	if t.userInput.flapThreshold == 0 {
+		return
+	}
+
+	t.recentStateChanges = append(t.recentStateChanges, now)
+
+	// only the last flapThreshold changes matter
+	if excess := len(t.recentStateChanges) - int(t.userInput.flapThreshold); excess > 0 {
+		t.recentStateChanges = append(t.recentStateChanges[:0], t.recentStateChanges[excess:]...)
+	}
+}
+
+// updateFlapping is called after every probe. It starts flapping when
+// the target changed its state --flap-threshold times within the window,
+// and stops once the state stayed the same for a whole window.
+func (t *tcping) updateFlapping(now time.Time) {
+	if t.userInput.flapThreshold == 0 {
+		return
+	}
+
+	cutoff := now.Add(-t.userInput.flapWindow)
+	expired := 0
+	for expired < len(t.recentStateChanges) && t.recentStateChanges[expired].Before(cutoff) {
+		expired++
+	}
+	t.recentStateChanges = append(t.recentStateChanges[:0], t.recentStateChanges[expired:]...)
+
+	switch {
+	case !t.flapping && len(t.recentStateChanges) >= int(t.userInput.flapThreshold):
+		t.flapping = true
+		t.flaps++
+		t.printStateChange(t.userInput, stateFlapping)
+
+	case t.flapping && len(t.recentStateChanges) == 0:
+		t.flapping = false
+		t.printStateChange(t.userInput, t.state())
+	}
+}
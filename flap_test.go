@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stateChangePrinter records the states of printStateChange.
+type stateChangePrinter struct {
+	dummyPrinter
+	states []targetState
+}
+
+func (p *stateChangePrinter) printStateChange(_ userInput, state targetState) {
+	p.states = append(p.states, state)
+}
+
+func TestDefaultThresholds(t *testing.T) {
+	stats := createTestStats(t)
+	now := time.Now()
+
+	stats.handleConnSuccess("", 1, now, time.Second, probeInfo{})
+	stats.handleConnError(now.Add(time.Second), time.Second, errorKindTimeout, probeInfo{})
+	assert.Equal(t, stateDown, stats.state())
+	assert.Equal(t, now.Add(time.Second), stats.startOfDowntime)
+
+	stats.handleConnSuccess("", 1, now.Add(2*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateUp, stats.state())
+	assert.Equal(t, uint(2), stats.stateChanges)
+	assert.Equal(t, 2*time.Second, stats.totalUptime)
+	assert.Equal(t, time.Second, stats.totalDowntime)
+	assert.Equal(t, time.Second, stats.longestDowntime.duration)
+}
+
+func TestDownAfter(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.downAfter = 3
+	now := time.Now()
+
+	stats.handleConnSuccess("", 1, now, time.Second, probeInfo{})
+
+	// a single failure doesn't make the target down
+	stats.handleConnError(now.Add(time.Second), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, now.Add(2*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateUp, stats.state())
+	assert.Zero(t, stats.stateChanges)
+	assert.Equal(t, 3*time.Second, stats.totalUptime)
+
+	stats.handleConnError(now.Add(3*time.Second), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnError(now.Add(4*time.Second), time.Second, errorKindTimeout, probeInfo{})
+	assert.Equal(t, stateUp, stats.state())
+
+	// the third failure in a row makes the target down since the first one
+	stats.handleConnError(now.Add(5*time.Second), time.Second, errorKindTimeout, probeInfo{})
+	assert.Equal(t, stateDown, stats.state())
+	assert.Equal(t, now.Add(3*time.Second), stats.startOfDowntime)
+	assert.Equal(t, 3*time.Second, stats.totalUptime)
+	assert.Equal(t, 3*time.Second, stats.totalDowntime)
+	assert.Equal(t, 3*time.Second, stats.longestUptime.duration)
+	assert.Equal(t, uint(1), stats.stateChanges)
+}
+
+func TestUpAfter(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.upAfter = 2
+	now := time.Now()
+
+	stats.handleConnError(now, time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, now.Add(time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateDown, stats.state())
+
+	stats.handleConnSuccess("", 1, now.Add(2*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateUp, stats.state())
+	assert.Equal(t, now.Add(time.Second), stats.startOfUptime)
+	assert.Equal(t, time.Second, stats.longestDowntime.duration)
+	assert.Equal(t, time.Second, stats.totalDowntime)
+	assert.Equal(t, 2*time.Second, stats.totalUptime)
+	assert.Equal(t, uint(2), stats.ongoingSuccessfulProbes)
+}
+
+func TestFlapDetection(t *testing.T) {
+	p := &stateChangePrinter{}
+	stats := createTestStats(t)
+	stats.printer = p
+	stats.userInput.flapThreshold = 4
+	stats.userInput.flapWindow = 10 * time.Second
+	now := time.Now()
+
+	for i := range 2 {
+		stats.handleConnError(now.Add(time.Duration(2*i)*time.Second), time.Second, errorKindTimeout, probeInfo{})
+		stats.handleConnSuccess("", 1, now.Add(time.Duration(2*i+1)*time.Second), time.Second, probeInfo{})
+	}
+	assert.Equal(t, stateFlapping, stats.state())
+	assert.Equal(t, []targetState{stateFlapping}, p.states)
+	assert.Equal(t, uint(1), stats.flaps)
+
+	// more state changes don't start another flap
+	stats.handleConnError(now.Add(4*time.Second), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, now.Add(5*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, uint(1), stats.flaps)
+
+	// the flapping stops once the state stayed the same for the whole window
+	stats.handleConnSuccess("", 1, now.Add(10*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateFlapping, stats.state())
+	stats.handleConnSuccess("", 1, now.Add(16*time.Second), time.Second, probeInfo{})
+	assert.Equal(t, stateUp, stats.state())
+	assert.Equal(t, []targetState{stateFlapping, stateUp}, p.states)
+	assert.Equal(t, uint(6), stats.stateChanges)
+}
+
+func TestFlapDetectionDisabled(t *testing.T) {
+	p := &stateChangePrinter{}
+	stats := createTestStats(t)
+	stats.printer = p
+	now := time.Now()
+
+	for i := range 10 {
+		stats.handleConnError(now.Add(time.Duration(2*i)*time.Second), time.Second, errorKindTimeout, probeInfo{})
+		stats.handleConnSuccess("", 1, now.Add(time.Duration(2*i+1)*time.Second), time.Second, probeInfo{})
+	}
+
+	assert.Equal(t, stateUp, stats.state())
+	assert.Empty(t, p.states)
+	assert.Empty(t, stats.recentStateChanges)
+	assert.Equal(t, uint(20), stats.stateChanges)
+}
@@ -0,0 +1,276 @@
+// happyeyeballs.go contains the logic of --happy-eyeballs, racing the connections
+// to the resolved addresses of a hostname as in RFC 8305
+package main
+
+import (
+	"cmp"
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultAttemptDelay is the default Connection Attempt Delay of RFC 8305, in seconds.
+	defaultAttemptDelay = 0.25
+	// minAttemptDelay is the lowest Connection Attempt Delay RFC 8305 allows, in seconds.
+	minAttemptDelay = 0.01
+	// maxAttemptDelay is the highest Connection Attempt Delay RFC 8305 recommends, in seconds.
+	maxAttemptDelay = 2
+)
+
+// happyEyeballs holds the options of --happy-eyeballs.
+type happyEyeballs struct {
+	// attemptDelay is the time to wait for a connection attempt
+	// before starting the next one in parallel.
+	attemptDelay time.Duration
+}
+
+// raceResult describes the winner of a connection race in --happy-eyeballs mode.
+type raceResult struct {
+	winner   netip.Addr
+	fellBack bool    // fellBack is true when the first address didn't win
+	fallback float32 // fallback is the time in ms from the start of the race to the start of the winning attempt
+	ipv6Lost bool    // ipv6Lost is true when an IPv6 address was raced, but an IPv4 address won
+}
+
+// family returns the IP version of the winner.
+func (r raceResult) family() ipFamily {
+	if r.winner.Is6() {
+		return familyIPv6
+	}
+	return familyIPv4
+}
+
+// raceStats keeps the results of the races of a target in --happy-eyeballs mode.
+type raceStats struct {
+	races       uint
+	ipv4Wins    uint
+	ipv6Wins    uint
+	ipv6Losses  uint // ipv6Losses counts the races won by IPv4 although an IPv6 address was raced
+	fallbacks   uint // fallbacks counts the races not won by the first address
+	fallbackSum float32
+	fallbackMax float32
+	winners     map[netip.Addr]uint // winners counts the races won by every address
+}
+
+// add records the result of a race.
+func (s *raceStats) add(r raceResult) {
+	s.races++
+	if r.family() == familyIPv6 {
+		s.ipv6Wins++
+	} else {
+		s.ipv4Wins++
+	}
+	if r.ipv6Lost {
+		s.ipv6Losses++
+	}
+
+	if r.fellBack {
+		s.fallbacks++
+		s.fallbackSum += r.fallback
+		s.fallbackMax = max(s.fallbackMax, r.fallback)
+	}
+
+	if s.winners == nil {
+		s.winners = map[netip.Addr]uint{}
+	}
+	s.winners[r.winner]++
+}
+
+// raceWinner is an address and the number of races it won.
+type raceWinner struct {
+	Addr netip.Addr `json:"addr"`
+	Wins uint       `json:"wins"`
+}
+
+// raceSummary is the result of the races of a target in --happy-eyeballs mode.
+type raceSummary struct {
+	Races      uint `json:"races"`
+	IPv4Wins   uint `json:"ipv4_wins"`
+	IPv6Wins   uint `json:"ipv6_wins"`
+	IPv6Losses uint `json:"ipv6_losses"`
+	Fallbacks  uint `json:"fallbacks"`
+	// FallbackAvg and FallbackMax are the time in ms until the winning attempt
+	// was started, of the races not won by the first address.
+	FallbackAvg float32 `json:"fallback_avg"`
+	FallbackMax float32 `json:"fallback_max"`
+	// Winners are the addresses that won a race, the most frequent first.
+	Winners []raceWinner `json:"winners"`
+	// IPv6Losing is true when IPv6 lost most of the races it took part in.
+	IPv6Losing bool `json:"ipv6_losing"`
+}
+
+// summary returns the result of the races.
+func (s *raceStats) summary() raceSummary {
+	summary := raceSummary{
+		Races:       s.races,
+		IPv4Wins:    s.ipv4Wins,
+		IPv6Wins:    s.ipv6Wins,
+		IPv6Losses:  s.ipv6Losses,
+		Fallbacks:   s.fallbacks,
+		FallbackMax: s.fallbackMax,
+		Winners:     []raceWinner{},
+		IPv6Losing:  s.ipv6Losses > s.ipv6Wins,
+	}
+
+	if s.fallbacks > 0 {
+		summary.FallbackAvg = s.fallbackSum / float32(s.fallbacks)
+	}
+
+	for addr, wins := range s.winners {
+		summary.Winners = append(summary.Winners, raceWinner{addr, wins})
+	}
+	slices.SortFunc(summary.Winners, func(a, b raceWinner) int {
+		if c := cmp.Compare(b.Wins, a.Wins); c != 0 {
+			return c
+		}
+		return a.Addr.Compare(b.Addr)
+	})
+
+	return summary
+}
+
+// sortRaceAddrs orders the addresses for a race as in section 4 of RFC 8305,
+// alternating between IPv6 and IPv4, starting with IPv6.
+// The order of the resolver is kept within an IP version.
+func sortRaceAddrs(addrs []netip.Addr) []netip.Addr {
+	var ipv6, ipv4 []netip.Addr
+	for _, addr := range addrs {
+		if addr.Is6() {
+			ipv6 = append(ipv6, addr)
+		} else {
+			ipv4 = append(ipv4, addr)
+		}
+	}
+
+	sorted := make([]netip.Addr, 0, len(addrs))
+	for i := range max(len(ipv6), len(ipv4)) {
+		if i < len(ipv6) {
+			sorted = append(sorted, ipv6[i])
+		}
+		if i < len(ipv4) {
+			sorted = append(sorted, ipv4[i])
+		}
+	}
+
+	return sorted
+}
+
+// resolveRaceAddrs resolves every address of the hostname to race in --happy-eyeballs mode.
+// An IP address given instead of a hostname is raced alone.
+func resolveRaceAddrs(tcping *tcping) ([]netip.Addr, error) {
+	if tcping.destIsIP {
+		return []netip.Addr{tcping.userInput.ip}, nil
+	}
+
+	addrs, err := lookupAddresses(tcping)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortRaceAddrs(addrs), nil
+}
+
+// raceAttempt is the outcome of the connection attempt to one address of a race.
+type raceAttempt struct {
+	conn    net.Conn
+	addr    netip.Addr
+	started time.Time
+	err     error
+}
+
+// raceConnect connects to the first address of addrs that accepts a connection,
+// following section 5 of RFC 8305.
+//
+// The attempts are started one after another, the next one when the previous
+// one failed or after attemptDelay, while the previous ones keep running.
+// The first connection wins and the other attempts are canceled.
+// The error of the first attempt is returned when every attempt failed.
+func raceConnect(dialer net.Dialer, addrs []netip.Addr, port uint16, attemptDelay time.Duration) (net.Conn, raceResult, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	raceStart := time.Now()
+	attempts := make(chan raceAttempt, len(addrs))
+	var started, pending int
+	var ipv6Raced bool
+
+	startAttempt := func() {
+		addr := addrs[started]
+		started++
+		pending++
+		ipv6Raced = ipv6Raced || addr.Is6()
+
+		go func() {
+			attemptStart := time.Now()
+			conn, err := dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(addr, port).String())
+			attempts <- raceAttempt{conn, addr, attemptStart, err}
+		}()
+	}
+
+	startAttempt()
+	timer := time.NewTimer(attemptDelay)
+	defer timer.Stop()
+
+	var firstErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if started < len(addrs) {
+				startAttempt()
+				timer.Reset(attemptDelay)
+			}
+
+		case a := <-attempts:
+			pending--
+			if a.err == nil {
+				// the connections of the attempts still running are not used
+				go func(pending int) {
+					for range pending {
+						if lost := <-attempts; lost.conn != nil {
+							lost.conn.Close()
+						}
+					}
+				}(pending)
+
+				r := raceResult{
+					winner:   a.addr,
+					fellBack: a.addr != addrs[0],
+					ipv6Lost: ipv6Raced && a.addr.Is4(),
+				}
+				if r.fellBack {
+					r.fallback = nanoToMillisecond(a.started.Sub(raceStart).Nanoseconds())
+				}
+				return a.conn, r, nil
+			}
+
+			if firstErr == nil {
+				firstErr = a.err
+			}
+			if started < len(addrs) {
+				startAttempt()
+				timer.Reset(attemptDelay)
+			}
+		}
+	}
+
+	return nil, raceResult{}, firstErr
+}
+
+// raceConnect races the addresses of the target in --happy-eyeballs mode.
+func (t *tcping) raceConnect() (net.Conn, *raceResult, error) {
+	dialer := net.Dialer{Timeout: t.userInput.timeout}
+	if t.userInput.networkInterface.use {
+		// dialer already contains the timeout value and the source address of the interface
+		dialer = t.userInput.networkInterface.dialer
+	}
+
+	conn, result, err := raceConnect(dialer, t.raceAddrs, t.userInput.port, t.userInput.happyEyeballs.attemptDelay)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, &result, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortRaceAddrs(t *testing.T) {
+	addrs := []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("192.0.2.3"),
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("2001:db8::2"),
+	}
+
+	assert.Equal(t, []netip.Addr{
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("2001:db8::2"),
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("192.0.2.3"),
+	}, sortRaceAddrs(addrs))
+
+	assert.Equal(t, addrs[:2], sortRaceAddrs(addrs[:2]))
+}
+
+func TestRaceStats(t *testing.T) {
+	ipv4 := netip.MustParseAddr("192.0.2.1")
+	ipv6 := netip.MustParseAddr("2001:db8::1")
+
+	var stats raceStats
+	stats.add(raceResult{winner: ipv6})
+	stats.add(raceResult{winner: ipv4, fellBack: true, fallback: 250, ipv6Lost: true})
+	stats.add(raceResult{winner: ipv4, fellBack: true, fallback: 300, ipv6Lost: true})
+
+	assert.Equal(t, raceSummary{
+		Races:       3,
+		IPv4Wins:    2,
+		IPv6Wins:    1,
+		IPv6Losses:  2,
+		Fallbacks:   2,
+		FallbackAvg: 275,
+		FallbackMax: 300,
+		Winners:     []raceWinner{{ipv4, 2}, {ipv6, 1}},
+		IPv6Losing:  true,
+	}, stats.summary())
+
+	stats.add(raceResult{winner: ipv6})
+	assert.False(t, stats.summary().IPv6Losing, "IPv6 won as many races as it lost")
+}
+
+func TestRaceConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+	listening := netip.MustParseAddr("127.0.0.1")
+	refusing := netip.MustParseAddr("127.0.0.2")
+	dialer := net.Dialer{Timeout: time.Second}
+
+	t.Run("first address wins", func(t *testing.T) {
+		conn, result, err := raceConnect(dialer, []netip.Addr{listening, refusing}, port, time.Second)
+		require.NoError(t, err)
+		conn.Close()
+
+		assert.Equal(t, listening, result.winner)
+		assert.Equal(t, familyIPv4, result.family())
+		assert.False(t, result.fellBack)
+		assert.Zero(t, result.fallback)
+	})
+
+	t.Run("a failed attempt starts the next one at once", func(t *testing.T) {
+		conn, result, err := raceConnect(dialer, []netip.Addr{refusing, listening}, port, time.Second)
+		require.NoError(t, err)
+		conn.Close()
+
+		assert.Equal(t, listening, result.winner)
+		assert.True(t, result.fellBack)
+		assert.Less(t, result.fallback, float32(1000), "the attempt delay must not be waited for")
+	})
+
+	t.Run("every attempt fails", func(t *testing.T) {
+		_, _, err := raceConnect(dialer, []netip.Addr{refusing}, port, time.Second)
+		assert.Equal(t, errorKindRefused, classifyDialError(err))
+	})
+}
@@ -0,0 +1,550 @@
+// html.go contains the self-contained HTML report of the probes
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// htmlChartWidth and htmlChartHeight are the size of the RTT chart of the HTML report, in SVG units.
+	htmlChartWidth  = 960
+	htmlChartHeight = 260
+	// htmlTimelineHeight is the height of the availability timeline of the HTML report, in SVG units.
+	htmlTimelineHeight = 40
+	// htmlChartLeft is the space left of the plots for the axis labels, in SVG units.
+	htmlChartLeft = 60
+	// htmlChartTicks is the number of ticks on each axis of the RTT chart.
+	htmlChartTicks = 5
+)
+
+// htmlPrinter records the probes of every target for the HTML report of --html,
+// and forwards every event to the printer it wraps.
+//
+// The report is written whenever the statistics are printed,
+// so that it's complete on exit.
+type htmlPrinter struct {
+	printer
+	mu         sync.Mutex
+	path       string
+	recordings []*htmlRecording
+}
+
+// htmlRecording holds the probes and the last statistics of a target.
+type htmlRecording struct {
+	target string
+	probes []reportProbe
+	stats  *tcping // stats is nil until the statistics of the target are printed
+}
+
+// newHTMLPrinter wraps p to write an HTML report to path.
+func newHTMLPrinter(p printer, path string) *htmlPrinter {
+	return &htmlPrinter{printer: p, path: path}
+}
+
+// recording returns the recording of the target of userInput.
+// The caller should hold p.mu.
+func (p *htmlPrinter) recording(userInput userInput) *htmlRecording {
+	target := userInput.target()
+	for _, r := range p.recordings {
+		if r.target == target {
+			return r
+		}
+	}
+
+	r := &htmlRecording{target: target}
+	p.recordings = append(p.recordings, r)
+	return r
+}
+
+// record adds a probe to the recording of its target.
+func (p *htmlPrinter) record(userInput userInput, probe reportProbe) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	probe.time = time.Now()
+	probe.hostname = userInput.hostname
+	probe.addr = userInput.ip.String()
+	probe.port = userInput.port
+
+	r := p.recording(userInput)
+	r.probes = append(r.probes, probe)
+}
+
+func (p *htmlPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
+	p.record(userInput, reportProbe{success: true, rtt: rtt})
+	p.printer.printProbeSuccess(sourceAddr, userInput, streak, rtt, info)
+}
+
+func (p *htmlPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
+	p.record(userInput, reportProbe{errorKind: kind})
+	p.printer.printProbeFail(userInput, streak, kind, info)
+}
+
+// printStatistics prints the statistics through the wrapped printer,
+// and writes the report with the targets whose statistics were printed.
+func (p *htmlPrinter) printStatistics(t tcping) {
+	p.printer.printStatistics(t)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.recording(t.userInput).stats = &t
+
+	var targets []htmlTarget
+	for _, r := range p.recordings {
+		if r.stats != nil {
+			outages := newSessionReport(reportSession{}, r.probes, nil).outages
+			targets = append(targets, newHTMLTarget(*r.stats, r.probes, outages))
+		}
+	}
+
+	if err := writeHTMLReport(p.path, targets); err != nil {
+		p.printError(msg("html.write-failed"), err)
+		return
+	}
+	p.printInfo(msg("html.written"), p.path)
+}
+
+// htmlRow is a row of the summary table of the HTML report.
+type htmlRow struct {
+	Label string
+	Value string
+}
+
+// htmlMark is a point of time on a chart, e.g. a hostname change.
+type htmlMark struct {
+	X     float64
+	Title string
+}
+
+// htmlSpan is a period of time on a chart, e.g. an outage.
+type htmlSpan struct {
+	X     float64
+	Width float64
+	Title string
+}
+
+// htmlTick is a labelled tick of an axis.
+type htmlTick struct {
+	Pos   float64
+	Label string
+}
+
+// htmlChart is the geometry of the RTT chart and the availability timeline of a target.
+type htmlChart struct {
+	Width          float64
+	Height         float64
+	TimelineHeight float64
+	Left           float64
+	Right          float64
+	Top            float64
+	Bottom         float64
+	PlotWidth      float64 // PlotWidth is Right - Left
+	PlotHeight     float64 // PlotHeight is Bottom - Top
+	FailureTop     float64 // FailureTop is the top of the failed probe marks
+	BarHeight      float64 // BarHeight is the height of the availability timeline bar
+	RTTPoints      string  // RTTPoints are the points of the RTT polyline
+	Failures       []htmlMark
+	Outages        []htmlSpan
+	Changes        []htmlMark
+	XTicks         []htmlTick
+	YTicks         []htmlTick
+	Timed          bool // Timed is false if the probes have no timestamps, so that they're spread evenly
+}
+
+// htmlOutage is an outage in the table of the HTML report.
+type htmlOutage struct {
+	Start    string
+	End      string
+	Duration string
+	Probes   uint
+	Ongoing  bool
+}
+
+// htmlHostnameChange is a hostname change in the table of the HTML report.
+type htmlHostnameChange struct {
+	From string
+	To   string
+	When string
+}
+
+// htmlTarget is the section of a target in the HTML report.
+type htmlTarget struct {
+	Title           string
+	Summary         []htmlRow
+	Chart           htmlChart
+	Outages         []htmlOutage
+	HostnameChanges []htmlHostnameChange
+}
+
+// newHTMLTarget creates the section of a target from its statistics, its probes and their outages.
+func newHTMLTarget(stats tcping, probes []reportProbe, outages []outage) htmlTarget {
+	t := htmlTarget{
+		Title:   stats.userInput.target(),
+		Summary: htmlStatistics(stats),
+		Chart:   newHTMLChart(probes, outages, stats.hostnameChanges),
+	}
+
+	for _, o := range outages {
+		t.Outages = append(t.Outages, htmlOutage{
+			Start:    o.Start.Format(timeFormat),
+			End:      o.End.Format(timeFormat),
+			Duration: durationToString(time.Duration(o.Duration * float64(time.Second))),
+			Probes:   o.Probes,
+			Ongoing:  o.Ongoing,
+		})
+	}
+
+	for i := 1; i < len(stats.hostnameChanges); i++ {
+		change := htmlHostnameChange{
+			From: stats.hostnameChanges[i-1].Addr.String(),
+			To:   stats.hostnameChanges[i].Addr.String(),
+		}
+		if when := stats.hostnameChanges[i].When; !when.IsZero() {
+			change.When = when.Format(timeFormat)
+		}
+		t.HostnameChanges = append(t.HostnameChanges, change)
+	}
+
+	return t
+}
+
+// htmlStatistics returns the rows of the summary table, as printStatistics shows them.
+func htmlStatistics(t tcping) []htmlRow {
+	totalPackets := t.totalSuccessfulProbes + t.totalUnsuccessfulProbes
+	packetLoss := float64(t.totalUnsuccessfulProbes) / float64(totalPackets) * 100
+	if math.IsNaN(packetLoss) {
+		packetLoss = 0
+	}
+
+	rows := []htmlRow{
+		{msg("stats.probes"), fmt.Sprint(totalPackets)},
+		{msg("stats.successful"), fmt.Sprint(t.totalSuccessfulProbes)},
+		{msg("stats.unsuccessful"), fmt.Sprint(t.totalUnsuccessfulProbes)},
+		{msg("stats.loss-rate"), fmt.Sprintf("%.2f%%", packetLoss)},
+	}
+
+	for _, kind := range errorKinds {
+		if count := t.failuresByKind[kind]; count > 0 {
+			rows = append(rows, htmlRow{"  " + kind.description(), fmt.Sprint(count)})
+		}
+	}
+
+	lastSuccess, lastFailure := msg("stats.never-succeeded"), msg("stats.never-failed")
+	if !t.lastSuccessfulProbe.IsZero() {
+		lastSuccess = t.lastSuccessfulProbe.Format(timeFormat)
+	}
+	if !t.lastUnsuccessfulProbe.IsZero() {
+		lastFailure = t.lastUnsuccessfulProbe.Format(timeFormat)
+	}
+
+	rows = append(rows,
+		htmlRow{msg("stats.last-success"), lastSuccess},
+		htmlRow{msg("stats.last-failure"), lastFailure},
+		htmlRow{msg("stats.total-uptime"), durationToString(t.totalUptime)},
+		htmlRow{msg("stats.total-downtime"), durationToString(t.totalDowntime)},
+		htmlRow{msg("stats.state"), t.state().description()},
+		htmlRow{msg("stats.state-changes"), msgf("stats.times", t.stateChanges) + " | " + msg("stats.flaps") + ": " + msgf("stats.times", t.flaps)},
+	)
+
+	if t.longestUptime.duration != 0 {
+		rows = append(rows, htmlRow{msg("stats.longest-uptime"), durationToString(t.longestUptime.duration) + " " +
+			msgf("report.from-to", t.longestUptime.start.Format(timeFormat), t.longestUptime.end.Format(timeFormat))})
+	}
+	if t.longestDowntime.duration != 0 {
+		rows = append(rows, htmlRow{msg("stats.longest-downtime"), durationToString(t.longestDowntime.duration) + " " +
+			msgf("report.from-to", t.longestDowntime.start.Format(timeFormat), t.longestDowntime.end.Format(timeFormat))})
+	}
+	if !t.destIsIP {
+		rows = append(rows, htmlRow{msg("stats.retried-lookups"), msgf("stats.times", t.retriedHostnameLookups)})
+		if t.userInput.resolver != nil {
+			rows = append(rows, htmlRow{msg("stats.resolver"), t.userInput.resolver.String()})
+		}
+		rows = append(rows, htmlRow{"DNS", msgf("stats.dns-lookups", t.dns.lookups, t.dns.failures)})
+		if result := t.dns.latency.result(); result.hasResults {
+			rows = append(rows, htmlRow{msg("stats.dns-latency"), fmt.Sprintf("%.1f/%.1f/%.1f ms", result.min, result.average, result.max)})
+		}
+	}
+	if t.userInput.srvName != "" {
+		rows = append(rows, htmlRow{msg("stats.srv-record"), msgf("stats.srv-record-value", t.userInput.srvName, t.srvRecord.Priority, t.srvRecord.Weight)})
+		for _, change := range t.srvChanges {
+			rows = append(rows, htmlRow{msg("stats.srv-changes"), srvChangeMessage(change) + " " + msg("stats.at") + " " + change.When.Format(timeFormat)})
+		}
+	}
+
+	if t.rttResults.hasResults {
+		rows = append(rows,
+			htmlRow{"rtt " + msg("stats.min") + "/" + msg("stats.avg") + "/" + msg("stats.max"), fmt.Sprintf("%.1f/%.1f/%.1f ms", t.rttResults.min, t.rttResults.average, t.rttResults.max)},
+			htmlRow{msg("stats.rtt-percentiles"), fmt.Sprintf("%.1f/%.1f/%.1f/%.1f ms", t.rttResults.median, t.rttResults.p90, t.rttResults.p95, t.rttResults.p99)},
+			htmlRow{msg("stats.rtt-stddev"), fmt.Sprintf("%.1f ms", t.rttResults.stdDev)},
+			htmlRow{msg("stats.jitter"), fmt.Sprintf("%.1f ms", t.rttResults.jitter)},
+		)
+	}
+
+	for p := range phaseCount {
+		if result := t.phases[p].result(); result.hasResults {
+			rows = append(rows, htmlRow{msgf("stats.phase", p.description()), fmt.Sprintf("%.1f/%.1f/%.1f ms", result.min, result.average, result.max)})
+		}
+	}
+
+	if t.lastTLS != nil {
+		rows = append(rows,
+			htmlRow{"TLS", t.lastTLS.version + " " + t.lastTLS.cipher},
+			htmlRow{msg("stats.certificate"), t.lastTLS.subject},
+			htmlRow{msg("stats.cert-expiry"), msgf("stats.days-left", t.lastTLS.notAfter.Format(timeFormat), t.lastTLS.daysLeft())},
+		)
+	}
+
+	if !t.startTime.IsZero() {
+		rows = append(rows, htmlRow{msg("stats.started"), t.startTime.Format(timeFormat)})
+	}
+	if !t.endTime.IsZero() {
+		rows = append(rows, htmlRow{msg("stats.ended"), t.endTime.Format(timeFormat)})
+	}
+
+	durationTime := time.Time{}.Add(t.totalDowntime + t.totalUptime)
+	rows = append(rows, htmlRow{msg("stats.duration"), durationTime.Format(hourFormat)})
+
+	return rows
+}
+
+// newHTMLChart lays out the RTT chart and the availability timeline.
+//
+// The probes are placed by their time, or spread evenly if they have no timestamps.
+func newHTMLChart(probes []reportProbe, outages []outage, changes []hostnameChange) htmlChart {
+	c := htmlChart{
+		Width:          htmlChartWidth,
+		Height:         htmlChartHeight,
+		TimelineHeight: htmlTimelineHeight,
+		Left:           htmlChartLeft,
+		Right:          htmlChartWidth - 10,
+		Top:            10,
+		Bottom:         htmlChartHeight - 30,
+		FailureTop:     htmlChartHeight - 38,
+		BarHeight:      htmlTimelineHeight - 8,
+		Timed:          len(probes) > 0 && !probes[0].time.IsZero(),
+	}
+	c.PlotWidth = c.Right - c.Left
+	c.PlotHeight = c.Bottom - c.Top
+	if len(probes) == 0 {
+		return c
+	}
+
+	start, end := probes[0].time, probes[len(probes)-1].time
+	x := func(i int, t time.Time) float64 {
+		switch {
+		case c.Timed && end.After(start):
+			return c.Left + float64(t.Sub(start))/float64(end.Sub(start))*(c.Right-c.Left)
+		case !c.Timed && len(probes) > 1:
+			return c.Left + float64(i)/float64(len(probes)-1)*(c.Right-c.Left)
+		default:
+			return (c.Left + c.Right) / 2
+		}
+	}
+
+	var maxRTT float32
+	for _, p := range probes {
+		if p.success {
+			maxRTT = max(maxRTT, p.rtt)
+		}
+	}
+	yMax := niceCeil(float64(maxRTT) * 1.1)
+	y := func(rtt float32) float64 {
+		return c.Bottom - float64(rtt)/yMax*(c.Bottom-c.Top)
+	}
+
+	var points strings.Builder
+	for i, p := range probes {
+		if p.success {
+			fmt.Fprintf(&points, "%.1f,%.1f ", x(i, p.time), y(p.rtt))
+			continue
+		}
+
+		title := p.errorKind.description()
+		if c.Timed {
+			title = p.time.Format(timeFormat) + " " + title
+		}
+		c.Failures = append(c.Failures, htmlMark{X: x(i, p.time), Title: title})
+	}
+	c.RTTPoints = strings.TrimSpace(points.String())
+
+	for i := range htmlChartTicks {
+		frac := float64(i) / float64(htmlChartTicks-1)
+		c.YTicks = append(c.YTicks, htmlTick{
+			Pos:   c.Bottom - frac*(c.Bottom-c.Top),
+			Label: formatFloat(math.Round(yMax*frac*100) / 100),
+		})
+
+		tick := htmlTick{Pos: c.Left + frac*(c.Right-c.Left)}
+		if c.Timed {
+			tick.Label = start.Add(time.Duration(frac * float64(end.Sub(start)))).Format(htmlTimeFormat(end.Sub(start)))
+		} else {
+			tick.Label = fmt.Sprintf("#%d", int(math.Round(frac*float64(len(probes)-1)))+1)
+		}
+		c.XTicks = append(c.XTicks, tick)
+	}
+
+	if !c.Timed {
+		return c
+	}
+
+	for _, o := range outages {
+		from, to := x(0, o.Start), x(0, o.End)
+		c.Outages = append(c.Outages, htmlSpan{
+			X:     from,
+			Width: max(to-from, 2),
+			Title: fmt.Sprintf("%s - %s (%s)", o.Start.Format(timeFormat), o.End.Format(timeFormat), durationToString(time.Duration(o.Duration*float64(time.Second)))),
+		})
+	}
+
+	for i := 1; i < len(changes); i++ {
+		when := changes[i].When
+		if when.Before(start) || when.After(end) {
+			continue
+		}
+		c.Changes = append(c.Changes, htmlMark{
+			X:     x(0, when),
+			Title: fmt.Sprintf("%s %s -> %s", when.Format(timeFormat), changes[i-1].Addr, changes[i].Addr),
+		})
+	}
+
+	return c
+}
+
+// htmlTimeFormat returns the format of the time axis labels for a chart spanning span.
+func htmlTimeFormat(span time.Duration) string {
+	if span > 24*time.Hour {
+		return "01-02 15:04"
+	}
+	return hourFormat
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of 10, so that the axis ticks are readable.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// writeHTMLReport writes a self-contained HTML report of targets to path.
+// The styles and the SVG charts are embedded, so that nothing is fetched when it's opened.
+func writeHTMLReport(path string, targets []htmlTarget) error {
+	var buf bytes.Buffer
+	err := htmlReportTemplate.Execute(&buf, struct {
+		Lang      language
+		Version   string
+		Generated string
+		Targets   []htmlTarget
+	}{
+		Lang:      currentLanguage,
+		Version:   version,
+		Generated: time.Now().Format(timeFormat),
+		Targets:   targets,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"msg": msg, "msgf": msgf}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{msg "html.title"}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Noto Sans", "PingFang SC", sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.3em; border-bottom: 1px solid #ccc; padding-bottom: .2em; margin-top: 2em; }
+h3 { font-size: 1.05em; }
+table { border-collapse: collapse; margin: .5em 0; }
+th, td { text-align: left; padding: .25em .8em; border-bottom: 1px solid #eee; }
+td.number { text-align: right; }
+svg { display: block; width: 100%; height: auto; }
+svg text { font-size: 11px; fill: #555; }
+.meta { color: #777; }
+.rtt { fill: none; stroke: #1f77b4; stroke-width: 1.5; }
+.failure { stroke: #d62728; stroke-width: 1.5; }
+.outage { fill: #d62728; fill-opacity: .15; }
+.timeline-up { fill: #2ca02c; }
+.timeline-down { fill: #d62728; }
+.change { stroke: #9467bd; stroke-width: 1.5; stroke-dasharray: 4 3; }
+.grid { stroke: #eee; }
+.ongoing { color: #d62728; }
+.legend span { margin-right: 1.5em; }
+.legend .swatch { display: inline-block; width: 1em; height: .7em; margin-right: .3em; }
+</style>
+</head>
+<body>
+<h1>{{msg "html.title"}}</h1>
+<p class="meta">{{msgf "html.generated" .Version .Generated}}</p>
+{{range .Targets}}
+<h2>{{.Title}}</h2>
+{{template "chart" .Chart}}
+<h3>{{msg "html.statistics"}}</h3>
+<table>
+{{range .Summary}}<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h3>{{msg "report.outages"}}</h3>
+{{if .Outages}}
+<table>
+<tr><th>{{msg "html.start"}}</th><th>{{msg "html.end"}}</th><th>{{msg "html.duration"}}</th><th>{{msg "stats.unsuccessful"}}</th></tr>
+{{range .Outages}}<tr><td>{{.Start}}</td><td>{{.End}}</td><td>{{.Duration}}{{if .Ongoing}} <span class="ongoing">{{msg "report.ongoing"}}</span>{{end}}</td><td class="number">{{.Probes}}</td></tr>
+{{end}}</table>
+{{else if .Chart.Timed}}
+<p>{{msg "report.none"}}</p>
+{{else}}
+<p>{{msg "html.untimed"}}</p>
+{{end}}
+{{if .HostnameChanges}}
+<h3>{{msg "stats.hostname-changes"}}</h3>
+<table>
+<tr><th>{{msg "html.from"}}</th><th>{{msg "html.to"}}</th><th>{{msg "html.time"}}</th></tr>
+{{range .HostnameChanges}}<tr><td>{{.From}}</td><td>{{.To}}</td><td>{{.When}}</td></tr>
+{{end}}</table>
+{{end}}
+{{end}}
+</body>
+</html>
+{{define "chart"}}
+<h3>RTT (ms)</h3>
+<svg viewBox="0 0 {{.Width}} {{.Height}}" role="img">
+{{range .YTicks}}<line class="grid" x1="{{$.Left}}" x2="{{$.Right}}" y1="{{printf "%.1f" .Pos}}" y2="{{printf "%.1f" .Pos}}"/>
+<text x="{{$.Left}}" y="{{printf "%.1f" .Pos}}" dx="-6" dy="4" text-anchor="end">{{.Label}}</text>
+{{end}}{{range .XTicks}}<text x="{{printf "%.1f" .Pos}}" y="{{$.Height}}" dy="-10" text-anchor="middle">{{.Label}}</text>
+{{end}}{{range .Outages}}<rect class="outage" x="{{printf "%.1f" .X}}" y="{{$.Top}}" width="{{printf "%.1f" .Width}}" height="{{$.PlotHeight}}"><title>{{.Title}}</title></rect>
+{{end}}{{range .Changes}}<line class="change" x1="{{printf "%.1f" .X}}" x2="{{printf "%.1f" .X}}" y1="{{$.Top}}" y2="{{$.Bottom}}"><title>{{.Title}}</title></line>
+{{end}}{{if .RTTPoints}}<polyline class="rtt" points="{{.RTTPoints}}"/>
+{{end}}{{range .Failures}}<line class="failure" x1="{{printf "%.1f" .X}}" x2="{{printf "%.1f" .X}}" y1="{{$.Bottom}}" y2="{{$.FailureTop}}"><title>{{.Title}}</title></line>
+{{end}}</svg>
+{{if .Timed}}
+<h3>{{msg "html.timeline"}}</h3>
+<svg viewBox="0 0 {{.Width}} {{.TimelineHeight}}" role="img">
+<rect class="timeline-up" x="{{.Left}}" y="4" width="{{.PlotWidth}}" height="{{.BarHeight}}"/>
+{{range .Outages}}<rect class="timeline-down" x="{{printf "%.1f" .X}}" y="4" width="{{printf "%.1f" .Width}}" height="{{$.BarHeight}}"><title>{{.Title}}</title></rect>
+{{end}}{{range .Changes}}<line class="change" x1="{{printf "%.1f" .X}}" x2="{{printf "%.1f" .X}}" y1="0" y2="{{$.TimelineHeight}}"><title>{{.Title}}</title></line>
+{{end}}</svg>
+{{end}}
+<p class="legend">
+<span><span class="swatch" style="background:#1f77b4"></span>RTT</span>
+<span><span class="swatch" style="background:#d62728"></span>{{msg "html.legend-failures"}}</span>
+<span><span class="swatch" style="background:#9467bd"></span>{{msg "stats.hostname-changes"}}</span>
+</p>
+{{end}}`))
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNiceCeil(t *testing.T) {
+	assert.Equal(t, 1.0, niceCeil(0))
+	assert.Equal(t, 1.0, niceCeil(0.9))
+	assert.Equal(t, 2.0, niceCeil(1.1))
+	assert.Equal(t, 50.0, niceCeil(33))
+	assert.Equal(t, 100.0, niceCeil(100))
+	assert.Equal(t, 1000.0, niceCeil(501))
+}
+
+func TestNewHTMLChart(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	probes := newTestProbes(start, true, false, false, true, true)
+	probes[4].rtt = 40
+	r := newSessionReport(reportSession{}, probes, nil)
+
+	changes := []hostnameChange{
+		{netip.MustParseAddr("192.0.2.1"), start},
+		{netip.MustParseAddr("192.0.2.2"), start.Add(3 * time.Second)},
+	}
+	c := newHTMLChart(probes, r.outages, changes)
+
+	assert.True(t, c.Timed)
+	assert.Equal(t, "60.0,186.0 727.5,186.0 950.0,54.0", c.RTTPoints)
+
+	require.Len(t, c.Failures, 2)
+	assert.InDelta(t, 282.5, c.Failures[0].X, 0.01)
+	assert.Contains(t, c.Failures[0].Title, "timeout")
+
+	require.Len(t, c.Outages, 1)
+	assert.InDelta(t, 282.5, c.Outages[0].X, 0.01)
+	assert.InDelta(t, 445, c.Outages[0].Width, 0.01)
+
+	require.Len(t, c.Changes, 1)
+	assert.InDelta(t, 727.5, c.Changes[0].X, 0.01)
+	assert.Contains(t, c.Changes[0].Title, "192.0.2.1 -> 192.0.2.2")
+
+	require.Len(t, c.YTicks, htmlChartTicks)
+	assert.Equal(t, "0", c.YTicks[0].Label)
+	assert.Equal(t, "50", c.YTicks[htmlChartTicks-1].Label)
+	assert.Equal(t, "10:00:00", c.XTicks[0].Label)
+	assert.Equal(t, "10:00:04", c.XTicks[htmlChartTicks-1].Label)
+}
+
+func TestNewHTMLChartWithoutTimestamps(t *testing.T) {
+	probes := newTestProbes(time.Time{}, true, false, true)
+	for i := range probes {
+		probes[i].time = time.Time{}
+	}
+
+	c := newHTMLChart(probes, nil, nil)
+
+	assert.False(t, c.Timed)
+	assert.Equal(t, "60.0,120.0 950.0,120.0", c.RTTPoints)
+	assert.InDelta(t, 505, c.Failures[0].X, 0.01)
+	assert.Equal(t, "#1", c.XTicks[0].Label)
+	assert.Equal(t, "#3", c.XTicks[htmlChartTicks-1].Label)
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	probes := newTestProbes(start, true, false, true)
+	r := newSessionReport(reportSession{}, probes, nil)
+	r.stats.userInput.hostname = "<example>"
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	require.NoError(t, writeHTMLReport(path, []htmlTarget{newHTMLTarget(r.stats, probes, r.outages)}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "&lt;example&gt;:443")
+	assert.Contains(t, html, `<polyline class="rtt"`)
+	assert.Contains(t, html, `<rect class="timeline-down"`)
+	assert.Contains(t, html, "2026-01-01 10:00:01")
+	assert.Contains(t, html, "<th>Successful probes</th>")
+	assert.Contains(t, html, `<html lang="en">`)
+
+	// every asset should be embedded
+	external := regexp.MustCompile(`(?i)(src|href)=|url\(|https?://`)
+	assert.False(t, external.MatchString(html), "the report should not reference external resources")
+}
+
+func TestHTMLPrinter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	p := newHTMLPrinter(&dummyPrinter{}, path)
+
+	stats := createTestStats(t)
+	stats.printer = p
+	stats.handleConnSuccess("", 5, time.Now(), time.Second, probeInfo{})
+	stats.handleConnError(time.Now(), time.Second, errorKindRefused, probeInfo{})
+
+	require.Len(t, p.recordings, 1)
+	require.Len(t, p.recordings[0].probes, 2)
+	assert.True(t, p.recordings[0].probes[0].success)
+	assert.Equal(t, errorKindRefused, p.recordings[0].probes[1].errorKind)
+
+	stats.printStats()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(content), stats.userInput.target()))
+	assert.Same(t, p.printer, unwrapPrinter(newSyncPrinter(p)))
+}
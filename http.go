@@ -0,0 +1,303 @@
+// http.go contains the logic of --http, sending an HTTP request over the connection of each probe
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultExpectedStatus is the default range of successful HTTP status codes.
+	defaultExpectedStatus = "200-399"
+	// maxHTTPBodySize is the number of body bytes read and matched against --expect-body.
+	maxHTTPBodySize = 1 << 20
+)
+
+// httpInfo describes the HTTP response of a probe.
+type httpInfo struct {
+	status int
+	ttfb   float32 // ttfb is the time from sending the request to the first byte of the response in milliseconds
+	total  float32 // total is the time from sending the request to the end of the response body in milliseconds
+}
+
+// statusRange is an inclusive range of HTTP status codes.
+type statusRange struct {
+	from int
+	to   int
+}
+
+// headerExpectation is a header that must match a pattern.
+type headerExpectation struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// httpProbe holds the request to send and the assertions
+// on the response of the --http probe mode.
+type httpProbe struct {
+	headers       http.Header
+	expectBody    *regexp.Regexp
+	method        string
+	path          string
+	host          string // host overrides the Host header, which defaults to the target
+	expectStatus  []statusRange
+	expectHeaders []headerExpectation
+}
+
+// httpOptions holds the flags of the --http probe mode.
+type httpOptions struct {
+	method        string
+	path          string
+	host          string
+	expectStatus  string
+	expectBody    string
+	headers       []string
+	expectHeaders []string
+}
+
+// httpAssertionError is returned when a response does not match the assertions.
+type httpAssertionError struct {
+	kind    errorKind
+	message string
+}
+
+func (e *httpAssertionError) Error() string {
+	return e.message
+}
+
+// stringsFlag is a flag that can be given multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// newHTTPProbe validates the flags of the --http probe mode.
+func newHTTPProbe(opts httpOptions) (*httpProbe, error) {
+	p := &httpProbe{
+		method:  strings.ToUpper(opts.method),
+		path:    opts.path,
+		host:    opts.host,
+		headers: http.Header{},
+	}
+
+	if p.path == "" {
+		p.path = "/"
+	}
+	if _, err := url.ParseRequestURI(p.path); err != nil {
+		return nil, fmt.Errorf(msg("http.invalid-path"), p.path)
+	}
+
+	for _, header := range opts.headers {
+		name, value, err := splitHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		p.headers.Add(name, value)
+	}
+
+	var err error
+	p.expectStatus, err = parseStatusRanges(opts.expectStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range opts.expectHeaders {
+		name, pattern, err := splitHeader(header)
+		if err != nil {
+			return nil, err
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(msg("error.invalid-regexp"), pattern, err)
+		}
+		p.expectHeaders = append(p.expectHeaders, headerExpectation{name: name, pattern: re})
+	}
+
+	if opts.expectBody != "" {
+		p.expectBody, err = regexp.Compile(opts.expectBody)
+		if err != nil {
+			return nil, fmt.Errorf(msg("error.invalid-regexp"), opts.expectBody, err)
+		}
+	}
+
+	return p, nil
+}
+
+// splitHeader splits a "Name: value" header.
+func splitHeader(header string) (string, string, error) {
+	name, value, found := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", fmt.Errorf(msg("http.invalid-header"), header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// parseStatusRanges parses status codes and ranges, e.g. "200,301-308".
+func parseStatusRanges(s string) ([]statusRange, error) {
+	var ranges []statusRange
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		fromStr, toStr, isRange := strings.Cut(field, "-")
+		if !isRange {
+			toStr = fromStr
+		}
+
+		from, errFrom := strconv.Atoi(strings.TrimSpace(fromStr))
+		to, errTo := strconv.Atoi(strings.TrimSpace(toStr))
+		if errFrom != nil || errTo != nil || from < 100 || to > 599 || from > to {
+			return nil, fmt.Errorf(msg("http.invalid-status"), field)
+		}
+
+		ranges = append(ranges, statusRange{from: from, to: to})
+	}
+
+	if len(ranges) == 0 {
+		return nil, msgErr("http.no-status")
+	}
+
+	return ranges, nil
+}
+
+// statusExpected reports whether status is one of the expected status codes.
+func (p *httpProbe) statusExpected(status int) bool {
+	for _, r := range p.expectStatus {
+		if status >= r.from && status <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// newRequest creates the request for the target of u.
+//
+// The URL and the Host header are made of the hostname and port of the target,
+// not of its label, which also names the address, the IP version or the SRV name.
+func (p *httpProbe) newRequest(u userInput) (*http.Request, error) {
+	host := p.host
+	if host == "" {
+		host = u.hostPort()
+	}
+
+	req, err := http.NewRequest(p.method, "http://"+host+p.path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = p.headers.Clone()
+	req.Header.Set("User-Agent", "tcping/"+version)
+	req.Close = true
+
+	return req, nil
+}
+
+// firstByteReader records when the first byte has been read.
+type firstByteReader struct {
+	r         io.Reader
+	firstByte time.Time
+}
+
+func (f *firstByteReader) Read(b []byte) (int, error) {
+	n, err := f.r.Read(b)
+	if n > 0 && f.firstByte.IsZero() {
+		f.firstByte = time.Now()
+	}
+	return n, err
+}
+
+// do sends the request over conn and checks the response against the assertions.
+//
+// The returned httpInfo is set whenever a response has been received,
+// even if it did not match the assertions.
+func (p *httpProbe) do(conn net.Conn, u userInput) (*httpInfo, error) {
+	if u.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(u.timeout))
+	}
+
+	req, err := p.newRequest(u)
+	if err != nil {
+		return nil, err
+	}
+
+	requestStart := time.Now()
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := &firstByteReader{r: conn}
+	resp, err := http.ReadResponse(bufio.NewReader(reader), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &httpInfo{
+		status: resp.StatusCode,
+		ttfb:   nanoToMillisecond(reader.firstByte.Sub(requestStart).Nanoseconds()),
+		total:  nanoToMillisecond(time.Since(requestStart).Nanoseconds()),
+	}
+
+	if !p.statusExpected(resp.StatusCode) {
+		return info, &httpAssertionError{
+			kind:    errorKindHTTPStatus,
+			message: msgf("http.unexpected-status", resp.StatusCode),
+		}
+	}
+
+	for _, expected := range p.expectHeaders {
+		if !expected.pattern.MatchString(resp.Header.Get(expected.name)) {
+			return info, &httpAssertionError{
+				kind:    errorKindHTTPHeader,
+				message: msgf("http.header-mismatch", expected.name, expected.pattern),
+			}
+		}
+	}
+
+	if p.expectBody != nil && !p.expectBody.Match(body) {
+		return info, &httpAssertionError{
+			kind:    errorKindHTTPBody,
+			message: msgf("http.body-mismatch", p.expectBody),
+		}
+	}
+
+	return info, nil
+}
+
+// classifyHTTPError maps an error of the HTTP exchange to an errorKind.
+func classifyHTTPError(err error) errorKind {
+	var assertionErr *httpAssertionError
+	if errors.As(err, &assertionErr) {
+		return assertionErr.kind
+	}
+
+	if kind := classifyDialError(err); kind != errorKindOther {
+		return kind
+	}
+
+	return errorKindHTTPError
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPTestStats creates stats that probe srv in --http mode.
+func newHTTPTestStats(t *testing.T, srv *httptest.Server, opts httpOptions) *tcping {
+	addrPort := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	if opts.expectStatus == "" {
+		opts.expectStatus = defaultExpectedStatus
+	}
+	probe, err := newHTTPProbe(opts)
+	require.NoError(t, err)
+
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.ip = addrPort.Addr()
+	stats.userInput.port = addrPort.Port()
+	stats.userInput.http = probe
+
+	return stats
+}
+
+func TestHTTPProbeSuccess(t *testing.T) {
+	var gotHost, gotHeader, gotMethod string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotHeader, gotMethod = r.Host, r.Header.Get("X-Test"), r.Method
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	stats := newHTTPTestStats(t, srv, httpOptions{
+		method:        "head",
+		path:          "/health",
+		host:          "example.com",
+		headers:       []string{"X-Test: 1"},
+		expectHeaders: []string{"Content-Type: ^application/json"},
+	})
+	tcpProbe(stats)
+
+	assert.Equal(t, uint(1), stats.totalSuccessfulProbes)
+	assert.Equal(t, "example.com", gotHost)
+	assert.Equal(t, "1", gotHeader)
+	assert.Equal(t, http.MethodHead, gotMethod)
+}
+
+func TestHTTPProbeAssertions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("status: degraded"))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name string
+		opts httpOptions
+		kind errorKind
+	}{
+		{"status", httpOptions{path: "/missing"}, errorKindHTTPStatus},
+		{"header", httpOptions{expectHeaders: []string{"X-Missing: .+"}}, errorKindHTTPHeader},
+		{"body", httpOptions{expectBody: "status: ok"}, errorKindHTTPBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := newHTTPTestStats(t, srv, tt.opts)
+			tcpProbe(stats)
+
+			assert.Equal(t, uint(1), stats.totalUnsuccessfulProbes)
+			assert.Equal(t, uint(1), stats.failuresByKind[tt.kind])
+			assert.Equal(t, "http", tt.kind.layer())
+		})
+	}
+}
+
+func TestHTTPProbeInvalidResponse(t *testing.T) {
+	// testServerListen closes every connection right away
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	probe, err := newHTTPProbe(httpOptions{expectStatus: defaultExpectedStatus})
+	require.NoError(t, err)
+
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.http = probe
+	tcpProbe(stats)
+
+	assert.Equal(t, uint(1), stats.totalUnsuccessfulProbes)
+	assert.Zero(t, stats.failuresByKind[errorKindRefused])
+}
+
+func TestHTTPRequestLabeledTargets(t *testing.T) {
+	p, err := newHTTPProbe(httpOptions{expectStatus: defaultExpectedStatus, path: "/health"})
+	require.NoError(t, err)
+
+	base := userInput{hostname: "example.com", ip: netip.MustParseAddr("192.0.2.1"), port: 80}
+	allAddresses, dualStack, srv := base, base, base
+	allAddresses.allAddresses = true
+	dualStack.family = familyIPv6
+	srv.srvName = "_http._tcp.example.com"
+
+	for _, u := range []userInput{allAddresses, dualStack, srv} {
+		req, err := p.newRequest(u)
+		require.NoError(t, err, u.target())
+		assert.Equal(t, "http://example.com:80/health", req.URL.String(), u.target())
+		assert.Equal(t, "example.com:80", req.Host, u.target())
+	}
+}
+
+func TestParseStatusRanges(t *testing.T) {
+	ranges, err := parseStatusRanges("200, 301-308")
+	require.NoError(t, err)
+	assert.Equal(t, []statusRange{{200, 200}, {301, 308}}, ranges)
+
+	for _, s := range []string{"", "abc", "99", "600", "308-301", "200-"} {
+		_, err := parseStatusRanges(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestStatusExpected(t *testing.T) {
+	p, err := newHTTPProbe(httpOptions{expectStatus: "200,301-308"})
+	require.NoError(t, err)
+
+	assert.True(t, p.statusExpected(200))
+	assert.True(t, p.statusExpected(302))
+	assert.False(t, p.statusExpected(201))
+	assert.False(t, p.statusExpected(404))
+}
+
+func TestSplitHeader(t *testing.T) {
+	name, value, err := splitHeader("Authorization: Bearer a:b")
+	require.NoError(t, err)
+	assert.Equal(t, "Authorization", name)
+	assert.Equal(t, "Bearer a:b", value)
+
+	_, _, err = splitHeader("no colon")
+	assert.Error(t, err)
+	_, _, err = splitHeader(": value")
+	assert.Error(t, err)
+}
+
+func TestNewHTTPProbeInvalid(t *testing.T) {
+	for _, opts := range []httpOptions{
+		{expectStatus: "abc"},
+		{expectStatus: "200", path: "relative"},
+		{expectStatus: "200", expectBody: "("},
+		{expectStatus: "200", expectHeaders: []string{"X: ("}},
+		{expectStatus: "200", headers: []string{"invalid"}},
+	} {
+		_, err := newHTTPProbe(opts)
+		assert.Error(t, err, opts)
+	}
+}
@@ -0,0 +1,148 @@
+// i18n.go contains the logic for choosing the language of the messages
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// language is a language the messages of tcping can be shown in.
+type language string
+
+const (
+	languageEnglish language = "en"
+	languageChinese language = "zh"
+)
+
+// catalog maps the key of every message to its format string.
+type catalog map[string]string
+
+// catalogs contains the messages of every supported language.
+var catalogs = map[language]catalog{
+	languageEnglish: englishMessages,
+	languageChinese: chineseMessages,
+}
+
+// currentLanguage is the language of the messages.
+// It stays English until main picks one from --lang or the environment.
+var currentLanguage = languageEnglish
+
+// msg returns the format string of a message in the current language.
+// It falls back to English and then to the key itself,
+// so a missing translation never hides a message.
+func msg(key string) string {
+	if format, ok := catalogs[currentLanguage][key]; ok {
+		return format
+	}
+	if format, ok := englishMessages[key]; ok {
+		return format
+	}
+	return key
+}
+
+// msgf formats a message in the current language.
+func msgf(key string, args ...any) string {
+	return fmt.Sprintf(msg(key), args...)
+}
+
+// msgErr creates an error from a message in the current language.
+func msgErr(key string) error {
+	return errors.New(msg(key))
+}
+
+// parseLanguage parses the value of --lang.
+func parseLanguage(value string) (language, error) {
+	switch lang := language(strings.ToLower(value)); lang {
+	case languageEnglish, languageChinese:
+		return lang, nil
+	}
+	return "", fmt.Errorf(msg("error.unknown-language"), value)
+}
+
+// languageFromEnv returns the language of the locale set in the environment.
+// LC_ALL and LC_MESSAGES take precedence over LANG, like they do for gettext.
+func languageFromEnv(getenv func(string) string) language {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			if strings.HasPrefix(strings.ToLower(locale), "zh") {
+				return languageChinese
+			}
+			return languageEnglish
+		}
+	}
+	return languageEnglish
+}
+
+// flagFromArgs returns the value of the flag called name in the command line arguments.
+//
+// The language has to be known before the flags are defined,
+// because their usage is translated too, so --lang, and --config and -p
+// for the language of the configuration file, are looked up by hand.
+func flagFromArgs(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		argName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if argName != name {
+			continue
+		}
+		if hasValue {
+			return value, true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// languageFromConfig returns the lang option of the configuration file of --config in args,
+// or of the default one, preferring the option of the profile chosen with -p.
+//
+// A configuration file that can't be read is ignored here,
+// it's reported once the flags are parsed.
+func languageFromConfig(args []string) (string, bool) {
+	path, _ := flagFromArgs(args, "config")
+	cfg, err := loadConfig(path)
+	if err != nil || cfg == nil {
+		return "", false
+	}
+
+	value, ok := cfg.Options["lang"]
+	if profileName, found := flagFromArgs(args, "p"); found {
+		if profileValue, found := cfg.Profiles[profileName].Options["lang"]; found {
+			value, ok = profileValue, true
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// setLanguage selects the language of the messages from --lang in args,
+// from the configuration file if it's not given, or from the environment.
+func setLanguage(args []string) error {
+	currentLanguage = languageFromEnv(os.Getenv)
+
+	value, ok := flagFromArgs(args, "lang")
+	if !ok {
+		value, ok = languageFromConfig(args)
+	}
+	if !ok {
+		return nil
+	}
+	lang, err := parseLanguage(value)
+	if err != nil {
+		return err
+	}
+	currentLanguage = lang
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useLanguage switches the language of the messages for the duration of a test.
+func useLanguage(t *testing.T, lang language) {
+	previous := currentLanguage
+	currentLanguage = lang
+	t.Cleanup(func() { currentLanguage = previous })
+}
+
+// formatVerbs returns the verbs of a format string, e.g. ["s", "d"] for "%s: %5d%%".
+func formatVerbs(format string) []string {
+	var verbs []string
+	for _, match := range regexp.MustCompile(`%[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`).FindAllStringSubmatch(format, -1) {
+		if match[1] != "%" {
+			verbs = append(verbs, match[1])
+		}
+	}
+	return verbs
+}
+
+func TestCatalogsHaveEveryKey(t *testing.T) {
+	for lang, messages := range catalogs {
+		for key, format := range englishMessages {
+			translation, ok := messages[key]
+			if assert.True(t, ok, "%s has no %q message", lang, key) {
+				assert.Equal(t, formatVerbs(format), formatVerbs(translation), "%s message %q has other verbs", lang, key)
+			}
+		}
+		for key := range messages {
+			assert.Contains(t, englishMessages, key, "%s message %q is not in the English catalog", lang, key)
+		}
+	}
+}
+
+func TestUsedMessagesExist(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	// messages used in the code and in the templates of the HTML report
+	usage := regexp.MustCompile(`(?:\bmsg|\bmsgf|\bmsgErr)\("([^"]+)"|\{\{msgf? "([^"]+)"`)
+	used := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		for _, match := range usage.FindAllStringSubmatch(string(content), -1) {
+			key := match[1] + match[2]
+			used++
+			for lang, messages := range catalogs {
+				assert.Contains(t, messages, key, "%s uses %q, which %s has no message for", file, key, lang)
+			}
+		}
+	}
+	assert.NotZero(t, used)
+}
+
+func TestMsg(t *testing.T) {
+	useLanguage(t, languageChinese)
+
+	assert.Equal(t, "超时", errorKindTimeout.description())
+	assert.Equal(t, "5 分钟 3 秒", durationToString(5*time.Minute+3*time.Second))
+	assert.Equal(t, "no.such.message", msg("no.such.message"))
+	assert.EqualError(t, msgErr("serve.job-not-found"), "任务不存在")
+}
+
+func TestParseLanguage(t *testing.T) {
+	lang, err := parseLanguage("ZH")
+	require.NoError(t, err)
+	assert.Equal(t, languageChinese, lang)
+
+	lang, err = parseLanguage("en")
+	require.NoError(t, err)
+	assert.Equal(t, languageEnglish, lang)
+
+	_, err = parseLanguage("fr")
+	assert.Error(t, err)
+}
+
+func TestLanguageFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want language
+	}{
+		{"unset", nil, languageEnglish},
+		{"chinese LANG", map[string]string{"LANG": "zh_CN.UTF-8"}, languageChinese},
+		{"english LANG", map[string]string{"LANG": "en_US.UTF-8"}, languageEnglish},
+		{"POSIX locale", map[string]string{"LANG": "C"}, languageEnglish},
+		{"LC_ALL overrides LANG", map[string]string{"LC_ALL": "en_GB.UTF-8", "LANG": "zh_TW.UTF-8"}, languageEnglish},
+		{"LC_MESSAGES overrides LANG", map[string]string{"LC_MESSAGES": "zh_CN.UTF-8", "LANG": "en_US.UTF-8"}, languageChinese},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			assert.Equal(t, tt.want, languageFromEnv(getenv))
+		})
+	}
+}
+
+func TestFlagFromArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		want  string
+		found bool
+	}{
+		{[]string{"example.com", "443"}, "", false},
+		{[]string{"--lang", "zh", "example.com", "443"}, "zh", true},
+		{[]string{"example.com", "443", "-lang=en"}, "en", true},
+		{[]string{"report", "tcping.db", "--lang=zh"}, "zh", true},
+		{[]string{"--", "--lang", "zh"}, "", false},
+		{[]string{"--lang"}, "", false},
+	}
+	for _, tt := range tests {
+		value, found := flagFromArgs(tt.args, "lang")
+		assert.Equal(t, tt.found, found, tt.args)
+		assert.Equal(t, tt.want, value, tt.args)
+	}
+}
+
+func TestSetLanguageFromConfig(t *testing.T) {
+	useLanguage(t, languageEnglish)
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "lang: zh\nprofiles:\n  english:\n    host: example.com\n    port: 443\n    lang: en\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	// the language of the configuration file is known before the flags are defined
+	require.NoError(t, setLanguage([]string{"--config", path, "example.com", "443"}))
+	assert.Equal(t, languageChinese, currentLanguage)
+
+	require.NoError(t, setLanguage([]string{"--config=" + path, "-p", "english"}))
+	assert.Equal(t, languageEnglish, currentLanguage, "the profile takes precedence")
+
+	require.NoError(t, setLanguage([]string{"--config", path, "-p", "english", "--lang", "zh"}))
+	assert.Equal(t, languageChinese, currentLanguage, "--lang takes precedence")
+
+	// an unreadable configuration file is reported later, once the flags are parsed
+	require.NoError(t, setLanguage([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}))
+	assert.Equal(t, languageEnglish, currentLanguage)
+}
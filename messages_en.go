@@ -0,0 +1,444 @@
+// messages_en.go contains the English messages
+package main
+
+// englishMessages is the catalog of the English messages.
+// It's the reference every other catalog is compared with.
+var englishMessages = catalog{
+	// usage
+	"usage.version": "TCPING version %s",
+	"usage.title":   "%s usage:",
+	"usage.targets": "%s <hostname/ip> <port number> [<hostname/ip> <port number> ...]  for example:",
+	"usage.profile": "%s -p <profile name>  use the targets and options of a profile in the configuration file",
+	"usage.serve":   "%s serve [--listen <address>] [--jobs-file <file>]  run as a daemon that manages probe jobs through an HTTP API",
+	"usage.report":  "%s report <file> [--session <name>]  analyze a session saved by --db or --csv",
+	"usage.options": "[optional flags]",
+	"usage.srv":     "%s <_service._proto.name>  probe the targets of the SRV records of a name, e.g. _sip._tcp.example.com",
+
+	// flags
+	"flag.4":                    "Only use IPv4.",
+	"flag.6":                    "Only use IPv6.",
+	"flag.r":                    "Retry resolving target's hostname after <n> number of failed probes. e.g. -r 10 to retry after 10 failed probes.",
+	"flag.c":                    "Stop after <n> probes, regardless of the result. By default, no limit will be applied.",
+	"flag.j":                    "Output in JSON format.",
+	"flag.pretty":               "Use indentation when using json output format. No effect without the '-j' flag.",
+	"flag.no-color":             "Do not colorize output.",
+	"flag.tui":                  "Show a full-screen live dashboard. Press p to pause/resume, r to reset the statistics, Tab to switch targets and q to quit.",
+	"flag.D":                    "Show timestamp in output.",
+	"flag.csv":                  "Path and file name to store tcping output to CSV file...If user prompts for stats, it will be saved to a file with the same name but _stats appended.",
+	"flag.v":                    "Show version.",
+	"flag.u":                    "Check for updates and exit.",
+	"flag.i":                    "Interval between sending probes. Real number allowed with dot as a decimal separator. The default is one second",
+	"flag.t":                    "Time to wait for a response, in seconds. Real number allowed. 0 means infinite timeout.",
+	"flag.db":                   "Path and file name to store tcping output to sqlite database.",
+	"flag.I":                    "Interface name or address.",
+	"flag.show-source-address":  "Show source address and port used for probe.",
+	"flag.show-failures-only":   "Show only the failed probes.",
+	"flag.f":                    "Read the targets from a file, one \"<hostname/ip> <port number>\" or SRV name per line.",
+	"flag.tls":                  "Perform a TLS handshake after connecting.",
+	"flag.sni":                  "Server name (SNI) of the TLS handshake. The default is the target hostname.",
+	"flag.alpn":                 "ALPN protocols offered in the TLS handshake, separated by commas, e.g. h2,http/1.1.",
+	"flag.ca-file":              "CA certificates file (PEM) to verify the server certificate with.",
+	"flag.cert":                 "TLS client certificate file (PEM).",
+	"flag.key":                  "TLS client private key file (PEM).",
+	"flag.insecure":             "Do not verify the server certificate.",
+	"flag.cert-expiry-warning":  "Show a warning when the certificate expires within <n> days.",
+	"flag.http":                 "Send an HTTP request after connecting and check the response. Sends an HTTPS request with --tls.",
+	"flag.http-method":          "HTTP request method.",
+	"flag.http-path":            "HTTP request path.",
+	"flag.http-host":            "HTTP Host header. The default is the target hostname and port.",
+	"flag.expect-status":        "Expected HTTP status codes, e.g. 200 or 200,301-308.",
+	"flag.expect-body":          "Regular expression the response body is expected to match.",
+	"flag.udp":                  "Probe over UDP: send a payload and wait for a reply.",
+	"flag.udp-payload":          "UDP payload string.",
+	"flag.udp-payload-hex":      "UDP payload in hexadecimal, e.g. \"de ad be ef\".",
+	"flag.udp-payload-file":     "Read the UDP payload from a file.",
+	"flag.udp-preset":           "Use a preset UDP payload: dns or ntp.",
+	"flag.udp-expect":           "Regular expression the reply is expected to match. By default, any reply is accepted.",
+	"flag.prometheus":           "Serve Prometheus metrics on the given address, e.g. :9100.",
+	"flag.prometheus-buckets":   "Upper bounds of the buckets of the Prometheus RTT histogram, separated by commas, in milliseconds.",
+	"flag.html":                 "Write an offline HTML report with an RTT chart, an availability timeline and the statistics to a file when the statistics are printed.",
+	"flag.alert-command":        "Shell command run when a target goes down or recovers, the event is passed in TCPING_* environment variables.",
+	"flag.alert-webhook":        "Webhook URL a JSON event is POSTed to when a target goes down or recovers.",
+	"flag.alert-webhook-format": "Webhook format: generic, slack or pagerduty.",
+	"flag.alert-routing-key":    "Integration key (routing key) of the pagerduty format.",
+	"flag.alert-threshold":      "Send a down alert after <n> consecutive failed probes.",
+	"flag.alert-cooldown":       "Minimum time between two down notifications of the same alert, in seconds.",
+	"flag.down-after":           "Only consider the target down after <n> consecutive failed probes.",
+	"flag.up-after":             "Only consider the target up again after <n> consecutive successful probes.",
+	"flag.flap-threshold":       "Consider the target flapping when its state changes <n> times within --flap-window. 0 disables flap detection.",
+	"flag.flap-window":          "Time window of the flap detection, in seconds.",
+	"flag.check":                "Health check mode: after -c probes, exit with a non-zero status code if a threshold is exceeded.",
+	"flag.max-loss":             "Maximum packet loss allowed in --check mode, in percent.",
+	"flag.max-avg-rtt":          "Maximum average rtt allowed in --check mode, in milliseconds. 0 disables the check.",
+	"flag.max-p95-rtt":          "Maximum p95 rtt allowed in --check mode, in milliseconds. 0 disables the check.",
+	"flag.config":               "Path of the configuration file. The default is $XDG_CONFIG_HOME/tcping/config.yaml.",
+	"flag.p":                    "Use a profile of the configuration file, e.g. -p prod-db.",
+	"flag.h":                    "Show help message.",
+	"flag.lang":                 "Language of the messages: en or zh. The default is taken from the LANG environment variable.",
+	"flag.serve.listen":         "Listen address of the HTTP API.",
+	"flag.serve.jobs-file":      "Path of the file the probe jobs are saved in.",
+	"flag.report.session":       "Session to analyze, i.e. the table name in a database. Can be omitted if the file has only one session.",
+	"flag.report.buckets":       "Upper bounds of the buckets of the RTT histogram, separated by commas, in milliseconds.",
+	"flag.report.html":          "Also write an offline HTML report with an RTT chart, an availability timeline and the statistics to a file.",
+	"flag.http-header":          "Add a \"Name: value\" HTTP request header. Can be given several times.",
+	"flag.expect-header":        "Expect a response header to match \"Name: regexp\". Can be given several times.",
+	"flag.all-addresses":        "Probe every resolved address of the hostname on each interval, keeping the statistics of every address and summarizing the failing ones on exit. -4 and -6 limit the addresses to one IP version",
+	"flag.dual-stack":           "Probe an IPv4 and an IPv6 address of the hostname side by side on each interval and compare the packet loss, RTT and outages of both IP versions on exit. It can't be used with -4, -6 or --all-addresses",
+	"flag.happy-eyeballs":       "Connect like Happy Eyeballs (RFC 8305) clients: race the resolved addresses, alternating between IPv6 and IPv4, and report which address won, the fallback time and whether IPv6 keeps losing",
+	"flag.attempt-delay":        "Seconds to wait for a connection attempt before racing the next address in --happy-eyeballs mode",
+	"flag.resolver":             "DNS server to resolve the hostname, e.g. 1.1.1.1, tcp://1.1.1.1, tls://1.1.1.1:853 or https://1.1.1.1/dns-query",
+	"flag.dns-timeout":          "timeout in seconds of a hostname lookup",
+	"flag.resolve-ttl":          "resolve the hostname again when the TTL of its records expires, requires --resolver",
+	"flag.srv-weighted":         "probe the targets of an SRV name one after another by priority and weight as in RFC 2782, until one succeeds, instead of all of them",
+	"flag.srv-interval":         "seconds between lookups of the SRV records, unless --resolve-ttl follows their TTL",
+
+	// error kinds
+	"error-kind.timeout":          "timeout",
+	"error-kind.refused":          "connection refused",
+	"error-kind.reset":            "connection reset",
+	"error-kind.net-unreachable":  "network unreachable",
+	"error-kind.no-route":         "no route to host",
+	"error-kind.permission":       "permission denied",
+	"error-kind.tls-handshake":    "TLS handshake failed",
+	"error-kind.tls-certificate":  "invalid TLS certificate",
+	"error-kind.http-status":      "unexpected HTTP status",
+	"error-kind.http-header":      "HTTP header mismatch",
+	"error-kind.http-body":        "HTTP body mismatch",
+	"error-kind.http-error":       "invalid HTTP response",
+	"error-kind.unexpected-reply": "unexpected reply",
+	"error-kind.other":            "other error",
+
+	// errors
+	"error.pretty-without-json": "--pretty has no effect without the -j flag.",
+	"error.tui-with-output":     "--tui cannot be used with the -j, --db or --csv flags.",
+	"error.tui-failures-only":   "--tui cannot be used with --show-failures-only.",
+	"error.create-csv":          "Failed to create the CSV file: %s",
+	"error.ip-versions":         "Only one IP version can be specified",
+	"error.prometheus-buckets":  "Invalid Prometheus buckets: %s",
+	"error.prometheus-start":    "Failed to start the Prometheus server: %s",
+	"error.alerts":              "Invalid alert configuration: %s",
+	"error.invalid-port":        "Invalid port number: %s",
+	"error.port-range":          "Port should be in the 1-65535 range",
+	"error.interval":            "Wait interval should be more than 2 ms",
+	"error.tls":                 "Invalid TLS configuration: %s",
+	"error.read-config":         "Failed to read the configuration file: %s",
+	"error.apply-config":        "Failed to apply the configuration file: %s",
+	"error.read-targets":        "Failed to read the targets file: %s",
+	"error.http":                "Invalid HTTP configuration: %s",
+	"error.down-up-after":       "--down-after and --up-after should be greater than zero",
+	"error.flap-window":         "--flap-window should be greater than zero",
+	"error.check":               "Invalid health check configuration: %s",
+	"error.udp-with-tls":        "--udp cannot be used with --tls or --http",
+	"error.udp":                 "Invalid UDP configuration: %s",
+	"error.targets-line":        "%s:%d: expected \"<host> <port>\" or an SRV name, got %q",
+	"error.no-targets":          "%s: no targets found",
+	"error.interface-not-found": "interface %s not found",
+	"error.interface-addresses": "unable to get the addresses of the interface",
+	"error.interface-ip":        "unable to get an IP address of the interface",
+	"error.no-ipv4":             "unable to find an IPv4 address for %s",
+	"error.no-ipv6":             "unable to find an IPv6 address for %s",
+	"error.resolve":             "unable to resolve %s: %w",
+	"error.unknown-language":    "unknown language %q, available: en, zh",
+	"error.invalid-regexp":      "invalid regular expression %q: %w",
+	"error.dual-stack":          "--dual-stack can't be used with -4, -6 or --all-addresses",
+	"error.dual-stack-ip":       "--dual-stack needs a hostname, not the IP address %s",
+	"error.happy-eyeballs":      "--happy-eyeballs can't be used with --udp, --all-addresses or --dual-stack",
+	"error.attempt-delay":       "--attempt-delay must be between %g and %g seconds",
+	"error.dns-timeout":         "--dns-timeout must be greater than 0",
+	"error.resolver":            "invalid --resolver: %s",
+	"error.resolve-ttl":         "--resolve-ttl requires --resolver, the system resolver doesn't report the TTLs",
+	"error.srv-mode":            "an SRV name can't be used with --all-addresses or --dual-stack",
+	"error.srv-interval":        "--srv-interval must be greater than 0",
+
+	// updates
+	"update.failed":      "Failed to check for updates %s",
+	"update.invalid-tag": "Failed to check for updates. The version name does not match the rule: %s",
+	"update.found":       "Found newer version %s",
+	"update.url":         "Please update TCPING from the URL below:",
+	"update.newer":       "Current version %s is newer than the latest release %s",
+	"update.latest":      "You have the latest version: %s",
+
+	// probes
+	"probe.start":            "TCPinging %s on port %d",
+	"probe.reply":            "Reply from %s on port %d TCP_conn=%d time=%s ms",
+	"probe.reply-source":     "Reply from %s on port %d using %s TCP_conn=%d time=%s ms",
+	"probe.no-reply":         "No reply from %s on port %d TCP_conn=%d (%s)",
+	"probe.downtime":         "No response received from %s on port %d for %s",
+	"probe.retrying":         "Retrying to resolve %s",
+	"probe.flapping":         "%s is flapping: within %s its state changed %d times",
+	"probe.stopped-flapping": "%s stopped flapping, current state: %s",
+	"probe.certificate":      "certificate=%q expires in %d days",
+	"probe.udp-reply":        "reply=%d bytes",
+	"probe.http":             "HTTP=%d TTFB=%.1f ms total=%.1f ms",
+	"probe.cert-expiring":    "Warning: the certificate of %s expires in %d days (%s)",
+	"probe.cert-expired":     "Warning: the certificate of %s has expired (%s)",
+
+	// statistics
+	"stats.title":            "%s TCPing statistics",
+	"stats.transmitted":      "%d probes transmitted on port %d",
+	"stats.received":         "%d received",
+	"stats.loss":             "packet loss",
+	"stats.probes":           "Probes",
+	"stats.loss-rate":        "Packet loss",
+	"stats.successful":       "Successful probes",
+	"stats.unsuccessful":     "Unsuccessful probes",
+	"stats.last-success":     "Last successful probe",
+	"stats.last-failure":     "Last unsuccessful probe",
+	"stats.never-succeeded":  "Never succeeded",
+	"stats.never-failed":     "Never failed",
+	"stats.total-uptime":     "Total uptime",
+	"stats.total-downtime":   "Total downtime",
+	"stats.state":            "Current state",
+	"stats.state-changes":    "State changes",
+	"stats.flaps":            "Flaps",
+	"stats.times":            "%d",
+	"stats.longest-uptime":   "Longest consecutive uptime",
+	"stats.longest-downtime": "Longest consecutive downtime",
+	"stats.from":             "from",
+	"stats.to":               "to",
+	"stats.at":               "at",
+	"stats.retried-lookups":  "Retried to resolve hostname",
+	"stats.hostname-changes": "IP address changes",
+	"stats.min":              "min",
+	"stats.avg":              "avg",
+	"stats.max":              "max",
+	"stats.rtt-percentiles":  "rtt median/p90/p95/p99",
+	"stats.rtt-stddev":       "rtt standard deviation",
+	"stats.jitter":           "jitter",
+	"stats.phase":            "%s min/avg/max",
+	"stats.certificate":      "Certificate",
+	"stats.cert-expiry":      "Certificate expires",
+	"stats.days-left":        "%s (%d days left)",
+	"stats.started":          "TCPing started at",
+	"stats.ended":            "TCPing ended at",
+	"stats.duration":         "Duration (HH:MM:SS)",
+	"stats.races":            "Happy Eyeballs races: %d | IPv6 won: %d | IPv4 won: %d",
+	"stats.ipv6-losses":      "IPv6 lost the race",
+	"stats.fallbacks":        "Fallbacks",
+	"stats.fallback-times":   "%d | avg/max: %.1f/%.1f ms",
+	"stats.race-winners":     "Winning addresses",
+	"stats.ipv6-losing":      "IPv6 keeps losing the race to IPv4",
+	"stats.resolver":         "Resolver",
+	"stats.dns-lookups":      "DNS lookups: %d | failed: %d",
+	"stats.dns-latency":      "DNS lookup time min/avg/max",
+	"stats.srv-record":       "SRV record",
+	"stats.srv-record-value": "%s, priority %d, weight %d",
+	"stats.srv-changes":      "SRV record changes",
+	"stats.srv-added":        "added",
+	"stats.srv-removed":      "removed",
+
+	// durations
+	"duration.hour":    "%s hour",
+	"duration.hours":   "%s hours",
+	"duration.minute":  "%s minute",
+	"duration.minutes": "%s minutes",
+	"duration.second":  "%s second",
+	"duration.seconds": "%s seconds",
+
+	// states
+	"state.up":              "up",
+	"state.down":            "down",
+	"state.flapping":        "flapping",
+	"state.unknown":         "unknown",
+	"state.flapping-marker": ", flapping",
+
+	// phases
+	"phase.dns":        "DNS lookup",
+	"phase.connect":    "TCP connect",
+	"phase.tls":        "TLS handshake",
+	"phase.first-byte": "first byte",
+	"phase.unknown":    "unknown",
+
+	// health checks
+	"check.max-loss":     "--max-loss should be between 0 and 100",
+	"check.negative-rtt": "--max-avg-rtt and --max-p95-rtt cannot be negative",
+	"check.healthy":      "health check passed",
+	"check.no-success":   "health check failed: no successful probes",
+	"check.loss":         "health check failed: packet loss %.2f%% exceeds the threshold of %.2f%%",
+	"check.avg-rtt":      "health check failed: average rtt %.1f ms exceeds the threshold of %.1f ms",
+	"check.p95-rtt":      "health check failed: p95 rtt %.1f ms exceeds the threshold of %.1f ms",
+	"check.failed":       "health check failed",
+	"check.exit-code":    "exit code %d",
+
+	// alerts
+	"alert.threshold":      "the threshold should be greater than zero",
+	"alert.cooldown":       "the cooldown cannot be negative",
+	"alert.routing-key":    "the pagerduty format requires --alert-routing-key",
+	"alert.webhook-format": "unknown webhook format %q, available: generic, slack, pagerduty",
+	"alert.down":           "tcping: %s is down after %d consecutive failed probes (%s)",
+	"alert.up":             "tcping: %s is up again after %s of downtime",
+	"alert.send-failed":    "Failed to send an alert: %s",
+	"alert.command-failed": "command %q failed: %w: %s",
+	"alert.webhook-status": "webhook %s returned %s",
+
+	// configuration file
+	"config.not-found":      "the configuration file %s does not exist",
+	"config.no-file":        "no configuration file found, unable to use the profile %q",
+	"config.no-profile":     "the configuration file has no profile %q",
+	"config.profile":        "profile %q: %w",
+	"config.unknown-option": "unknown option %q",
+	"config.option":         "option %q: %w",
+	"config.host-port":      "host and port must be given together",
+	"config.target":         "a target should be \"<hostname/ip> <port number>\" or an SRV name, not %q",
+	"config.no-list":        "lists are not accepted",
+	"config.missing-value":  "missing value",
+
+	// HTTP probes
+	"http.invalid-path":      "invalid path %q",
+	"http.invalid-header":    "invalid header %q, expected \"Name: value\"",
+	"http.invalid-status":    "invalid HTTP status code %q",
+	"http.no-status":         "at least one HTTP status code is required",
+	"http.unexpected-status": "unexpected HTTP status %d",
+	"http.header-mismatch":   "header %s does not match %q",
+	"http.body-mismatch":     "response body does not match %q",
+
+	// TLS probes
+	"tls.no-certificates": "no certificates found in %s",
+	"tls.cert-key":        "--cert and --key must be given together",
+
+	// UDP probes
+	"udp.payloads":       "only one of --udp-payload, --udp-payload-hex, --udp-payload-file and --udp-preset can be given",
+	"udp.invalid-hex":    "invalid hexadecimal payload: %w",
+	"udp.unknown-preset": "unknown preset payload %q, available: dns, ntp",
+
+	// Prometheus
+	"prometheus.invalid-bucket":  "invalid bucket %q",
+	"prometheus.bucket-positive": "bucket %q should be greater than zero",
+	"prometheus.no-buckets":      "no buckets given",
+
+	// CSV output
+	"csv.create-data":         "error creating data CSV file: %w",
+	"csv.write-headers":       "failed to write headers: %w",
+	"csv.recreate-data":       "failed to recreate data CSV file: %w",
+	"csv.write-record":        "failed to write record: %w",
+	"csv.write-success":       "failed to write success record: %v",
+	"csv.write-failure":       "failed to write failure record: %v",
+	"csv.write-resolve":       "failed to write resolve record: %v",
+	"csv.write-state-change":  "failed to write state change record: %v",
+	"csv.write-stats-headers": "failed to write statistics headers: %w",
+	"csv.recreate-stats":      "failed to recreate statistics CSV file: %w",
+	"csv.write-stats-record":  "failed to write statistics record: %w",
+	"csv.create-stats":        "failed to create statistics CSV file: %v",
+	"csv.write-stats":         "failed to write statistics record: %v",
+	"csv.write-check":         "failed to write check record: %v",
+	"csv.start":               "TCPing results for %s on port %d being written to: %s",
+	"csv.error":               "CSV Error: ",
+	"csv.stats-written":       "TCPing statistics written to: %s",
+	"csv.write-addresses":     "failed to write address record: %v",
+	"csv.write-dual-stack":    "failed to write dual stack record: %v",
+
+	// database output
+	"db.create-failed":                 "Error while creating the database %q: %s",
+	"db.write-failed":                  "Error writing to the database %q \nerr: %s",
+	"db.write-probes-failed":           "Error while writing probes to the database %q\nerr: %s",
+	"db.write-state-change-failed":     "Error while writing a state change to the database %q\nerr: %s",
+	"db.write-check-failed":            "Error while writing the check result to the database %q\nerr: %s",
+	"db.write-stats-failed":            "Error while writing stats to the database %q\nerr: %s",
+	"db.write-hostname-changes-failed": "Error while writing hostname changes to the database %q\nerr: %s",
+	"db.stats-saved":                   "Statistics for %q have been saved to %q in the table %q",
+	"db.write-addresses-failed":        "Error while writing the addresses to the database %q\nerr: %s",
+	"db.write-dual-stack-failed":       "Error while writing the dual stack comparison to the database %q\nerr: %s",
+	"db.write-srv-changes-failed":      "Error while writing SRV target changes to the database %q\nerr: %s",
+
+	// serve subcommand
+	"serve.load-failed":     "Failed to load the probe jobs: %s",
+	"serve.listening":       "Serving the HTTP API on %s, jobs are saved in %s",
+	"serve.start-failed":    "Failed to start the HTTP API: %s",
+	"serve.invalid-request": "invalid request: %w",
+	"serve.host-required":   "a host is required",
+	"serve.job":             "job %s: %w",
+	"serve.save-failed":     "Failed to save the probe jobs: %s",
+	"serve.job-not-found":   "job not found",
+
+	// report subcommand
+	"report.usage":               "Usage: tcping report [-j] [--pretty] [--no-color] [--session <name>] [--buckets <ms,...>] [--html <file>] <file>",
+	"report.invalid-buckets":     "Invalid --buckets: %s",
+	"report.read-failed":         "Failed to read %s: %s",
+	"report.read-session-failed": "Failed to read the session %s: %s",
+	"report.no-sessions":         "the file has no sessions",
+	"report.session-not-found":   "session %q does not exist",
+	"report.table":               "table %s: %w",
+	"report.csv-header":          "failed to read the CSV header: %w",
+	"report.csv-column":          "not a tcping CSV file: the %q column is missing",
+	"report.csv-port":            "line %d: invalid port: %w",
+	"report.csv-latency":         "line %d: invalid latency: %w",
+	"report.csv-line":            "line %d: %w",
+	"report.sessions":            "%s has %d sessions, pick one with --session <name>:",
+	"report.sessions-json":       "%s has %d sessions",
+	"report.probes":              "%d probes",
+	"report.from-to":             "from %s to %s",
+	"report.title":               "%s session report",
+	"report.untimed":             "The session has no timestamps (the CSV was saved without -D), so outages and hourly availability cannot be computed",
+	"report.outages":             "Outages",
+	"report.none":                "none",
+	"report.ongoing":             "ongoing",
+	"report.hourly":              "Hourly availability",
+	"report.histogram":           "rtt histogram (ms)",
+
+	// HTML report
+	"html.write-failed":    "Failed to write the HTML report: %s",
+	"html.written":         "HTML report written to: %s",
+	"html.title":           "TCPing report",
+	"html.generated":       "TCPing version %s, generated at %s",
+	"html.statistics":      "Statistics",
+	"html.start":           "Start",
+	"html.end":             "End",
+	"html.duration":        "Duration",
+	"html.untimed":         "The probes have no timestamps, so outages cannot be computed",
+	"html.from":            "From",
+	"html.to":              "To",
+	"html.time":            "Time",
+	"html.timeline":        "Availability timeline",
+	"html.legend-failures": "Outages / failed probes",
+
+	// dashboard
+	"tui.no-terminal":     "--tui has to run in a terminal",
+	"tui.terminal-failed": "unable to set up the terminal: %w",
+	"tui.reset":           "Statistics reset",
+	"tui.keys":            "p pause/resume | r reset statistics | Tab switch target | q quit",
+	"tui.paused":          "paused",
+	"tui.target":          "Target",
+	"tui.state":           "State",
+	"tui.streak":          "Streak",
+	"tui.trend":           "rtt trend (last %d)",
+	"tui.successes":       "ok %d",
+	"tui.failures":        "fail %d",
+	"tui.messages":        "Messages",
+	"tui.outages":         "Outages (last %d)",
+	"tui.ongoing-outage":  "since %s, ongoing for %s",
+	"tui.recovered":       "%s recovered after %s",
+	"tui.state-change":    "%s current state: %s",
+
+	// all addresses
+	"address.title":   "%s addresses",
+	"address.stats":   "%s: %s, %.2f%% packet loss",
+	"address.avg-rtt": ", rtt avg %.3f ms",
+	"address.failing": "Failing addresses",
+	"address.all-up":  "Every address is up",
+	"address.summary": "%s: %d of %d addresses failing",
+
+	// dual stack
+	"dual.title":         "%s IPv4 vs IPv6",
+	"dual.cycles":        "Cycles: %d, both up %d, only IPv4 down %d, only IPv6 down %d, both down %d",
+	"dual.rtt-delta":     "RTT of IPv6 minus IPv4: %+.3f ms",
+	"dual.single-family": "Only one IP version down",
+	"dual.summary":       "%s: IPv4 %.2f%% and IPv6 %.2f%% packet loss, only one IP version down %d times",
+
+	// DNS resolver
+	"dns.no-server":          "no DNS server in %q",
+	"dns.unexpected-path":    "unexpected path in %q, only https:// servers have a path",
+	"dns.unsupported-scheme": "unsupported scheme %q, use udp, tcp, tls or https",
+	"dns.no-addresses":       "no addresses found",
+	"dns.http-status":        "DNS server returned HTTP status %s",
+	"dns.invalid-name":       "invalid hostname %q",
+	"dns.malformed":          "malformed DNS response",
+	"dns.no-such-host":       "no such host",
+	"dns.rcode":              "DNS server returned error code %d",
+
+	// SRV records
+	"srv.found":         "%s: %d targets found in the SRV records",
+	"srv.added":         "%s: %s was added to the SRV records",
+	"srv.removed":       "%s: %s was removed from the SRV records",
+	"srv.lookup-failed": "unable to look up the SRV records of %s: %s",
+	"srv.no-records":    "%s: no SRV records found",
+}
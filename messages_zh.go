@@ -0,0 +1,443 @@
+// messages_zh.go contains the Chinese messages
+package main
+
+// chineseMessages is the catalog of the Chinese messages.
+var chineseMessages = catalog{
+	// usage
+	"usage.version": "TCPING 版本 %s",
+	"usage.title":   "%s 命令格式:",
+	"usage.targets": "%s <主机名/ip> <端口号> [<主机名/ip> <端口号> ...]  例如:",
+	"usage.profile": "%s -p <配置名称>  使用配置文件中的目标和选项",
+	"usage.serve":   "%s serve [--listen <地址>] [--jobs-file <文件>]  以守护进程模式运行，通过 HTTP API 管理探测任务",
+	"usage.report":  "%s report <文件> [--session <名称>]  分析 --db 或 --csv 保存的会话",
+	"usage.options": "[可选项]",
+	"usage.srv":     "%s <_服务._协议.名称>  探测一个名称的 SRV 记录中的目标，例如 _sip._tcp.example.com",
+
+	// flags
+	"flag.4":                    "仅使用IPv4。",
+	"flag.6":                    "仅使用IPv6。",
+	"flag.r":                    "在 <n> 次探测失败后重试解析目标主机名。例如：-r 10 表示10次失败后重试。",
+	"flag.c":                    "在 <n> 次探测后停止，无论结果如何。默认无限制。",
+	"flag.j":                    "以JSON格式输出。",
+	"flag.pretty":               "在使用json输出格式时使用缩进。没有'-j'标志时无效。",
+	"flag.no-color":             "不使用彩色输出。",
+	"flag.tui":                  "显示全屏实时仪表盘。按 p 暂停/继续，r 重置统计，Tab 切换目标，q 退出。",
+	"flag.D":                    "在输出中显示时间戳。",
+	"flag.csv":                  "保存tcping输出到CSV文件的路径和文件名...如果用户请求统计信息，它将被保存到同名但附加了_stats的文件中。",
+	"flag.v":                    "显示版本。",
+	"flag.u":                    "检查更新并退出。",
+	"flag.i":                    "发送探测之间的间隔。允许使用小数点分隔的实数。默认为一秒",
+	"flag.t":                    "等待响应的时间，以秒为单位。允许使用实数。0表示无限超时。",
+	"flag.db":                   "保存tcping输出到sqlite数据库的路径和文件名。",
+	"flag.I":                    "接口名称或地址。",
+	"flag.show-source-address":  "显示用于探测的源地址和端口。",
+	"flag.show-failures-only":   "仅显示失败的探测。",
+	"flag.f":                    "从文件中读取目标，每行一个 \"<主机名/ip> <端口号>\" 或 SRV 名称。",
+	"flag.tls":                  "在连接后执行 TLS 握手。",
+	"flag.sni":                  "TLS 握手使用的服务器名称 (SNI)。默认为目标主机名。",
+	"flag.alpn":                 "TLS 握手提供的 ALPN 协议，以逗号分隔，例如 h2,http/1.1。",
+	"flag.ca-file":              "用于验证服务器证书的 CA 证书文件 (PEM)。",
+	"flag.cert":                 "TLS 客户端证书文件 (PEM)。",
+	"flag.key":                  "TLS 客户端私钥文件 (PEM)。",
+	"flag.insecure":             "不验证服务器证书。",
+	"flag.cert-expiry-warning":  "证书在 <n> 天内过期时显示警告。",
+	"flag.http":                 "在连接后发送 HTTP 请求，并检查响应。与 --tls 一起使用时发送 HTTPS 请求。",
+	"flag.http-method":          "HTTP 请求方法。",
+	"flag.http-path":            "HTTP 请求路径。",
+	"flag.http-host":            "HTTP Host 头部。默认为目标主机名和端口。",
+	"flag.expect-status":        "期望的 HTTP 状态码，例如 200 或 200,301-308。",
+	"flag.expect-body":          "期望响应正文匹配的正则表达式。",
+	"flag.udp":                  "使用 UDP 探测：发送负载并等待回复。",
+	"flag.udp-payload":          "UDP 负载字符串。",
+	"flag.udp-payload-hex":      "十六进制的 UDP 负载，例如 \"de ad be ef\"。",
+	"flag.udp-payload-file":     "从文件读取 UDP 负载。",
+	"flag.udp-preset":           "使用预设的 UDP 负载: dns 或 ntp。",
+	"flag.udp-expect":           "期望回复匹配的正则表达式。默认接受任何回复。",
+	"flag.prometheus":           "在指定地址上提供 Prometheus 指标，例如 :9100。",
+	"flag.prometheus-buckets":   "Prometheus RTT 直方图的桶上限，以逗号分隔，单位为毫秒。",
+	"flag.html":                 "在打印统计信息时，将包含 RTT 图表、可用性时间线和统计信息的离线 HTML 报告写入文件。",
+	"flag.alert-command":        "目标断开或恢复时运行的 shell 命令，事件通过 TCPING_* 环境变量传递。",
+	"flag.alert-webhook":        "目标断开或恢复时 POST JSON 的 webhook URL。",
+	"flag.alert-webhook-format": "webhook 格式: generic、slack 或 pagerduty。",
+	"flag.alert-routing-key":    "pagerduty 格式使用的集成密钥 (routing key)。",
+	"flag.alert-threshold":      "连续 <n> 次探测失败后发送断开告警。",
+	"flag.alert-cooldown":       "同一告警两次断开通知之间的最短时间，以秒为单位。",
+	"flag.down-after":           "连续 <n> 次探测失败后才认为目标离线。",
+	"flag.up-after":             "连续 <n> 次探测成功后才认为目标恢复在线。",
+	"flag.flap-threshold":       "在 --flap-window 内状态变化 <n> 次时认为目标在抖动。0 表示禁用抖动检测。",
+	"flag.flap-window":          "抖动检测的时间窗口，以秒为单位。",
+	"flag.check":                "健康检查模式: 在 -c 次探测后，如果超过阈值则以非零状态码退出。",
+	"flag.max-loss":             "--check 模式下允许的最大丢包率，以百分比为单位。",
+	"flag.max-avg-rtt":          "--check 模式下允许的最大平均 rtt，以毫秒为单位。0 表示不检查。",
+	"flag.max-p95-rtt":          "--check 模式下允许的最大 p95 rtt，以毫秒为单位。0 表示不检查。",
+	"flag.config":               "配置文件的路径。默认为 $XDG_CONFIG_HOME/tcping/config.yaml。",
+	"flag.p":                    "使用配置文件中的配置，例如 -p prod-db。",
+	"flag.h":                    "显示帮助信息。",
+	"flag.lang":                 "消息的语言: en 或 zh。默认根据 LANG 环境变量选择。",
+	"flag.serve.listen":         "HTTP API 的监听地址。",
+	"flag.serve.jobs-file":      "保存探测任务的文件路径。",
+	"flag.report.session":       "要分析的会话，即数据库中的表名。文件只有一个会话时可省略。",
+	"flag.report.buckets":       "RTT 直方图的桶上限，以逗号分隔，单位为毫秒。",
+	"flag.report.html":          "同时将包含 RTT 图表、可用性时间线和统计信息的离线 HTML 报告写入文件。",
+	"flag.http-header":          "添加 \"名称: 值\" HTTP 请求头部。可以多次指定。",
+	"flag.expect-header":        "期望响应头部匹配 \"名称: 正则表达式\"。可以多次指定。",
+	"flag.all-addresses":        "在每个间隔探测主机名解析出的每个地址，分别保留每个地址的统计信息，并在退出时汇总失败的地址。-4 和 -6 会将地址限制为一个 IP 版本",
+	"flag.dual-stack":           "在每个间隔同时探测主机名的一个 IPv4 地址和一个 IPv6 地址，并在退出时对比两个 IP 版本的丢包率、RTT 和中断。不能与 -4、-6 或 --all-addresses 一起使用",
+	"flag.happy-eyeballs":       "像 Happy Eyeballs (RFC 8305) 客户端一样连接：交替使用 IPv6 和 IPv4 竞速解析出的地址，并报告获胜的地址、回退时间以及 IPv6 是否一直落败",
+	"flag.attempt-delay":        "在 --happy-eyeballs 模式下，开始竞速下一个地址之前等待一次连接尝试的秒数",
+	"flag.resolver":             "解析主机名的 DNS 服务器，例如 1.1.1.1、tcp://1.1.1.1、tls://1.1.1.1:853 或 https://1.1.1.1/dns-query",
+	"flag.dns-timeout":          "主机名解析的超时时间（秒）",
+	"flag.resolve-ttl":          "在记录的 TTL 过期时重新解析主机名，需要 --resolver",
+	"flag.srv-weighted":         "按照 RFC 2782 的优先级和权重依次探测 SRV 名称的目标，直到其中一个成功，而不是探测全部目标",
+	"flag.srv-interval":         "两次查询 SRV 记录之间的秒数，除非使用 --resolve-ttl 跟随其 TTL",
+
+	// error kinds
+	"error-kind.timeout":          "超时",
+	"error-kind.refused":          "连接被拒绝",
+	"error-kind.reset":            "连接被重置",
+	"error-kind.net-unreachable":  "网络不可达",
+	"error-kind.no-route":         "没有到主机的路由",
+	"error-kind.permission":       "权限被拒绝",
+	"error-kind.tls-handshake":    "TLS 握手失败",
+	"error-kind.tls-certificate":  "TLS 证书无效",
+	"error-kind.http-status":      "意外的 HTTP 状态",
+	"error-kind.http-header":      "HTTP 响应头不匹配",
+	"error-kind.http-body":        "HTTP 响应正文不匹配",
+	"error-kind.http-error":       "无效的 HTTP 响应",
+	"error-kind.unexpected-reply": "意外的回复",
+	"error-kind.other":            "其他错误",
+
+	// errors
+	"error.pretty-without-json": "--pretty 标志在没有 -j 标志的情况下无效。",
+	"error.tui-with-output":     "--tui 标志不能与 -j、--db 或 --csv 标志一起使用。",
+	"error.tui-failures-only":   "--tui 标志不能与 --show-failures-only 标志一起使用。",
+	"error.create-csv":          "创建CSV文件失败: %s",
+	"error.ip-versions":         "只能指定一个IP版本",
+	"error.prometheus-buckets":  "无效的 Prometheus 桶: %s",
+	"error.prometheus-start":    "启动 Prometheus 服务失败: %s",
+	"error.alerts":              "无效的告警配置: %s",
+	"error.invalid-port":        "无效的端口号: %s",
+	"error.port-range":          "端口应该在 1-65535 范围内",
+	"error.interval":            "等待间隔应大于 2 毫秒",
+	"error.tls":                 "无效的 TLS 配置: %s",
+	"error.read-config":         "读取配置文件失败: %s",
+	"error.apply-config":        "应用配置文件失败: %s",
+	"error.read-targets":        "读取目标文件失败: %s",
+	"error.http":                "无效的 HTTP 配置: %s",
+	"error.down-up-after":       "--down-after 和 --up-after 应大于零",
+	"error.flap-window":         "--flap-window 应大于零",
+	"error.check":               "无效的健康检查配置: %s",
+	"error.udp-with-tls":        "--udp 不能与 --tls 或 --http 一起使用",
+	"error.udp":                 "无效的 UDP 配置: %s",
+	"error.targets-line":        "%s:%d: 应为 \"<主机名/ip> <端口号>\" 或 SRV 名称，而不是 %q",
+	"error.no-targets":          "%s: 没有找到目标",
+	"error.interface-not-found": "接口 %s 未找到",
+	"error.interface-addresses": "无法获取接口地址",
+	"error.interface-ip":        "无法获取接口的IP地址",
+	"error.no-ipv4":             "无法找到%s的IPv4地址",
+	"error.no-ipv6":             "无法找到%s的IPv6地址",
+	"error.resolve":             "无法解析%s: %w",
+	"error.unknown-language":    "未知的语言 %q，可用: en, zh",
+	"error.invalid-regexp":      "无效的正则表达式 %q: %w",
+	"error.dual-stack":          "--dual-stack 不能与 -4、-6 或 --all-addresses 一起使用",
+	"error.dual-stack-ip":       "--dual-stack 需要主机名，而不是 IP 地址 %s",
+	"error.happy-eyeballs":      "--happy-eyeballs 不能与 --udp、--all-addresses 或 --dual-stack 一起使用",
+	"error.attempt-delay":       "--attempt-delay 必须在 %g 到 %g 秒之间",
+	"error.dns-timeout":         "--dns-timeout 必须大于 0",
+	"error.resolver":            "无效的 --resolver：%s",
+	"error.resolve-ttl":         "--resolve-ttl 需要 --resolver，系统解析器不提供 TTL",
+	"error.srv-mode":            "SRV 名称不能与 --all-addresses 或 --dual-stack 一起使用",
+	"error.srv-interval":        "--srv-interval 必须大于 0",
+
+	// updates
+	"update.failed":      "检查更新失败 %s",
+	"update.invalid-tag": "检查更新失败。版本名称不符合规则: %s",
+	"update.found":       "发现新版本 %s",
+	"update.url":         "请从下方URL更新TCPING:",
+	"update.newer":       "当前版本 %s 比最新发布版本 %s 更新",
+	"update.latest":      "您使用的是最新版本: %s",
+
+	// probes
+	"probe.start":            "正在TCP探测 %s 的 %d 端口",
+	"probe.reply":            "响应自 %s 端口 %d TCP_conn=%d 时间=%s ms",
+	"probe.reply-source":     "响应自 %s 端口 %d 使用 %s TCP_conn=%d 时间=%s ms",
+	"probe.no-reply":         "未收到来自 %s 端口 %d 的响应 TCP_conn=%d (%s)",
+	"probe.downtime":         "%s 端口 %d 未收到响应 %s",
+	"probe.retrying":         "重试解析主机名 %s",
+	"probe.flapping":         "%s 状态抖动，在 %s 内变化了 %d 次",
+	"probe.stopped-flapping": "%s 停止抖动，当前状态: %s",
+	"probe.certificate":      "证书=%q 剩余 %d 天",
+	"probe.udp-reply":        "回复=%d 字节",
+	"probe.http":             "HTTP=%d TTFB=%.1f ms 总计=%.1f ms",
+	"probe.cert-expiring":    "警告: %s 的证书将在 %d 天后过期 (%s)",
+	"probe.cert-expired":     "警告: %s 的证书已过期 (%s)",
+
+	// statistics
+	"stats.title":            "%s TCPing 统计信息",
+	"stats.transmitted":      "%d 个探测包发送到 %d 端口",
+	"stats.received":         "%d 个探测包收到",
+	"stats.loss":             "丢失",
+	"stats.probes":           "探测包",
+	"stats.loss-rate":        "丢包率",
+	"stats.successful":       "成功探测包",
+	"stats.unsuccessful":     "失败探测包",
+	"stats.last-success":     "最后一次成功探测",
+	"stats.last-failure":     "最后一次失败探测",
+	"stats.never-succeeded":  "从未成功",
+	"stats.never-failed":     "从未失败",
+	"stats.total-uptime":     "总运行时间",
+	"stats.total-downtime":   "总暂停时间",
+	"stats.state":            "当前状态",
+	"stats.state-changes":    "状态变化",
+	"stats.flaps":            "抖动",
+	"stats.times":            "%d 次",
+	"stats.longest-uptime":   "最长连续运行时间",
+	"stats.longest-downtime": "最长连续暂停时间",
+	"stats.from":             "从",
+	"stats.to":               "到",
+	"stats.at":               "于",
+	"stats.retried-lookups":  "重试解析主机名",
+	"stats.hostname-changes": "主机名解析变更",
+	"stats.min":              "最小",
+	"stats.avg":              "平均",
+	"stats.max":              "最大",
+	"stats.rtt-percentiles":  "rtt 中位数/p90/p95/p99",
+	"stats.rtt-stddev":       "rtt 标准差",
+	"stats.jitter":           "抖动",
+	"stats.phase":            "%s 最小/平均/最大",
+	"stats.certificate":      "证书",
+	"stats.cert-expiry":      "证书到期时间",
+	"stats.days-left":        "%s (剩余 %d 天)",
+	"stats.started":          "TCPing 开始时间",
+	"stats.ended":            "TCPing 结束时间",
+	"stats.duration":         "持续时间 (HH:MM:SS)",
+	"stats.races":            "Happy Eyeballs 竞速: %d | IPv6 获胜: %d | IPv4 获胜: %d",
+	"stats.ipv6-losses":      "IPv6 竞速落败",
+	"stats.fallbacks":        "回退",
+	"stats.fallback-times":   "%d 次 | 平均/最大: %.1f/%.1f ms",
+	"stats.race-winners":     "获胜的地址",
+	"stats.ipv6-losing":      "IPv6 一直输给 IPv4",
+	"stats.resolver":         "DNS 服务器",
+	"stats.dns-lookups":      "DNS 解析: %d 次 | 失败: %d 次",
+	"stats.dns-latency":      "DNS 解析耗时 最小/平均/最大",
+	"stats.srv-record":       "SRV 记录",
+	"stats.srv-record-value": "%s，优先级 %d，权重 %d",
+	"stats.srv-changes":      "SRV 记录变化",
+	"stats.srv-added":        "加入",
+	"stats.srv-removed":      "移除",
+
+	// durations
+	"duration.hour":    "%s 小时",
+	"duration.hours":   "%s 小时",
+	"duration.minute":  "%s 分钟",
+	"duration.minutes": "%s 分钟",
+	"duration.second":  "%s 秒",
+	"duration.seconds": "%s 秒",
+
+	// states
+	"state.up":              "在线",
+	"state.down":            "离线",
+	"state.flapping":        "抖动",
+	"state.unknown":         "未知",
+	"state.flapping-marker": "，抖动中",
+
+	// phases
+	"phase.dns":        "DNS 解析",
+	"phase.connect":    "TCP 连接",
+	"phase.tls":        "TLS 握手",
+	"phase.first-byte": "首字节",
+	"phase.unknown":    "未知",
+
+	// health checks
+	"check.max-loss":     "--max-loss 应在 0 到 100 之间",
+	"check.negative-rtt": "--max-avg-rtt 和 --max-p95-rtt 不能为负数",
+	"check.healthy":      "健康检查通过",
+	"check.no-success":   "健康检查失败: 没有成功的探测",
+	"check.loss":         "健康检查失败: 丢包率 %.2f%% 超过阈值 %.2f%%",
+	"check.avg-rtt":      "健康检查失败: 平均 rtt %.1f ms 超过阈值 %.1f ms",
+	"check.p95-rtt":      "健康检查失败: p95 rtt %.1f ms 超过阈值 %.1f ms",
+	"check.failed":       "健康检查失败",
+	"check.exit-code":    "退出码 %d",
+
+	// alerts
+	"alert.threshold":      "阈值应大于零",
+	"alert.cooldown":       "冷却时间不能为负数",
+	"alert.routing-key":    "pagerduty 格式需要 --alert-routing-key",
+	"alert.webhook-format": "未知的 webhook 格式 %q，可用: generic, slack, pagerduty",
+	"alert.down":           "tcping: %s 已断开，连续 %d 次探测失败 (%s)",
+	"alert.up":             "tcping: %s 已恢复，停机 %s",
+	"alert.send-failed":    "发送告警失败: %s",
+	"alert.command-failed": "命令 %q 失败: %w: %s",
+	"alert.webhook-status": "webhook %s 返回 %s",
+
+	// configuration file
+	"config.not-found":      "配置文件 %s 不存在",
+	"config.no-file":        "未找到配置文件，无法使用配置 %q",
+	"config.no-profile":     "配置文件中没有配置 %q",
+	"config.profile":        "配置 %q: %w",
+	"config.unknown-option": "未知的配置选项 %q",
+	"config.option":         "配置选项 %q: %w",
+	"config.host-port":      "host 和 port 必须同时指定",
+	"config.target":         "目标应为 \"<主机名/ip> <端口号>\" 或 SRV 名称，而不是 %q",
+	"config.no-list":        "不接受列表",
+	"config.missing-value":  "缺少值",
+
+	// HTTP probes
+	"http.invalid-path":      "无效的路径 %q",
+	"http.invalid-header":    "无效的头部 %q，应为 \"名称: 值\"",
+	"http.invalid-status":    "无效的 HTTP 状态码 %q",
+	"http.no-status":         "需要至少一个 HTTP 状态码",
+	"http.unexpected-status": "意外的 HTTP 状态 %d",
+	"http.header-mismatch":   "头部 %s 不匹配 %q",
+	"http.body-mismatch":     "响应正文不匹配 %q",
+
+	// TLS probes
+	"tls.no-certificates": "%s 中没有找到证书",
+	"tls.cert-key":        "--cert 和 --key 必须同时指定",
+
+	// UDP probes
+	"udp.payloads":       "--udp-payload、--udp-payload-hex、--udp-payload-file 和 --udp-preset 只能指定一个",
+	"udp.invalid-hex":    "无效的十六进制负载: %w",
+	"udp.unknown-preset": "未知的预设负载 %q，可用: dns, ntp",
+
+	// Prometheus
+	"prometheus.invalid-bucket":  "无效的桶 %q",
+	"prometheus.bucket-positive": "桶 %q 应大于零",
+	"prometheus.no-buckets":      "没有指定桶",
+
+	// CSV output
+	"csv.create-data":         "创建数据 CSV 文件失败: %w",
+	"csv.write-headers":       "写入标题失败: %w",
+	"csv.recreate-data":       "重新创建数据 CSV 文件失败: %w",
+	"csv.write-record":        "写入记录失败: %w",
+	"csv.write-success":       "写入成功记录失败: %v",
+	"csv.write-failure":       "写入失败记录失败: %v",
+	"csv.write-resolve":       "写入解析记录失败: %v",
+	"csv.write-state-change":  "写入状态变化记录失败: %v",
+	"csv.write-stats-headers": "写入统计信息标题失败: %w",
+	"csv.recreate-stats":      "重新创建统计信息 CSV 文件失败: %w",
+	"csv.write-stats-record":  "写入统计信息记录失败: %w",
+	"csv.create-stats":        "创建统计信息 CSV 文件失败: %v",
+	"csv.write-stats":         "写入统计信息记录失败: %v",
+	"csv.write-check":         "写入健康检查记录失败: %v",
+	"csv.start":               "%s 端口 %d 的 TCPing 结果正在写入: %s",
+	"csv.error":               "CSV 错误: ",
+	"csv.stats-written":       "TCPing 统计信息已写入: %s",
+	"csv.write-addresses":     "写入地址记录失败: %v",
+	"csv.write-dual-stack":    "写入双栈记录失败: %v",
+
+	// database output
+	"db.create-failed":                 "创建数据库 %q 失败: %s",
+	"db.write-failed":                  "写入数据库 %q 失败\n错误: %s",
+	"db.write-probes-failed":           "将探测写入数据库 %q 失败\n错误: %s",
+	"db.write-state-change-failed":     "将状态变化写入数据库 %q 失败\n错误: %s",
+	"db.write-check-failed":            "将健康检查结果写入数据库 %q 失败\n错误: %s",
+	"db.write-stats-failed":            "将统计信息写入数据库 %q 失败\n错误: %s",
+	"db.write-hostname-changes-failed": "将主机名变更写入数据库 %q 失败\n错误: %s",
+	"db.stats-saved":                   "%q 的统计信息已保存到 %q 的表 %q 中",
+	"db.write-addresses-failed":        "将地址写入数据库 %q 失败\n错误: %s",
+	"db.write-dual-stack-failed":       "将双栈对比写入数据库 %q 失败\n错误: %s",
+	"db.write-srv-changes-failed":      "将 SRV 目标变更写入数据库 %q 失败\n错误: %s",
+
+	// serve subcommand
+	"serve.load-failed":     "加载探测任务失败: %s",
+	"serve.listening":       "在 %s 上提供 HTTP API，任务保存在 %s",
+	"serve.start-failed":    "启动 HTTP API 失败: %s",
+	"serve.invalid-request": "无效的请求: %w",
+	"serve.host-required":   "需要指定主机",
+	"serve.job":             "任务 %s: %w",
+	"serve.save-failed":     "保存探测任务失败: %s",
+	"serve.job-not-found":   "任务不存在",
+
+	// report subcommand
+	"report.usage":               "用法: tcping report [-j] [--pretty] [--no-color] [--session <名称>] [--buckets <毫秒,...>] [--html <文件>] <文件>",
+	"report.invalid-buckets":     "无效的 --buckets: %s",
+	"report.read-failed":         "读取 %s 失败: %s",
+	"report.read-session-failed": "读取会话 %s 失败: %s",
+	"report.no-sessions":         "文件中没有会话",
+	"report.session-not-found":   "会话 %q 不存在",
+	"report.table":               "表 %s: %w",
+	"report.csv-header":          "读取 CSV 标题失败: %w",
+	"report.csv-column":          "不是 tcping CSV 文件: 缺少 %q 列",
+	"report.csv-port":            "第 %d 行: 无效的端口: %w",
+	"report.csv-latency":         "第 %d 行: 无效的延迟: %w",
+	"report.csv-line":            "第 %d 行: %w",
+	"report.sessions":            "%s 中有 %d 个会话，使用 --session <名称> 选择一个:",
+	"report.sessions-json":       "%s 中有 %d 个会话",
+	"report.probes":              "%d 次探测",
+	"report.from-to":             "从 %s 到 %s",
+	"report.title":               "%s 会话报告",
+	"report.untimed":             "会话没有时间戳 (保存 CSV 时未使用 -D)，无法计算中断和每小时可用率",
+	"report.outages":             "中断",
+	"report.none":                "无",
+	"report.ongoing":             "进行中",
+	"report.hourly":              "每小时可用率",
+	"report.histogram":           "rtt 直方图 (ms)",
+
+	// HTML report
+	"html.write-failed":    "写入 HTML 报告失败: %s",
+	"html.written":         "HTML 报告已写入: %s",
+	"html.title":           "TCPing 报告",
+	"html.generated":       "TCPing 版本 %s，生成于 %s",
+	"html.statistics":      "统计信息",
+	"html.start":           "开始",
+	"html.end":             "结束",
+	"html.duration":        "持续时间",
+	"html.untimed":         "探测没有时间戳，无法计算中断",
+	"html.from":            "从",
+	"html.to":              "到",
+	"html.time":            "时间",
+	"html.timeline":        "可用性时间线",
+	"html.legend-failures": "中断 / 失败探测",
+
+	// dashboard
+	"tui.no-terminal":     "--tui 需要在终端中运行",
+	"tui.terminal-failed": "无法设置终端: %w",
+	"tui.reset":           "统计信息已重置",
+	"tui.keys":            "p 暂停/继续 | r 重置统计 | Tab 切换目标 | q 退出",
+	"tui.paused":          "已暂停",
+	"tui.target":          "目标",
+	"tui.state":           "状态",
+	"tui.streak":          "连续",
+	"tui.trend":           "rtt 走势 (最近 %d 次)",
+	"tui.successes":       "成功 %d",
+	"tui.failures":        "失败 %d",
+	"tui.messages":        "消息",
+	"tui.outages":         "中断 (最近 %d 次)",
+	"tui.ongoing-outage":  "从 %s 起 %s 进行中",
+	"tui.recovered":       "%s 在 %s 后恢复",
+	"tui.state-change":    "%s 当前状态: %s",
+
+	// all addresses
+	"address.title":   "%s 的地址",
+	"address.stats":   "%s: %s，丢包率 %.2f%%",
+	"address.avg-rtt": "，平均 rtt %.3f ms",
+	"address.failing": "失败的地址",
+	"address.all-up":  "所有地址均正常",
+	"address.summary": "%s: %d 个地址失败，共 %d 个",
+
+	// dual stack
+	"dual.title":         "%s 的 IPv4 与 IPv6 对比",
+	"dual.cycles":        "周期: %d，均正常 %d，仅 IPv4 中断 %d，仅 IPv6 中断 %d，均中断 %d",
+	"dual.rtt-delta":     "IPv6 与 IPv4 的 RTT 差: %+.3f ms",
+	"dual.single-family": "仅一个 IP 版本中断",
+	"dual.summary":       "%s: IPv4 丢包率 %.2f%%，IPv6 丢包率 %.2f%%，仅一个 IP 版本中断 %d 次",
+
+	// DNS resolver
+	"dns.no-server":          "%q 中没有 DNS 服务器",
+	"dns.unexpected-path":    "%q 中有多余的路径，只有 https:// 服务器有路径",
+	"dns.unsupported-scheme": "不支持的协议 %q，请使用 udp、tcp、tls 或 https",
+	"dns.no-addresses":       "未找到地址",
+	"dns.http-status":        "DNS 服务器返回 HTTP 状态 %s",
+	"dns.invalid-name":       "无效的主机名 %q",
+	"dns.malformed":          "DNS 响应格式错误",
+	"dns.no-such-host":       "主机不存在",
+	"dns.rcode":              "DNS 服务器返回错误码 %d",
+
+	// SRV records
+	"srv.found":         "%s：在 SRV 记录中找到 %d 个目标",
+	"srv.added":         "%s：%s 已加入 SRV 记录",
+	"srv.removed":       "%s：%s 已从 SRV 记录中移除",
+	"srv.lookup-failed": "无法查询 %s 的 SRV 记录：%s",
+	"srv.no-records":    "%s：未找到 SRV 记录",
+}
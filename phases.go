@@ -0,0 +1,124 @@
+// phases.go contains the breakdown of the timing of a probe into its DNS, TCP, TLS and first byte phases
+package main
+
+// phase is a step of a probe whose duration is measured on its own.
+type phase int
+
+const (
+	// phaseDNS is the hostname lookup before a probe, only measured when the hostname is (re)resolved.
+	phaseDNS phase = iota
+	// phaseConnect is the TCP handshake.
+	phaseConnect
+	// phaseTLS is the TLS handshake in --tls mode.
+	phaseTLS
+	// phaseFirstByte is the time from sending the request to the first byte of the response in --http mode.
+	phaseFirstByte
+
+	phaseCount
+)
+
+// String returns the stable name of the phase, used in JSON keys and database columns.
+func (p phase) String() string {
+	switch p {
+	case phaseDNS:
+		return "dns"
+	case phaseConnect:
+		return "connect"
+	case phaseTLS:
+		return "tls"
+	case phaseFirstByte:
+		return "first_byte"
+	default:
+		return "unknown"
+	}
+}
+
+// label returns the name of the phase used in CSV files.
+func (p phase) label() string {
+	switch p {
+	case phaseDNS:
+		return "DNS"
+	case phaseConnect:
+		return "Connect"
+	case phaseTLS:
+		return "TLS"
+	case phaseFirstByte:
+		return "First Byte"
+	default:
+		return "Unknown"
+	}
+}
+
+// description returns a human-readable name of the phase.
+func (p phase) description() string {
+	switch p {
+	case phaseDNS:
+		return msg("phase.dns")
+	case phaseConnect:
+		return msg("phase.connect")
+	case phaseTLS:
+		return msg("phase.tls")
+	case phaseFirstByte:
+		return msg("phase.first-byte")
+	default:
+		return msg("phase.unknown")
+	}
+}
+
+// phaseTimings holds the duration of every phase of a single probe in milliseconds.
+// Phases that did not happen are zero.
+type phaseTimings [phaseCount]float32
+
+// phaseStat keeps the min, max and average duration of a phase in constant memory.
+type phaseStat struct {
+	count uint
+	sum   float64
+	min   float32
+	max   float32
+}
+
+// phaseResult is the summary of a phaseStat.
+type phaseResult struct {
+	min        float32
+	max        float32
+	average    float32
+	hasResults bool
+}
+
+// add records the duration of a phase in milliseconds.
+func (s *phaseStat) add(ms float32) {
+	if s.count == 0 || ms < s.min {
+		s.min = ms
+	}
+	if s.count == 0 || ms > s.max {
+		s.max = ms
+	}
+	s.count++
+	s.sum += float64(ms)
+}
+
+// result returns the summary of the recorded durations.
+func (s phaseStat) result() phaseResult {
+	if s.count == 0 {
+		return phaseResult{}
+	}
+
+	return phaseResult{
+		min:        s.min,
+		max:        s.max,
+		average:    float32(s.sum / float64(s.count)),
+		hasResults: true,
+	}
+}
+
+// phaseStats keeps the statistics of every phase.
+type phaseStats [phaseCount]phaseStat
+
+// add records the phases of a probe that happened.
+func (s *phaseStats) add(timings phaseTimings) {
+	for p, ms := range timings {
+		if ms > 0 {
+			s[p].add(ms)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseStat(t *testing.T) {
+	var s phaseStat
+	assert.False(t, s.result().hasResults)
+
+	for _, ms := range []float32{4, 1, 7} {
+		s.add(ms)
+	}
+
+	assert.Equal(t, phaseResult{min: 1, max: 7, average: 4, hasResults: true}, s.result())
+}
+
+func TestPhaseStatsSkipsMissingPhases(t *testing.T) {
+	var s phaseStats
+	s.add(phaseTimings{phaseConnect: 2})
+	s.add(phaseTimings{phaseDNS: 5, phaseConnect: 4})
+
+	assert.Equal(t, uint(1), s[phaseDNS].count)
+	assert.Equal(t, uint(2), s[phaseConnect].count)
+	assert.False(t, s[phaseTLS].result().hasResults)
+	assert.False(t, s[phaseFirstByte].result().hasResults)
+}
+
+func TestProbePhases(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	// the lookup is only reported with the probe that follows it
+	stats.pendingDNS = 3
+	tcpProbe(stats)
+	tcpProbe(stats)
+
+	assert.Equal(t, uint(1), stats.phases[phaseDNS].count)
+	assert.Equal(t, float32(3), stats.phases[phaseDNS].result().average)
+	assert.Equal(t, uint(2), stats.phases[phaseConnect].count)
+	assert.Zero(t, stats.pendingDNS)
+}
@@ -0,0 +1,309 @@
+// prometheus.go contains the exporter of the Prometheus metrics of the probes
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPrometheusBuckets are the default upper bounds
+// of the RTT histogram, in milliseconds.
+const defaultPrometheusBuckets = "1,2.5,5,10,25,50,100,250,500,1000,2500,5000"
+
+// prometheusExporter is a sink that exposes probe results of every
+// target on an HTTP "/metrics" endpoint in the Prometheus text format.
+//
+// It is fed from handleConnSuccess, handleConnError and
+// retryResolveHostname and is safe for concurrent use.
+type prometheusExporter struct {
+	mu      sync.Mutex
+	buckets []float64 // buckets are the upper bounds of the RTT histogram in seconds
+	targets map[string]*targetMetrics
+	order   []string // order keeps the targets in the order they were registered
+}
+
+// targetMetrics holds the metrics of a single target.
+type targetMetrics struct {
+	failures       map[errorKind]uint64
+	rttBuckets     []uint64 // rttBuckets are not cumulative, see writeMetrics
+	rttSum         float64
+	rttCount       uint64
+	probes         uint64
+	resolveRetries uint64
+	dnsLookups     uint64
+	dnsFailures    uint64
+	dnsTime        time.Duration
+	uptime         time.Duration
+	downtime       time.Duration
+	up             bool // up is the state declared with --down-after and --up-after, not the result of the last probe
+}
+
+// newPrometheusExporter creates an exporter with the given
+// RTT histogram buckets in milliseconds.
+func newPrometheusExporter(bucketsMs []float64) *prometheusExporter {
+	buckets := make([]float64, len(bucketsMs))
+	for i, b := range bucketsMs {
+		buckets[i] = b / 1000
+	}
+
+	return &prometheusExporter{
+		buckets: buckets,
+		targets: map[string]*targetMetrics{},
+	}
+}
+
+// parsePrometheusBuckets parses a comma-separated list of
+// RTT histogram buckets in milliseconds.
+func parsePrometheusBuckets(s string) ([]float64, error) {
+	var buckets []float64
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		b, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf(msg("prometheus.invalid-bucket"), field)
+		}
+		if b <= 0 {
+			return nil, fmt.Errorf(msg("prometheus.bucket-positive"), field)
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	if len(buckets) == 0 {
+		return nil, msgErr("prometheus.no-buckets")
+	}
+
+	slices.Sort(buckets)
+	return slices.Compact(buckets), nil
+}
+
+// listen starts serving the metrics on addr in the background.
+//
+// The listener is created before returning, so an invalid or
+// already used address is reported right away.
+func (p *prometheusExporter) listen(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", p)
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go server.Serve(ln)
+
+	return nil
+}
+
+// register adds a target, so its metrics are exposed before the first probe.
+func (p *prometheusExporter) register(u userInput) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metrics(u.target())
+}
+
+// metrics returns the metrics of target, creating them when needed.
+// p.mu must be held by the caller.
+func (p *prometheusExporter) metrics(target string) *targetMetrics {
+	m, ok := p.targets[target]
+	if !ok {
+		m = &targetMetrics{
+			failures:   map[errorKind]uint64{},
+			rttBuckets: make([]uint64, len(p.buckets)+1),
+		}
+		p.targets[target] = m
+		p.order = append(p.order, target)
+	}
+
+	return m
+}
+
+// observeSuccess records a successful probe.
+func (p *prometheusExporter) observeSuccess(u userInput, rtt float32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := p.metrics(u.target())
+	m.probes++
+
+	seconds := float64(rtt) / 1000
+	i, _ := slices.BinarySearch(p.buckets, seconds)
+	m.rttBuckets[i]++
+	m.rttSum += seconds
+	m.rttCount++
+}
+
+// observeFailure records a failed probe.
+func (p *prometheusExporter) observeFailure(u userInput, kind errorKind) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := p.metrics(u.target())
+	m.probes++
+	m.failures[kind]++
+}
+
+// observeState records the state of the target after a probe,
+// and the uptime and downtime added since the previous one.
+func (p *prometheusExporter) observeState(u userInput, up bool, uptime, downtime time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := p.metrics(u.target())
+	m.up = up
+	m.uptime += uptime
+	m.downtime += downtime
+}
+
+// exportState sends the state of t and its uptime and downtime to the exporter.
+//
+// Like the printed statistics, the time of a streak that changes the state is moved
+// to the new state. As the counters can't go back, the time of the current streak
+// is only exported once it changed the state, or ended without changing it.
+func (t *tcping) exportState() {
+	uptime, downtime := t.totalUptime, t.totalDowntime
+	if !t.destWasDown && t.consecutiveFailures > 0 {
+		uptime -= t.streakElapsed
+	} else if t.destWasDown && t.consecutiveSuccesses > 0 {
+		downtime -= t.streakElapsed
+	}
+
+	t.exporter.observeState(t.userInput, !t.destWasDown, uptime-t.exportedUptime, downtime-t.exportedDowntime)
+	t.exportedUptime, t.exportedDowntime = uptime, downtime
+}
+
+// observeResolveRetry records a retry of resolving the hostname.
+func (p *prometheusExporter) observeResolveRetry(u userInput) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metrics(u.target()).resolveRetries++
+}
+
+// observeLookup records a lookup of the hostname.
+func (p *prometheusExporter) observeLookup(u userInput, duration time.Duration, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := p.metrics(u.target())
+	m.dnsLookups++
+	m.dnsTime += duration
+	if failed {
+		m.dnsFailures++
+	}
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format.
+func (p *prometheusExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.writeMetrics(w)
+}
+
+// writeMetrics writes every metric family for all targets.
+// p.mu must be held by the caller.
+func (p *prometheusExporter) writeMetrics(w io.Writer) {
+	writeHeader(w, "tcping_probes_total", "counter", "Total number of probes sent.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_probes_total{target=\"%s\"} %d\n", escapeLabelValue(target), p.targets[target].probes)
+	}
+
+	writeHeader(w, "tcping_probe_failures_total", "counter", "Total number of failed probes by reason.")
+	for _, target := range p.order {
+		m := p.targets[target]
+		for _, kind := range errorKinds {
+			fmt.Fprintf(w, "tcping_probe_failures_total{target=\"%s\",reason=\"%s\"} %d\n", escapeLabelValue(target), kind, m.failures[kind])
+		}
+	}
+
+	writeHeader(w, "tcping_up", "gauge", "Whether the target is up (1) or down (0), as declared with --down-after and --up-after.")
+	for _, target := range p.order {
+		up := 0
+		if p.targets[target].up {
+			up = 1
+		}
+		fmt.Fprintf(w, "tcping_up{target=\"%s\"} %d\n", escapeLabelValue(target), up)
+	}
+
+	writeHeader(w, "tcping_uptime_seconds_total", "counter", "Total time the target was up.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_uptime_seconds_total{target=\"%s\"} %s\n", escapeLabelValue(target), formatFloat(p.targets[target].uptime.Seconds()))
+	}
+
+	writeHeader(w, "tcping_downtime_seconds_total", "counter", "Total time the target was down.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_downtime_seconds_total{target=\"%s\"} %s\n", escapeLabelValue(target), formatFloat(p.targets[target].downtime.Seconds()))
+	}
+
+	writeHeader(w, "tcping_hostname_resolve_retries_total", "counter", "Total number of retries to resolve the hostname.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_hostname_resolve_retries_total{target=\"%s\"} %d\n", escapeLabelValue(target), p.targets[target].resolveRetries)
+	}
+
+	writeHeader(w, "tcping_dns_lookups_total", "counter", "Total number of lookups of the hostname.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_dns_lookups_total{target=\"%s\"} %d\n", escapeLabelValue(target), p.targets[target].dnsLookups)
+	}
+
+	writeHeader(w, "tcping_dns_lookup_failures_total", "counter", "Total number of failed lookups of the hostname.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_dns_lookup_failures_total{target=\"%s\"} %d\n", escapeLabelValue(target), p.targets[target].dnsFailures)
+	}
+
+	writeHeader(w, "tcping_dns_lookup_seconds_total", "counter", "Total time spent on lookups of the hostname.")
+	for _, target := range p.order {
+		fmt.Fprintf(w, "tcping_dns_lookup_seconds_total{target=\"%s\"} %s\n", escapeLabelValue(target), formatFloat(p.targets[target].dnsTime.Seconds()))
+	}
+
+	writeHeader(w, "tcping_rtt_seconds", "histogram", "Round-trip time of successful probes.")
+	for _, target := range p.order {
+		m := p.targets[target]
+		label := escapeLabelValue(target)
+
+		var cumulative uint64
+		for i, bound := range p.buckets {
+			cumulative += m.rttBuckets[i]
+			fmt.Fprintf(w, "tcping_rtt_seconds_bucket{target=\"%s\",le=\"%s\"} %d\n", label, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "tcping_rtt_seconds_bucket{target=\"%s\",le=\"+Inf\"} %d\n", label, m.rttCount)
+		fmt.Fprintf(w, "tcping_rtt_seconds_sum{target=\"%s\"} %s\n", label, formatFloat(m.rttSum))
+		fmt.Fprintf(w, "tcping_rtt_seconds_count{target=\"%s\"} %d\n", label, m.rttCount)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric family.
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value as required by the text format.
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+// formatFloat formats a sample value with the shortest exact representation.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
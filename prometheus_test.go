@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrometheusBuckets(t *testing.T) {
+	buckets, err := parsePrometheusBuckets("100, 1,10,10")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 10, 100}, buckets)
+
+	for _, invalid := range []string{"", "a,1", "0", "-5"} {
+		_, err := parsePrometheusBuckets(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPrometheusExporterMetrics(t *testing.T) {
+	exporter := newPrometheusExporter([]float64{10, 100})
+
+	u := userInput{
+		hostname: "example.com",
+		ip:       netip.MustParseAddr("127.0.0.1"),
+		port:     443,
+	}
+	idle := userInput{
+		ip:   netip.MustParseAddr("127.0.0.2"),
+		port: 22,
+	}
+
+	exporter.register(u)
+	exporter.register(idle)
+
+	exporter.observeSuccess(u, 5)
+	exporter.observeSuccess(u, 10)
+	exporter.observeSuccess(u, 50)
+	exporter.observeSuccess(u, 500)
+	exporter.observeState(u, true, 4*time.Second, 0)
+	exporter.observeFailure(u, errorKindRefused)
+	exporter.observeState(u, false, 0, 2*time.Second)
+	exporter.observeResolveRetry(u)
+	exporter.observeLookup(u, 20*time.Millisecond, false)
+	exporter.observeLookup(u, 30*time.Millisecond, true)
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	expected := []string{
+		"# TYPE tcping_probes_total counter",
+		`tcping_probes_total{target="example.com:443"} 5`,
+		`tcping_probes_total{target="127.0.0.2:22"} 0`,
+		`tcping_probe_failures_total{target="example.com:443",reason="refused"} 1`,
+		`tcping_probe_failures_total{target="example.com:443",reason="timeout"} 0`,
+		`tcping_up{target="example.com:443"} 0`,
+		`tcping_uptime_seconds_total{target="example.com:443"} 4`,
+		`tcping_downtime_seconds_total{target="example.com:443"} 2`,
+		`tcping_hostname_resolve_retries_total{target="example.com:443"} 1`,
+		`tcping_dns_lookups_total{target="example.com:443"} 2`,
+		`tcping_dns_lookup_failures_total{target="example.com:443"} 1`,
+		`tcping_dns_lookup_seconds_total{target="example.com:443"} 0.05`,
+		"# TYPE tcping_rtt_seconds histogram",
+		`tcping_rtt_seconds_bucket{target="example.com:443",le="0.01"} 2`,
+		`tcping_rtt_seconds_bucket{target="example.com:443",le="0.1"} 3`,
+		`tcping_rtt_seconds_bucket{target="example.com:443",le="+Inf"} 4`,
+		`tcping_rtt_seconds_count{target="example.com:443"} 4`,
+	}
+	for _, line := range expected {
+		assert.Contains(t, body, line+"\n")
+	}
+
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"))
+}
+
+func TestPrometheusExporterDeclaredState(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.downAfter = 3
+	stats.exporter = newPrometheusExporter(nil)
+
+	metrics := func() string {
+		recorder := httptest.NewRecorder()
+		stats.exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return recorder.Body.String()
+	}
+
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+
+	// the target is still up, and the time of the failures is held back until the state is known
+	body := metrics()
+	assert.Contains(t, body, `tcping_up{target="127.0.0.1:12345"} 1`+"\n")
+	assert.Contains(t, body, `tcping_uptime_seconds_total{target="127.0.0.1:12345"} 1`+"\n")
+	assert.Contains(t, body, `tcping_downtime_seconds_total{target="127.0.0.1:12345"} 0`+"\n")
+
+	// the target is down since the first failure, as in the printed statistics
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	body = metrics()
+	assert.Contains(t, body, `tcping_up{target="127.0.0.1:12345"} 0`+"\n")
+	assert.Contains(t, body, `tcping_uptime_seconds_total{target="127.0.0.1:12345"} 1`+"\n")
+	assert.Contains(t, body, `tcping_downtime_seconds_total{target="127.0.0.1:12345"} 3`+"\n")
+	assert.Equal(t, 3*time.Second, stats.totalDowntime)
+
+	// a single success doesn't bring it back up with --up-after 2
+	stats.userInput.upAfter = 2
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	body = metrics()
+	assert.Contains(t, body, `tcping_up{target="127.0.0.1:12345"} 0`+"\n")
+	assert.Contains(t, body, `tcping_downtime_seconds_total{target="127.0.0.1:12345"} 3`+"\n")
+
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	body = metrics()
+	assert.Contains(t, body, `tcping_up{target="127.0.0.1:12345"} 1`+"\n")
+	assert.Contains(t, body, `tcping_uptime_seconds_total{target="127.0.0.1:12345"} 3`+"\n")
+	assert.Contains(t, body, `tcping_downtime_seconds_total{target="127.0.0.1:12345"} 3`+"\n")
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+}
@@ -0,0 +1,641 @@
+// report.go contains the report subcommand, analyzing the probes saved by --db or --csv
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+const (
+	// sqliteHeader is the magic string at the start of every SQLite database file.
+	sqliteHeader = "SQLite format 3\x00"
+	// histogramWidth is the width of the largest bar of the RTT histogram, in characters.
+	histogramWidth = 40
+)
+
+// reportSession is a probing session saved by --db or --csv.
+// A table of the database made by newTableName, or a whole CSV file, is a session.
+type reportSession struct {
+	Name   string     `json:"name"`
+	Target string     `json:"target"`
+	Probes uint       `json:"probes"`
+	Start  *time.Time `json:"start,omitempty"`
+	End    *time.Time `json:"end,omitempty"`
+}
+
+// reportProbe is a saved probe.
+type reportProbe struct {
+	time      time.Time // time is zero if the session has no timestamps
+	hostname  string
+	addr      string
+	port      uint16
+	success   bool
+	rtt       float32
+	errorKind errorKind
+}
+
+// outage is a period in which every probe of a session failed.
+type outage struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Duration is in seconds.
+	Duration float64 `json:"duration"`
+	Probes   uint    `json:"probes"`
+	// Ongoing is true when the session ended during the outage.
+	Ongoing bool `json:"ongoing,omitempty"`
+}
+
+// hourlyAvailability is the share of successful probes within an hour.
+type hourlyAvailability struct {
+	Hour             time.Time `json:"hour"`
+	Probes           uint      `json:"probes"`
+	SuccessfulProbes uint      `json:"successful_probes"`
+	// Availability is in percent.
+	Availability float64 `json:"availability"`
+}
+
+// histogramBucket counts the RTTs up to its upper bound
+// and above the upper bound of the previous bucket.
+type histogramBucket struct {
+	// Le is the upper bound in ms, "+Inf" for the last bucket.
+	Le         string  `json:"le"`
+	Count      uint    `json:"count"`
+	upperBound float64 // upperBound is math.Inf(1) for the last bucket
+}
+
+// sessionReport is the analysis of a saved session.
+type sessionReport struct {
+	session   reportSession
+	stats     tcping // stats are the recomputed statistics, printed through printStatistics
+	outages   []outage
+	hourly    []hourlyAvailability
+	histogram []histogramBucket
+	timed     bool // timed is false if the probes have no timestamps, so that only the histogram is known
+}
+
+// report runs the report subcommand, started through `tcping report`.
+func report(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	outputJSON := flags.Bool("j", false, msg("flag.j"))
+	prettyJSON := flags.Bool("pretty", false, msg("flag.pretty"))
+	noColor := flags.Bool("no-color", false, msg("flag.no-color"))
+	sessionName := flags.String("session", "", msg("flag.report.session"))
+	buckets := flags.String("buckets", defaultPrometheusBuckets, msg("flag.report.buckets"))
+	htmlReport := flags.String("html", "", msg("flag.report.html"))
+	flags.String("lang", "", msg("flag.lang"))
+
+	// flags may follow the file, as in `tcping report tcping.db -j`
+	var files []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	showTimestamp := false
+	var p printer
+	switch {
+	case *outputJSON:
+		p = newJSONPrinter(*prettyJSON)
+	case *noColor:
+		p = newPlainPrinter(&showTimestamp)
+	default:
+		p = newColorPrinter(&showTimestamp)
+	}
+
+	if len(files) != 1 {
+		p.printError(msg("report.usage"))
+		os.Exit(1)
+	}
+
+	bounds, err := parsePrometheusBuckets(*buckets)
+	if err != nil {
+		p.printError(msg("report.invalid-buckets"), err)
+		os.Exit(1)
+	}
+
+	sessions, err := readSessions(files[0])
+	if err != nil {
+		p.printError(msg("report.read-failed"), files[0], err)
+		os.Exit(1)
+	}
+
+	session, err := chooseSession(sessions, *sessionName)
+	if err != nil {
+		p.printError("%s", err)
+		os.Exit(1)
+	}
+	if session == nil {
+		p.printSessions(files[0], sessions)
+		return
+	}
+
+	probes, err := readProbes(files[0], *session)
+	if err != nil {
+		p.printError(msg("report.read-session-failed"), session.Name, err)
+		os.Exit(1)
+	}
+
+	r := newSessionReport(*session, probes, bounds)
+	p.printStatistics(r.stats)
+	p.printReport(r)
+
+	if *htmlReport != "" {
+		target := newHTMLTarget(r.stats, probes, r.outages)
+		target.Title = fmt.Sprintf("%s (%s)", target.Title, session.Name)
+		if err := writeHTMLReport(*htmlReport, []htmlTarget{target}); err != nil {
+			p.printError(msg("html.write-failed"), err)
+			os.Exit(1)
+		}
+		p.printInfo(msg("html.written"), *htmlReport)
+	}
+}
+
+// chooseSession returns the session called name, or the only session
+// of the file if no name is given. It returns nil if the file has
+// several sessions and no name is given, so that they are listed instead.
+func chooseSession(sessions []reportSession, name string) (*reportSession, error) {
+	if name == "" {
+		if len(sessions) == 1 {
+			return &sessions[0], nil
+		}
+		if len(sessions) == 0 {
+			return nil, msgErr("report.no-sessions")
+		}
+		return nil, nil
+	}
+
+	for i := range sessions {
+		if sessions[i].Name == name {
+			return &sessions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf(msg("report.session-not-found"), name)
+}
+
+// isSQLiteFile reports whether the file at path is an SQLite database.
+func isSQLiteFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(file, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return bytes.Equal(header, []byte(sqliteHeader)), nil
+}
+
+// readSessions returns the sessions of a database or CSV file.
+func readSessions(path string) ([]reportSession, error) {
+	isDB, err := isSQLiteFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isDB {
+		return readDBSessions(path)
+	}
+
+	probes, err := readCSVProbes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return []reportSession{newReportSession(filepath.Base(path), probes)}, nil
+}
+
+// readProbes returns the probes of a session of a database or CSV file.
+func readProbes(path string, session reportSession) ([]reportProbe, error) {
+	isDB, err := isSQLiteFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isDB {
+		return readDBProbes(path, session.Name)
+	}
+
+	return readCSVProbes(path)
+}
+
+// newReportSession describes the session called name from its probes.
+func newReportSession(name string, probes []reportProbe) reportSession {
+	session := reportSession{
+		Name:   name,
+		Probes: uint(len(probes)),
+	}
+
+	if len(probes) > 0 {
+		session.Target = reportTarget(probes[0])
+
+		if start, end := probes[0].time, probes[len(probes)-1].time; !start.IsZero() {
+			session.Start = &start
+			session.End = &end
+		}
+	}
+
+	return session
+}
+
+// reportTarget returns the "host:port" label of the target of a probe.
+func reportTarget(probe reportProbe) string {
+	return reportUserInput(probe).target()
+}
+
+// openReportDB opens a database saved by --db for reading.
+func openReportDB(path string) (*sqlite.Conn, error) {
+	return sqlite.OpenConn(path, sqlite.OpenReadOnly)
+}
+
+// readDBSessions returns a session for every table of the database.
+func readDBSessions(path string) ([]reportSession, error) {
+	conn, err := openReportDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var tables []string
+	err = sqlitex.Execute(conn, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name", &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			tables = append(tables, stmt.ColumnText(0))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// %[1]s will be replaced by the table name
+	query := `SELECT
+	COUNT(*),
+	IFNULL(MIN(timestamp), ''),
+	IFNULL(MAX(timestamp), ''),
+	IFNULL((SELECT hostname FROM %[1]s WHERE event_type = ? LIMIT 1), ''),
+	IFNULL((SELECT addr FROM %[1]s WHERE event_type = ? LIMIT 1), ''),
+	IFNULL((SELECT port FROM %[1]s WHERE event_type = ? LIMIT 1), 0)
+	FROM %[1]s WHERE event_type = ?`
+
+	sessions := make([]reportSession, 0, len(tables))
+	for _, table := range tables {
+		session := reportSession{Name: table}
+		err := sqlitex.Execute(conn, fmt.Sprintf(query, table), &sqlitex.ExecOptions{
+			Args: []interface{}{eventTypeProbe, eventTypeProbe, eventTypeProbe, eventTypeProbe},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				session.Probes = uint(stmt.ColumnInt64(0))
+				if start, err := parseReportTime(stmt.ColumnText(1)); err == nil {
+					end, _ := parseReportTime(stmt.ColumnText(2))
+					session.Start = &start
+					session.End = &end
+				}
+				session.Target = reportTarget(reportProbe{
+					hostname: stmt.ColumnText(3),
+					addr:     stmt.ColumnText(4),
+					port:     uint16(stmt.ColumnInt64(5)),
+				})
+				return nil
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf(msg("report.table"), table, err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// readDBProbes returns the probes saved in a table of the database.
+// The table should be one returned by readDBSessions.
+func readDBProbes(path, table string) ([]reportProbe, error) {
+	conn, err := openReportDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// %s will be replaced by the table name
+	query := `SELECT timestamp, success, IFNULL(latency, 0), IFNULL(error_kind, ''),
+	IFNULL(hostname, ''), IFNULL(addr, ''), IFNULL(port, 0)
+	FROM %s WHERE event_type = ? ORDER BY id`
+
+	var probes []reportProbe
+	err = sqlitex.Execute(conn, fmt.Sprintf(query, table), &sqlitex.ExecOptions{
+		Args: []interface{}{eventTypeProbe},
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			probeTime, err := parseReportTime(stmt.ColumnText(0))
+			if err != nil {
+				return err
+			}
+
+			probes = append(probes, reportProbe{
+				time:      probeTime,
+				success:   stmt.ColumnBool(1),
+				rtt:       float32(stmt.ColumnFloat(2)),
+				errorKind: errorKind(stmt.ColumnText(3)),
+				hostname:  stmt.ColumnText(4),
+				addr:      stmt.ColumnText(5),
+				port:      uint16(stmt.ColumnInt64(6)),
+			})
+			return nil
+		},
+	})
+
+	return probes, err
+}
+
+// parseReportTime parses a timestamp saved with timeFormat in the local time zone.
+func parseReportTime(s string) (time.Time, error) {
+	return time.ParseInLocation(timeFormat, s, time.Local)
+}
+
+// readCSVProbes returns the probes of a CSV file saved by --csv.
+// Records other than replies, e.g. hostname resolutions, are skipped.
+// The probes have no time unless the file was saved with -D.
+func readCSVProbes(path string) ([]reportProbe, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	// records other than probes have fewer fields
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf(msg("report.csv-header"), err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{colStatus, colIP, colPort, colLatency} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf(msg("report.csv-column"), name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var probes []reportProbe
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		status := field(record, colStatus)
+		if status != "Reply" && status != "No reply" {
+			continue
+		}
+
+		probe := reportProbe{
+			hostname:  field(record, colHostname),
+			addr:      field(record, colIP),
+			success:   status == "Reply",
+			errorKind: errorKind(field(record, colErrorKind)),
+		}
+
+		port, err := strconv.ParseUint(field(record, colPort), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf(msg("report.csv-port"), line, err)
+		}
+		probe.port = uint16(port)
+
+		if probe.success {
+			rtt, err := strconv.ParseFloat(field(record, colLatency), 32)
+			if err != nil {
+				return nil, fmt.Errorf(msg("report.csv-latency"), line, err)
+			}
+			probe.rtt = float32(rtt)
+		}
+
+		if timestamp := field(record, colTimestamp); timestamp != "" {
+			if probe.time, err = parseReportTime(timestamp); err != nil {
+				return nil, fmt.Errorf(msg("report.csv-line"), line, err)
+			}
+		}
+
+		probes = append(probes, probe)
+	}
+
+	return probes, nil
+}
+
+// newSessionReport recomputes the statistics of a session from its probes,
+// and finds its outages, its hourly availability and its RTT histogram.
+func newSessionReport(session reportSession, probes []reportProbe, bounds []float64) sessionReport {
+	r := sessionReport{
+		session:   session,
+		histogram: newHistogram(bounds),
+		timed:     len(probes) > 0 && !probes[0].time.IsZero(),
+	}
+
+	if len(probes) > 0 {
+		r.stats.userInput = reportUserInput(probes[0])
+		r.stats.destIsIP = r.stats.userInput.hostname == r.stats.userInput.ip.String()
+		r.stats.hostnameChanges = []hostnameChange{{r.stats.userInput.ip, probes[0].time}}
+	}
+
+	hours := map[time.Time]*hourlyAvailability{}
+	// runStart is the first probe of the current run of successes or failures
+	runStart := 0
+
+	for i, probe := range probes {
+		r.addHostnameChange(probe)
+
+		if probe.success {
+			r.stats.totalSuccessfulProbes++
+			r.stats.lastSuccessfulProbe = probe.time
+			r.stats.rtt.add(probe.rtt)
+			addToHistogram(r.histogram, probe.rtt)
+		} else {
+			if r.stats.failuresByKind == nil {
+				r.stats.failuresByKind = map[errorKind]uint{}
+			}
+			r.stats.totalUnsuccessfulProbes++
+			r.stats.lastUnsuccessfulProbe = probe.time
+			r.stats.failuresByKind[probe.errorKind]++
+		}
+
+		if r.timed {
+			hour := probe.time.Truncate(time.Hour)
+			h, ok := hours[hour]
+			if !ok {
+				h = &hourlyAvailability{Hour: hour}
+				hours[hour] = h
+				r.hourly = append(r.hourly, *h)
+			}
+			h.Probes++
+			if probe.success {
+				h.SuccessfulProbes++
+			}
+		}
+
+		// a run ends with the last probe, or when the next probe has another result
+		last := i == len(probes)-1
+		if last || probes[i+1].success != probe.success {
+			r.addRun(probes[runStart:i+1], probes[min(i+1, len(probes)-1)], last)
+			runStart = i + 1
+			if !last {
+				r.stats.stateChanges++
+			}
+		}
+	}
+
+	for i := range r.hourly {
+		h := hours[r.hourly[i].Hour]
+		h.Availability = float64(h.SuccessfulProbes) / float64(h.Probes) * 100
+		r.hourly[i] = *h
+	}
+
+	if len(probes) > 0 {
+		r.stats.destWasDown = !probes[len(probes)-1].success
+	}
+	if r.timed {
+		r.stats.startTime = probes[0].time
+		r.stats.endTime = probes[len(probes)-1].time
+	}
+	r.stats.rttResults = r.stats.rtt.result()
+
+	return r
+}
+
+// reportUserInput returns the target of a probe in the form of the command line arguments.
+func reportUserInput(probe reportProbe) userInput {
+	u := userInput{
+		hostname: probe.hostname,
+		port:     probe.port,
+	}
+
+	if ip, err := netip.ParseAddr(probe.addr); err == nil {
+		u.ip = ip
+	}
+	if u.hostname == "" {
+		u.hostname = probe.addr
+	}
+
+	return u
+}
+
+// addHostnameChange records a change of the address the hostname resolved to.
+func (r *sessionReport) addHostnameChange(probe reportProbe) {
+	addr, err := netip.ParseAddr(probe.addr)
+	if r.stats.destIsIP || err != nil {
+		return
+	}
+
+	if last := r.stats.hostnameChanges[len(r.stats.hostnameChanges)-1]; last.Addr != addr {
+		r.stats.hostnameChanges = append(r.stats.hostnameChanges, hostnameChange{addr, probe.time})
+	}
+}
+
+// addRun adds a run of successful or failed probes to the uptime or downtime.
+//
+// The run lasts until next, the first probe of the following run,
+// or until its own last probe if it's the last run of the session.
+func (r *sessionReport) addRun(run []reportProbe, next reportProbe, last bool) {
+	if !r.timed {
+		return
+	}
+
+	start := run[0].time
+	end := next.time
+	if last {
+		end = run[len(run)-1].time
+	}
+	duration := end.Sub(start)
+
+	if run[0].success {
+		r.stats.totalUptime += duration
+		if duration > r.stats.longestUptime.duration {
+			r.stats.longestUptime = newLongestTime(start, duration)
+		}
+		return
+	}
+
+	r.stats.totalDowntime += duration
+	if duration > r.stats.longestDowntime.duration {
+		r.stats.longestDowntime = newLongestTime(start, duration)
+	}
+
+	r.outages = append(r.outages, outage{
+		Start:    start,
+		End:      end,
+		Duration: duration.Seconds(),
+		Probes:   uint(len(run)),
+		Ongoing:  last,
+	})
+}
+
+// newHistogram creates an empty histogram with the given upper bounds and +Inf.
+func newHistogram(bounds []float64) []histogramBucket {
+	histogram := make([]histogramBucket, 0, len(bounds)+1)
+	for _, b := range bounds {
+		histogram = append(histogram, histogramBucket{Le: formatFloat(b), upperBound: b})
+	}
+	return append(histogram, histogramBucket{Le: "+Inf", upperBound: math.Inf(1)})
+}
+
+// addToHistogram counts rtt in the first bucket whose upper bound is not below it.
+func addToHistogram(histogram []histogramBucket, rtt float32) {
+	for i := range histogram {
+		if float64(rtt) <= histogram[i].upperBound {
+			histogram[i].Count++
+			return
+		}
+	}
+}
+
+// histogramBar returns a bar of up to width characters for count,
+// relative to the largest count of the histogram.
+func histogramBar(count, largest uint, width int) string {
+	if largest == 0 {
+		return ""
+	}
+	return strings.Repeat("#", int(math.Ceil(float64(count)/float64(largest)*float64(width))))
+}
+
+// largestCount returns the largest count of the histogram.
+func largestCount(histogram []histogramBucket) uint {
+	var largest uint
+	for _, b := range histogram {
+		largest = max(largest, b.Count)
+	}
+	return largest
+}
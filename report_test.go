@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReportCSV = `Status,Hostname,IP,Port,TCP_Conn,Latency(ms),Error Kind,DNS(ms),Connect(ms),Timestamp
+Resolving,example.com,,,,,,2.000,,2026-01-01 10:59:58
+Reply,example.com,192.0.2.1,443,1,3.000,,,3.000,2026-01-01 10:59:59
+No reply,example.com,192.0.2.1,443,1,,timeout,,,2026-01-01 11:00:00
+No reply,example.com,192.0.2.1,443,2,,refused,,,2026-01-01 11:00:01
+Reply,example.com,192.0.2.1,443,1,30.000,,,30.000,2026-01-01 11:00:03
+No reply,example.com,192.0.2.1,443,1,,timeout,,,2026-01-01 11:00:04
+`
+
+// newTestProbes creates a probe per result, one second apart.
+func newTestProbes(start time.Time, results ...bool) []reportProbe {
+	probes := make([]reportProbe, len(results))
+	for i, success := range results {
+		probes[i] = reportProbe{
+			time:    start.Add(time.Duration(i) * time.Second),
+			addr:    "192.0.2.1",
+			port:    443,
+			success: success,
+		}
+		if success {
+			probes[i].rtt = 10
+		} else {
+			probes[i].errorKind = errorKindTimeout
+		}
+	}
+	return probes
+}
+
+func TestReadCSVProbes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tcping.csv")
+	require.NoError(t, os.WriteFile(path, []byte(testReportCSV), 0o644))
+
+	probes, err := readCSVProbes(path)
+	require.NoError(t, err)
+	require.Len(t, probes, 5)
+
+	assert.Equal(t, "example.com", probes[0].hostname)
+	assert.Equal(t, "192.0.2.1", probes[0].addr)
+	assert.Equal(t, uint16(443), probes[0].port)
+	assert.True(t, probes[0].success)
+	assert.Equal(t, float32(3), probes[0].rtt)
+	assert.Equal(t, time.Date(2026, 1, 1, 10, 59, 59, 0, time.Local), probes[0].time)
+
+	assert.False(t, probes[2].success)
+	assert.Equal(t, errorKindRefused, probes[2].errorKind)
+
+	sessions, err := readSessions(path)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "tcping.csv", sessions[0].Name)
+	assert.Equal(t, "example.com:443", sessions[0].Target)
+	assert.Equal(t, uint(5), sessions[0].Probes)
+	assert.Equal(t, probes[4].time, *sessions[0].End)
+}
+
+func TestReadCSVProbesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n"), 0o644))
+
+	_, err := readSessions(path)
+	assert.Error(t, err)
+}
+
+func TestSessionReport(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 59, 58, 0, time.Local)
+	probes := newTestProbes(start, true, true, false, false, true, false)
+	probes[1].rtt = 100
+
+	r := newSessionReport(reportSession{Name: "test"}, probes, []float64{5, 50})
+
+	assert.Equal(t, uint(3), r.stats.totalSuccessfulProbes)
+	assert.Equal(t, uint(3), r.stats.totalUnsuccessfulProbes)
+	assert.Equal(t, uint(3), r.stats.failuresByKind[errorKindTimeout])
+	assert.Equal(t, 3*time.Second, r.stats.totalUptime)
+	assert.Equal(t, 2*time.Second, r.stats.totalDowntime)
+	assert.Equal(t, 2*time.Second, r.stats.longestUptime.duration)
+	assert.Equal(t, 2*time.Second, r.stats.longestDowntime.duration)
+	assert.Equal(t, uint(3), r.stats.stateChanges)
+	assert.Equal(t, stateDown, r.stats.state())
+	assert.Equal(t, start, r.stats.startTime)
+	assert.Equal(t, start.Add(5*time.Second), r.stats.endTime)
+	assert.Equal(t, float32(40), r.stats.rttResults.average)
+	assert.True(t, r.stats.destIsIP)
+
+	assert.Equal(t, []outage{
+		{Start: start.Add(2 * time.Second), End: start.Add(4 * time.Second), Duration: 2, Probes: 2},
+		{Start: start.Add(5 * time.Second), End: start.Add(5 * time.Second), Duration: 0, Probes: 1, Ongoing: true},
+	}, r.outages)
+
+	hour := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	assert.Equal(t, []hourlyAvailability{
+		{Hour: hour, Probes: 2, SuccessfulProbes: 2, Availability: 100},
+		{Hour: hour.Add(time.Hour), Probes: 4, SuccessfulProbes: 1, Availability: 25},
+	}, r.hourly)
+
+	require.Len(t, r.histogram, 3)
+	assert.Equal(t, []string{"5", "50", "+Inf"}, []string{r.histogram[0].Le, r.histogram[1].Le, r.histogram[2].Le})
+	assert.Equal(t, []uint{0, 2, 1}, []uint{r.histogram[0].Count, r.histogram[1].Count, r.histogram[2].Count})
+}
+
+func TestSessionReportWithoutTimestamps(t *testing.T) {
+	probes := newTestProbes(time.Time{}, true, false, true)
+	for i := range probes {
+		probes[i].time = time.Time{}
+	}
+
+	r := newSessionReport(reportSession{Name: "test"}, probes, []float64{10})
+
+	assert.False(t, r.timed)
+	assert.Empty(t, r.outages)
+	assert.Empty(t, r.hourly)
+	assert.Zero(t, r.stats.totalUptime)
+	assert.Equal(t, uint(2), r.stats.totalSuccessfulProbes)
+	assert.Equal(t, uint(2), r.histogram[0].Count)
+}
+
+func TestChooseSession(t *testing.T) {
+	sessions := []reportSession{{Name: "a"}, {Name: "b"}}
+
+	s, err := chooseSession(sessions, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", s.Name)
+
+	s, err = chooseSession(sessions, "")
+	assert.NoError(t, err)
+	assert.Nil(t, s, "several sessions should be listed")
+
+	s, err = chooseSession(sessions[:1], "")
+	require.NoError(t, err)
+	assert.Equal(t, "a", s.Name)
+
+	_, err = chooseSession(sessions, "c; DROP TABLE a")
+	assert.Error(t, err)
+
+	_, err = chooseSession(nil, "")
+	assert.Error(t, err)
+}
+
+func TestHistogramBar(t *testing.T) {
+	assert.Equal(t, "", histogramBar(0, 0, 10))
+	assert.Equal(t, "##########", histogramBar(8, 8, 10))
+	assert.Equal(t, "###", histogramBar(2, 8, 10))
+	assert.Equal(t, "", histogramBar(0, 8, 10))
+}
+
+func TestSessionReportHostnameChanges(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	probes := newTestProbes(start, true, true, true)
+	for i := range probes {
+		probes[i].hostname = "example.com"
+	}
+	probes[2].addr = "192.0.2.2"
+
+	r := newSessionReport(reportSession{}, probes, nil)
+
+	assert.False(t, r.stats.destIsIP)
+	assert.Equal(t, []hostnameChange{
+		{netip.MustParseAddr("192.0.2.1"), start},
+		{netip.MustParseAddr("192.0.2.2"), start.Add(2 * time.Second)},
+	}, r.stats.hostnameChanges)
+}
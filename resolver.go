@@ -0,0 +1,496 @@
+// resolver.go contains the DNS client of --resolver, querying a DNS server
+// over UDP, TCP, TLS (DoT) or HTTPS (DoH) and reporting the TTLs of the records
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDNSTimeout is the default timeout of a hostname lookup, in seconds.
+	defaultDNSTimeout = 2
+
+	// maxDNSMessageSize is the size of the buffer a DNS response is read into.
+	maxDNSMessageSize = 64 * 1024
+
+	// dnsMessageType is the media type of DNS messages sent over HTTPS, see RFC 8484.
+	dnsMessageType = "application/dns-message"
+)
+
+// DNS record types, classes and response codes used by the DNS client.
+const (
+	dnsTypeA     uint16 = 1
+	dnsTypeCNAME uint16 = 5
+	dnsTypeAAAA  uint16 = 28
+	dnsTypeSRV   uint16 = 33
+	dnsClassIN   uint16 = 1
+
+	dnsFlagResponse  = 0x8000
+	dnsFlagTruncated = 0x0200
+	dnsRcodeMask     = 0x000f
+	dnsRcodeNXDomain = 3
+)
+
+// dnsTransport is the protocol used to reach the DNS server of --resolver.
+type dnsTransport string
+
+const (
+	dnsOverUDP   dnsTransport = "udp"
+	dnsOverTCP   dnsTransport = "tcp"
+	dnsOverTLS   dnsTransport = "tls"
+	dnsOverHTTPS dnsTransport = "https"
+)
+
+// defaultDNSPorts are the ports of the transports, when --resolver has none.
+var defaultDNSPorts = map[dnsTransport]string{
+	dnsOverUDP: "53",
+	dnsOverTCP: "53",
+	dnsOverTLS: "853",
+}
+
+// resolver is the DNS server of --resolver.
+type resolver struct {
+	transport dnsTransport
+	addr      string       // addr is the "host:port" of the server, unused over HTTPS
+	url       string       // url is the URL of the server over HTTPS
+	client    *http.Client // client sends the queries over HTTPS
+}
+
+// parseResolver parses the server of --resolver, e.g. "1.1.1.1", "tcp://1.1.1.1:53",
+// "tls://dns.google" or "https://dns.google/dns-query".
+// A server without a scheme is queried over UDP.
+func parseResolver(s string) (*resolver, error) {
+	// a bare IPv6 address can't be parsed as a URL
+	if ip, err := netip.ParseAddr(s); err == nil {
+		return &resolver{transport: dnsOverUDP, addr: net.JoinHostPort(ip.String(), defaultDNSPorts[dnsOverUDP])}, nil
+	}
+
+	if !strings.Contains(s, "://") {
+		s = string(dnsOverUDP) + "://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf(msg("dns.no-server"), s)
+	}
+
+	r := &resolver{transport: dnsTransport(strings.ToLower(u.Scheme))}
+	switch r.transport {
+	case dnsOverHTTPS:
+		r.url = u.String()
+		r.client = &http.Client{}
+	case dnsOverUDP, dnsOverTCP, dnsOverTLS:
+		if u.Path != "" && u.Path != "/" {
+			return nil, fmt.Errorf(msg("dns.unexpected-path"), s)
+		}
+
+		port := u.Port()
+		if port == "" {
+			port = defaultDNSPorts[r.transport]
+		}
+		r.addr = net.JoinHostPort(u.Hostname(), port)
+	default:
+		return nil, fmt.Errorf(msg("dns.unsupported-scheme"), u.Scheme)
+	}
+
+	return r, nil
+}
+
+// String returns the server with its transport, e.g. "udp://1.1.1.1:53".
+func (r *resolver) String() string {
+	if r.transport == dnsOverHTTPS {
+		return r.url
+	}
+	return string(r.transport) + "://" + r.addr
+}
+
+// dnsRecord is a record of the answer to a query.
+type dnsRecord struct {
+	rtype uint16
+	ttl   uint32     // ttl is in seconds
+	addr  netip.Addr // addr is only set for A and AAAA records
+	srv   srvRecord  // srv is only set for SRV records
+}
+
+// lookupNetIP resolves the A and AAAA records of host and returns the addresses
+// and the lowest TTL of the records they were found through.
+func (r *resolver) lookupNetIP(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+	qtypes := []uint16{dnsTypeA, dnsTypeAAAA}
+	records := make([][]dnsRecord, len(qtypes))
+	errs := make([]error, len(qtypes))
+
+	var wg sync.WaitGroup
+	for i, qtype := range qtypes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			records[i], errs[i] = r.query(ctx, host, qtype)
+		}()
+	}
+	wg.Wait()
+
+	var addrs []netip.Addr
+	ttl := uint32(math.MaxUint32)
+	for i := range qtypes {
+		for _, record := range records[i] {
+			ttl = min(ttl, record.ttl)
+			if record.addr.IsValid() {
+				addrs = append(addrs, record.addr)
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return nil, 0, msgErr("dns.no-addresses")
+	}
+
+	return addrs, time.Duration(ttl) * time.Second, nil
+}
+
+// lookupSRV resolves the SRV records of name and returns them with their lowest TTL.
+func (r *resolver) lookupSRV(ctx context.Context, name string) ([]srvRecord, time.Duration, error) {
+	records, err := r.query(ctx, name, dnsTypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var srvs []srvRecord
+	ttl := uint32(math.MaxUint32)
+	for _, record := range records {
+		ttl = min(ttl, record.ttl)
+		if record.rtype == dnsTypeSRV {
+			srvs = append(srvs, record.srv)
+		}
+	}
+
+	if len(srvs) == 0 {
+		return nil, 0, nil
+	}
+
+	return srvs, time.Duration(ttl) * time.Second, nil
+}
+
+// query asks the server for the records of type qtype of name.
+// A truncated response over UDP is asked for again over TCP.
+func (r *resolver) query(ctx context.Context, name string, qtype uint16) ([]dnsRecord, error) {
+	// the ID of queries over HTTPS should be 0, so that responses can be cached
+	var id uint16
+	if r.transport != dnsOverHTTPS {
+		id = uint16(rand.Intn(math.MaxUint16 + 1))
+	}
+
+	query, err := newDNSQuery(id, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []byte
+	switch r.transport {
+	case dnsOverHTTPS:
+		response, err = r.exchangeHTTPS(ctx, query)
+	case dnsOverUDP:
+		response, err = r.exchangeUDP(ctx, query)
+	default:
+		response, err = r.exchangeStream(ctx, r.transport, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records, truncated, err := parseDNSResponse(response, id, qtype)
+	if truncated && r.transport == dnsOverUDP {
+		if response, err = r.exchangeStream(ctx, dnsOverTCP, query); err != nil {
+			return nil, err
+		}
+		records, _, err = parseDNSResponse(response, id, qtype)
+	}
+
+	return records, err
+}
+
+// exchangeUDP sends a query over UDP and returns the response with the same ID.
+func (r *resolver) exchangeUDP(ctx context.Context, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", r.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, maxDNSMessageSize)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return nil, err
+		}
+
+		// late responses to other queries are ignored
+		if n >= 2 && bytes.Equal(response[:2], query[:2]) {
+			return response[:n], nil
+		}
+	}
+}
+
+// exchangeStream sends a query over TCP, or TLS, prefixed by its length as in RFC 1035
+// and returns the response.
+func (r *resolver) exchangeStream(ctx context.Context, transport dnsTransport, query []byte) ([]byte, error) {
+	var conn net.Conn
+	var err error
+
+	d := &net.Dialer{}
+	if transport == dnsOverTLS {
+		host, _, _ := net.SplitHostPort(r.addr)
+		tlsDialer := &tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", r.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	message := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(query)), uint16(len(query)))
+	if _, err := conn.Write(append(message, query...)); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// exchangeHTTPS sends a query over HTTPS as in RFC 8484 and returns the response.
+func (r *resolver) exchangeHTTPS(ctx context.Context, query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(msg("dns.http-status"), resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxDNSMessageSize))
+}
+
+// newDNSQuery creates a recursive query for the records of type qtype of name.
+func newDNSQuery(id uint16, name string, qtype uint16) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return nil, fmt.Errorf(msg("dns.invalid-name"), name)
+	}
+
+	query := binary.BigEndian.AppendUint16(nil, id)
+	query = append(query,
+		0x01, 0x00, // flags: standard query, recursion desired
+		0x00, 0x01, // QDCOUNT
+		0x00, 0x00, // ANCOUNT
+		0x00, 0x00, // NSCOUNT
+		0x00, 0x00, // ARCOUNT
+	)
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf(msg("dns.invalid-name"), name)
+		}
+		query = append(query, byte(len(label)))
+		query = append(query, label...)
+	}
+	query = append(query, 0x00)
+
+	query = binary.BigEndian.AppendUint16(query, qtype)
+	query = binary.BigEndian.AppendUint16(query, dnsClassIN)
+
+	return query, nil
+}
+
+// parseDNSResponse returns the records of type qtype in the answer of a response to the query with id,
+// and the CNAME records they were found through, as their TTL matters too.
+// truncated is true when the response didn't fit into a UDP datagram.
+func parseDNSResponse(response []byte, id uint16, qtype uint16) (records []dnsRecord, truncated bool, err error) {
+	if len(response) < 12 || binary.BigEndian.Uint16(response) != id {
+		return nil, false, msgErr("dns.malformed")
+	}
+
+	flags := binary.BigEndian.Uint16(response[2:])
+	if flags&dnsFlagResponse == 0 {
+		return nil, false, msgErr("dns.malformed")
+	}
+	if flags&dnsFlagTruncated != 0 {
+		return nil, true, nil
+	}
+
+	switch rcode := flags & dnsRcodeMask; rcode {
+	case 0:
+	case dnsRcodeNXDomain:
+		return nil, false, msgErr("dns.no-such-host")
+	default:
+		return nil, false, fmt.Errorf(msg("dns.rcode"), rcode)
+	}
+
+	questions := binary.BigEndian.Uint16(response[4:])
+	answers := binary.BigEndian.Uint16(response[6:])
+	offset := 12
+
+	for range questions {
+		if _, offset, err = readDNSName(response, offset); err != nil {
+			return nil, false, err
+		}
+		offset += 4 // QTYPE and QCLASS
+	}
+
+	for range answers {
+		if _, offset, err = readDNSName(response, offset); err != nil {
+			return nil, false, err
+		}
+		if offset+10 > len(response) {
+			return nil, false, msgErr("dns.malformed")
+		}
+
+		record := dnsRecord{
+			rtype: binary.BigEndian.Uint16(response[offset:]),
+			ttl:   binary.BigEndian.Uint32(response[offset+4:]),
+		}
+		length := int(binary.BigEndian.Uint16(response[offset+8:]))
+		start := offset + 10
+		offset = start + length
+		if offset > len(response) {
+			return nil, false, msgErr("dns.malformed")
+		}
+		data := response[start:offset]
+
+		switch {
+		case record.rtype != qtype && record.rtype != dnsTypeCNAME:
+			continue
+		case record.rtype == dnsTypeA && length == 4:
+			record.addr = netip.AddrFrom4([4]byte(data))
+		case record.rtype == dnsTypeAAAA && length == 16:
+			record.addr = netip.AddrFrom16([16]byte(data))
+		case record.rtype == dnsTypeSRV:
+			if length < 7 {
+				return nil, false, msgErr("dns.malformed")
+			}
+			// the target may be compressed, pointing anywhere in the response
+			target, _, err := readDNSName(response, start+6)
+			if err != nil {
+				return nil, false, err
+			}
+			record.srv = srvRecord{
+				Target:   target,
+				Port:     binary.BigEndian.Uint16(data[4:]),
+				Priority: binary.BigEndian.Uint16(data),
+				Weight:   binary.BigEndian.Uint16(data[2:]),
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, false, nil
+}
+
+// readDNSName reads the possibly compressed domain name at offset of a message
+// and returns it with the offset after it.
+func readDNSName(message []byte, offset int) (string, int, error) {
+	var labels []string
+	end := -1 // end is the offset after the name, once a pointer was followed
+
+	for jumps := 0; ; {
+		if offset >= len(message) {
+			return "", 0, msgErr("dns.malformed")
+		}
+
+		length := int(message[offset])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = offset + 1
+			}
+			return strings.Join(labels, "."), end, nil
+
+		case length&0xc0 == 0xc0:
+			if offset+1 >= len(message) || jumps > 10 {
+				return "", 0, msgErr("dns.malformed")
+			}
+			if end < 0 {
+				end = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(message[offset:]) & 0x3fff)
+			jumps++
+
+		default:
+			if offset+1+length > len(message) {
+				return "", 0, msgErr("dns.malformed")
+			}
+			labels = append(labels, string(message[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
+
+// dnsStats keeps the statistics of the hostname lookups of a target.
+type dnsStats struct {
+	lookups  uint
+	failures uint
+	latency  phaseStat // latency is the duration of the successful lookups
+}
+
+// add records a lookup that took ms milliseconds.
+func (s *dnsStats) add(ms float32, failed bool) {
+	s.lookups++
+	if failed {
+		s.failures++
+	} else {
+		s.latency.add(ms)
+	}
+}
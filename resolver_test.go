@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDNSRecord is a record of the answers of a fake DNS server.
+type fakeDNSRecord struct {
+	rtype uint16
+	ttl   uint32
+	data  []byte
+}
+
+// fakeDNSResponse answers a query with the records of its type,
+// their names compressed to a pointer to the question.
+func fakeDNSResponse(query []byte, flags uint16, records map[uint16][]fakeDNSRecord) []byte {
+	answers := records[binary.BigEndian.Uint16(query[len(query)-4:])]
+
+	response := append([]byte{}, query[:2]...)
+	response = binary.BigEndian.AppendUint16(response, dnsFlagResponse|0x0180|flags)
+	response = binary.BigEndian.AppendUint16(response, 1)
+	response = binary.BigEndian.AppendUint16(response, uint16(len(answers)))
+	response = append(response, 0, 0, 0, 0)
+	response = append(response, query[12:]...)
+
+	for _, answer := range answers {
+		response = append(response, 0xc0, 0x0c)
+		response = binary.BigEndian.AppendUint16(response, answer.rtype)
+		response = binary.BigEndian.AppendUint16(response, dnsClassIN)
+		response = binary.BigEndian.AppendUint32(response, answer.ttl)
+		response = binary.BigEndian.AppendUint16(response, uint16(len(answer.data)))
+		response = append(response, answer.data...)
+	}
+
+	return response
+}
+
+// testDNSRecords are the records of the fake DNS servers.
+var testDNSRecords = map[uint16][]fakeDNSRecord{
+	dnsTypeA: {
+		{dnsTypeCNAME, 300, []byte{3, 'w', 'w', 'w', 0xc0, 0x0c}},
+		{dnsTypeA, 60, []byte{192, 0, 2, 1}},
+	},
+	dnsTypeAAAA: {
+		{dnsTypeAAAA, 30, netip.MustParseAddr("2001:db8::1").AsSlice()},
+	},
+}
+
+func TestParseResolver(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"1.1.1.1", "udp://1.1.1.1:53"},
+		{"2606:4700:4700::1111", "udp://[2606:4700:4700::1111]:53"},
+		{"1.1.1.1:5353", "udp://1.1.1.1:5353"},
+		{"TCP://1.1.1.1", "tcp://1.1.1.1:53"},
+		{"tls://dns.google", "tls://dns.google:853"},
+		{"tls://[2606:4700:4700::1111]", "tls://[2606:4700:4700::1111]:853"},
+		{"https://dns.google/dns-query", "https://dns.google/dns-query"},
+	}
+	for _, tt := range tests {
+		r, err := parseResolver(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.expected, r.String())
+	}
+
+	for _, invalid := range []string{"ftp://1.1.1.1", "udp://", "tcp://1.1.1.1/dns-query"} {
+		_, err := parseResolver(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseDNSResponse(t *testing.T) {
+	query, err := newDNSQuery(0x1234, "example.com.", dnsTypeA)
+	require.NoError(t, err)
+
+	records, truncated, err := parseDNSResponse(fakeDNSResponse(query, 0, testDNSRecords), 0x1234, dnsTypeA)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []dnsRecord{
+		{rtype: dnsTypeCNAME, ttl: 300},
+		{rtype: dnsTypeA, ttl: 60, addr: netip.MustParseAddr("192.0.2.1")},
+	}, records)
+
+	_, truncated, err = parseDNSResponse(fakeDNSResponse(query, dnsFlagTruncated, nil), 0x1234, dnsTypeA)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+
+	_, _, err = parseDNSResponse(fakeDNSResponse(query, dnsRcodeNXDomain, nil), 0x1234, dnsTypeA)
+	assert.Error(t, err)
+
+	_, _, err = parseDNSResponse(fakeDNSResponse(query, 0, testDNSRecords), 0x4321, dnsTypeA)
+	assert.Error(t, err, "the response to another query")
+
+	response := fakeDNSResponse(query, 0, testDNSRecords)
+	_, _, err = parseDNSResponse(response[:len(response)-2], 0x1234, dnsTypeA)
+	assert.Error(t, err, "a truncated record")
+
+	for _, invalid := range []string{"", "a..b", string(make([]byte, 64))} {
+		_, err := newDNSQuery(0, invalid, dnsTypeA)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestReadDNSName(t *testing.T) {
+	message := []byte{
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		3, 'w', 'w', 'w', 0xc0, 0x00,
+		0xc0, 0x13,
+	}
+
+	name, offset, err := readDNSName(message, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", name)
+	assert.Equal(t, 13, offset)
+
+	name, offset, err = readDNSName(message, 13)
+	require.NoError(t, err)
+	assert.Equal(t, "www.example.com", name)
+	assert.Equal(t, 19, offset)
+
+	_, _, err = readDNSName(message, 19)
+	assert.Error(t, err, "a pointer to itself")
+}
+
+// serveFakeDNSOverTCP answers the queries sent to ln over TCP with the current records.
+func serveFakeDNSOverTCP(ln net.Listener, records func() map[uint16][]fakeDNSRecord) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			query := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, query); err != nil {
+				return
+			}
+
+			response := fakeDNSResponse(query, 0, records())
+			conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(response))), response...))
+		}()
+	}
+}
+
+func TestResolverLookupNetIP(t *testing.T) {
+	expected := []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("2001:db8::1")}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go serveFakeDNSOverTCP(ln, func() map[uint16][]fakeDNSRecord { return testDNSRecords })
+
+	lookup := func(t *testing.T, server string) {
+		r, err := parseResolver(server)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		addrs, ttl, err := r.lookupNetIP(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, expected, addrs)
+		assert.Equal(t, 30*time.Second, ttl, "the lowest TTL")
+	}
+
+	t.Run("tcp", func(t *testing.T) {
+		lookup(t, "tcp://"+ln.Addr().String())
+	})
+
+	t.Run("a truncated udp response is asked for again over tcp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", ln.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		go func() {
+			buf := make([]byte, maxDNSMessageSize)
+			for {
+				n, addr, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				conn.WriteTo(fakeDNSResponse(buf[:n], dnsFlagTruncated, nil), addr)
+			}
+		}()
+
+		lookup(t, ln.Addr().String())
+	})
+
+	t.Run("https", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, dnsMessageType, req.Header.Get("Content-Type"))
+			query, _ := io.ReadAll(req.Body)
+			w.Header().Set("Content-Type", dnsMessageType)
+			w.Write(fakeDNSResponse(query, 0, testDNSRecords))
+		}))
+		defer server.Close()
+
+		r, err := parseResolver(server.URL + "/dns-query")
+		require.NoError(t, err)
+		r.client = server.Client()
+
+		addrs, ttl, err := r.lookupNetIP(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, expected, addrs)
+		assert.Equal(t, 30*time.Second, ttl)
+	})
+}
+
+func TestDNSStats(t *testing.T) {
+	var stats dnsStats
+	stats.add(10, false)
+	stats.add(2000, true)
+	stats.add(30, false)
+
+	assert.Equal(t, uint(3), stats.lookups)
+	assert.Equal(t, uint(1), stats.failures)
+	assert.Equal(t, phaseResult{min: 10, max: 30, average: 20, hasResults: true}, stats.latency.result(), "failed lookups are not timed")
+}
@@ -0,0 +1,213 @@
+// rttstats.go contains the RTT statistics, kept in constant memory however long tcping runs
+package main
+
+import (
+	"math"
+)
+
+const (
+	// sketchRelativeAccuracy is the maximum relative error
+	// of the quantiles returned by quantileSketch.
+	sketchRelativeAccuracy = 0.005
+	// sketchMinValue is the smallest RTT in milliseconds that gets its own bucket.
+	// Smaller values are counted in the first bucket.
+	sketchMinValue = 0.001
+	// sketchMaxValue is the largest RTT in milliseconds that gets its own bucket.
+	// Larger values are counted in the last bucket.
+	sketchMaxValue = 3_600_000
+)
+
+var (
+	sketchGamma      = (1 + sketchRelativeAccuracy) / (1 - sketchRelativeAccuracy)
+	sketchLogGamma   = math.Log(sketchGamma)
+	sketchIndexShift = int(math.Floor(math.Log(sketchMinValue) / sketchLogGamma))
+	sketchBuckets    = int(math.Ceil(math.Log(sketchMaxValue)/sketchLogGamma)) - sketchIndexShift + 1
+)
+
+// quantileSketch is a DDSketch with a fixed set of logarithmic buckets.
+//
+// Every quantile it returns is within sketchRelativeAccuracy of the
+// exact value, its size never changes regardless of the number of
+// values added and two sketches can be merged by adding their buckets.
+type quantileSketch struct {
+	buckets []uint64
+	count   uint64
+}
+
+// newQuantileSketch allocates all buckets up front
+// so adding values never grows the sketch.
+func newQuantileSketch() *quantileSketch {
+	return &quantileSketch{
+		buckets: make([]uint64, sketchBuckets),
+	}
+}
+
+// bucketIndex returns the bucket that value falls into.
+func (q *quantileSketch) bucketIndex(value float64) int {
+	if value <= sketchMinValue {
+		return 0
+	}
+
+	index := int(math.Ceil(math.Log(value)/sketchLogGamma)) - sketchIndexShift
+	if index >= len(q.buckets) {
+		return len(q.buckets) - 1
+	}
+
+	return index
+}
+
+// bucketValue returns the value representing all values of a bucket.
+func (q *quantileSketch) bucketValue(index int) float64 {
+	return 2 * math.Pow(sketchGamma, float64(index+sketchIndexShift)) / (sketchGamma + 1)
+}
+
+// add counts value in its bucket.
+func (q *quantileSketch) add(value float64) {
+	q.buckets[q.bucketIndex(value)]++
+	q.count++
+}
+
+// merge adds the counts of other into q.
+func (q *quantileSketch) merge(other *quantileSketch) {
+	for i, c := range other.buckets {
+		q.buckets[i] += c
+	}
+	q.count += other.count
+}
+
+// quantile returns the approximate value at quantile p, where 0 <= p <= 1.
+//
+// Like the exact percentiles of a sorted list, it interpolates
+// linearly between the values of the two ranks closest to p.
+func (q *quantileSketch) quantile(p float64) float64 {
+	if q.count == 0 {
+		return 0
+	}
+
+	rank, frac := math.Modf(p * float64(q.count-1))
+	value := q.valueAt(uint64(rank))
+	if frac == 0 {
+		return value
+	}
+
+	return value + frac*(q.valueAt(uint64(rank)+1)-value)
+}
+
+// valueAt returns the approximate value of the given rank,
+// counted from 0 for the smallest value.
+func (q *quantileSketch) valueAt(rank uint64) float64 {
+	var seen uint64
+	for i, c := range q.buckets {
+		seen += c
+		if seen > rank {
+			return q.bucketValue(i)
+		}
+	}
+
+	return q.bucketValue(len(q.buckets) - 1)
+}
+
+// rttStats keeps running RTT statistics in constant memory.
+//
+// The mean and variance are tracked with Welford's algorithm,
+// jitter with the running sum of differences between consecutive RTTs
+// and the percentiles with a quantileSketch.
+type rttStats struct {
+	sketch    *quantileSketch
+	count     uint64
+	mean      float64
+	m2        float64 // m2 is the sum of squared differences from the mean
+	jitterSum float64
+	last      float32
+	min       float32
+	max       float32
+}
+
+// add records a new RTT value in milliseconds.
+func (s *rttStats) add(rtt float32) {
+	if s.sketch == nil {
+		s.sketch = newQuantileSketch()
+	}
+
+	if s.count == 0 {
+		s.min = rtt
+		s.max = rtt
+	} else {
+		s.jitterSum += math.Abs(float64(rtt - s.last))
+		s.min = min(s.min, rtt)
+		s.max = max(s.max, rtt)
+	}
+
+	s.count++
+	delta := float64(rtt) - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (float64(rtt) - s.mean)
+	s.last = rtt
+
+	s.sketch.add(float64(rtt))
+}
+
+// merge combines the statistics of other into s.
+//
+// The difference between the last RTT of s and the first RTT of other
+// is unknown, so the merged jitter only covers consecutive RTTs of each.
+func (s *rttStats) merge(other *rttStats) {
+	if other.count == 0 {
+		return
+	}
+
+	if s.count == 0 {
+		s.min = other.min
+		s.max = other.max
+	} else {
+		s.min = min(s.min, other.min)
+		s.max = max(s.max, other.max)
+	}
+
+	total := s.count + other.count
+	delta := other.mean - s.mean
+	s.m2 += other.m2 + delta*delta*float64(s.count)*float64(other.count)/float64(total)
+	s.mean += delta * float64(other.count) / float64(total)
+	s.jitterSum += other.jitterSum
+	s.count = total
+	s.last = other.last
+
+	if s.sketch == nil {
+		s.sketch = newQuantileSketch()
+	}
+	s.sketch.merge(other.sketch)
+}
+
+// result calculates min, avg, max, percentiles,
+// standard deviation and jitter of the recorded RTT values.
+func (s *rttStats) result() rttResult {
+	var result rttResult
+
+	if s.count == 0 {
+		return result
+	}
+
+	result.hasResults = true
+	result.min = s.min
+	result.max = s.max
+	result.average = float32(s.mean)
+	result.stdDev = float32(math.Sqrt(s.m2 / float64(s.count)))
+
+	if s.count > 1 {
+		result.jitter = float32(s.jitterSum / float64(s.count-1))
+	}
+
+	result.median = s.percentile(50)
+	result.p90 = s.percentile(90)
+	result.p95 = s.percentile(95)
+	result.p99 = s.percentile(99)
+
+	return result
+}
+
+// percentile returns the p-th percentile of the recorded RTT values,
+// clamped to the exact min and max values.
+func (s *rttStats) percentile(p float64) float32 {
+	value := float32(s.sketch.quantile(p / 100))
+	return min(max(value, s.min), s.max)
+}
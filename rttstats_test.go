@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRttStatsResult(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var s rttStats
+		assert.False(t, s.result().hasResults)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		var s rttStats
+		for _, rtt := range []float32{10, 20, 30, 40, 50} {
+			s.add(rtt)
+		}
+		result := s.result()
+
+		assert.True(t, result.hasResults)
+		assert.Equal(t, float32(10), result.min)
+		assert.Equal(t, float32(50), result.max)
+		assert.Equal(t, float32(30), result.average)
+		assert.InEpsilon(t, 30, result.median, sketchRelativeAccuracy)
+		assert.InEpsilon(t, 46, result.p90, sketchRelativeAccuracy)
+		assert.InEpsilon(t, 48, result.p95, sketchRelativeAccuracy)
+		assert.InEpsilon(t, 49.6, result.p99, sketchRelativeAccuracy)
+		assert.InDelta(t, 14.142, result.stdDev, 0.001)
+		assert.Equal(t, float32(10), result.jitter)
+	})
+
+	t.Run("jitter uses consecutive differences", func(t *testing.T) {
+		var s rttStats
+		for _, rtt := range []float32{10, 30, 10, 30} {
+			s.add(rtt)
+		}
+
+		result := s.result()
+
+		assert.Equal(t, float32(20), result.jitter)
+		assert.InEpsilon(t, 20, result.median, sketchRelativeAccuracy)
+	})
+
+	t.Run("single value", func(t *testing.T) {
+		var s rttStats
+		s.add(7)
+		result := s.result()
+
+		assert.Equal(t, float32(7), result.median)
+		assert.Equal(t, float32(7), result.p99)
+		assert.Equal(t, float32(0), result.jitter)
+		assert.Equal(t, float32(0), result.stdDev)
+	})
+}
+
+func TestRttStatsPercentiles(t *testing.T) {
+	var s rttStats
+	for i := 1; i <= 10000; i++ {
+		s.add(float32(i) / 10)
+	}
+	result := s.result()
+
+	assert.InEpsilon(t, 500, result.median, sketchRelativeAccuracy)
+	assert.InEpsilon(t, 900, result.p90, sketchRelativeAccuracy)
+	assert.InEpsilon(t, 950, result.p95, sketchRelativeAccuracy)
+	assert.InEpsilon(t, 990, result.p99, sketchRelativeAccuracy)
+	assert.InEpsilon(t, 500.05, result.average, 0.0001)
+	assert.InEpsilon(t, 288.675, result.stdDev, 0.0001)
+}
+
+func TestRttStatsConstantMemory(t *testing.T) {
+	var s rttStats
+	s.add(1)
+
+	buckets := len(s.sketch.buckets)
+
+	allocs := testing.AllocsPerRun(100_000, func() {
+		s.add(rand.Float32() * 1000)
+	})
+
+	assert.Zero(t, allocs)
+	assert.Equal(t, buckets, len(s.sketch.buckets))
+	assert.Equal(t, buckets, cap(s.sketch.buckets))
+
+	// values outside the sketch range are kept in the edge buckets
+	s.add(0)
+	s.add(sketchMaxValue * 10)
+	assert.Equal(t, buckets, len(s.sketch.buckets))
+}
+
+func TestRttStatsMerge(t *testing.T) {
+	var all, first, second rttStats
+	for i := 1; i <= 1000; i++ {
+		rtt := float32(i)
+		all.add(rtt)
+		if i <= 400 {
+			first.add(rtt)
+		} else {
+			second.add(rtt)
+		}
+	}
+
+	var merged rttStats
+	merged.merge(&first)
+	merged.merge(&second)
+
+	want := all.result()
+	got := merged.result()
+
+	assert.Equal(t, want.min, got.min)
+	assert.Equal(t, want.max, got.max)
+	assert.InEpsilon(t, want.average, got.average, 0.0001)
+	assert.InEpsilon(t, want.stdDev, got.stdDev, 0.0001)
+	assert.Equal(t, want.median, got.median)
+	assert.Equal(t, want.p99, got.p99)
+}
@@ -0,0 +1,504 @@
+// serve.go contains the logic of the serve subcommand, probing the targets as a daemon
+// and exposing their statistics over HTTP
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultServeAddr is the default address of the daemon's HTTP API.
+	defaultServeAddr = "127.0.0.1:8080"
+	// jobsFileName is the default name of the file the jobs are persisted to.
+	jobsFileName = "jobs.json"
+)
+
+// jobState is the state of a probe job.
+type jobState string
+
+const (
+	jobRunning jobState = "running"
+	jobPaused  jobState = "paused"
+)
+
+// jobSpec describes what a probe job probes and how.
+// It is the equivalent of the command line arguments of a single target.
+type jobSpec struct {
+	Host             string   `json:"host"`
+	Port             uint16   `json:"port"`
+	Interval         *float64 `json:"interval,omitempty"` // Interval between probes in seconds, defaults to 1
+	Timeout          *float64 `json:"timeout,omitempty"`  // Timeout in seconds, defaults to 1. 0 means infinite timeout
+	RetryResolve     uint     `json:"retry_resolve,omitempty"`
+	Interface        string   `json:"interface,omitempty"`
+	IPv4             bool     `json:"ipv4,omitempty"`
+	IPv6             bool     `json:"ipv6,omitempty"`
+	ShowFailuresOnly bool     `json:"show_failures_only,omitempty"`
+}
+
+// job is a probe job managed by the daemon.
+type job struct {
+	ID      string    `json:"id"`
+	Spec    jobSpec   `json:"spec"`
+	State   jobState  `json:"state"`
+	Created time.Time `json:"created"`
+
+	tcping *tcping
+	stats  JSONData      // stats is the latest statistics of the job
+	stop   chan struct{} // stop is closed to stop the probe goroutine
+	done   chan struct{} // done is closed once the probe goroutine returned
+}
+
+// daemon runs probe jobs and serves the HTTP API to manage them.
+type daemon struct {
+	mu       sync.Mutex
+	printer  printer // printer is shared by all jobs to log their probes
+	jobs     map[string]*job
+	jobsFile string
+	nextID   uint64
+}
+
+// jobPrinter logs the events of a job through the daemon's printer
+// and keeps the statistics of the job instead of printing them.
+type jobPrinter struct {
+	printer
+	onStatistics func(JSONData)
+}
+
+func (p *jobPrinter) printStatistics(t tcping) {
+	p.onStatistics(newStatisticsData(t))
+}
+
+// serve runs the daemon mode, started through `tcping serve`.
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listenAddr := flags.String("listen", defaultServeAddr, msg("flag.serve.listen"))
+	jobsFile := flags.String("jobs-file", defaultJobsFile(), msg("flag.serve.jobs-file"))
+	outputJSON := flags.Bool("j", false, msg("flag.j"))
+	showTimestamp := flags.Bool("D", true, msg("flag.D"))
+	flags.String("lang", "", msg("flag.lang"))
+	flags.Parse(args)
+
+	var p printer
+	if *outputJSON {
+		p = newJSONPrinter(false)
+	} else {
+		p = newPlainPrinter(showTimestamp)
+	}
+
+	d := newDaemon(newSyncPrinter(p), *jobsFile)
+	if err := d.load(); err != nil {
+		p.printError(msg("serve.load-failed"), err)
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:              *listenAddr,
+		Handler:           d.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	p.printInfo(msg("serve.listening"), *listenAddr, *jobsFile)
+	if err := server.ListenAndServe(); err != nil {
+		p.printError(msg("serve.start-failed"), err)
+		os.Exit(1)
+	}
+}
+
+// defaultJobsFile returns the jobs file under the user's configuration directory,
+// or under the current directory if it cannot be determined.
+func defaultJobsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return jobsFileName
+	}
+	return filepath.Join(dir, "tcping", jobsFileName)
+}
+
+func newDaemon(p printer, jobsFile string) *daemon {
+	return &daemon{
+		printer:  p,
+		jobs:     map[string]*job{},
+		jobsFile: jobsFile,
+	}
+}
+
+// handler returns the HTTP API of the daemon.
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs", d.handleListJobs)
+	mux.HandleFunc("POST /jobs", d.handleCreateJob)
+	mux.HandleFunc("GET /jobs/{id}", d.handleGetJob)
+	mux.HandleFunc("DELETE /jobs/{id}", d.handleDeleteJob)
+	mux.HandleFunc("POST /jobs/{id}/pause", d.handlePauseJob)
+	mux.HandleFunc("POST /jobs/{id}/resume", d.handleResumeJob)
+	mux.HandleFunc("GET /jobs/{id}/stats", d.handleJobStats)
+	return mux
+}
+
+func (d *daemon) handleListJobs(w http.ResponseWriter, _ *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, d.sortedJobs())
+}
+
+func (d *daemon) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var spec jobSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf(msg("serve.invalid-request"), err))
+		return
+	}
+
+	t, err := newJobTcping(spec)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// resolve right away, so an unknown host is reported to the caller
+	if err := resolveJobTarget(t, spec); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextID++
+	j := &job{
+		ID:      strconv.FormatUint(d.nextID, 10),
+		Spec:    spec,
+		State:   jobRunning,
+		Created: time.Now(),
+	}
+	d.jobs[j.ID] = j
+	d.attach(j, t)
+	t.printStart(t.userInput.hostname, t.userInput.port)
+	d.start(j)
+
+	if err := d.save(); err != nil {
+		d.printer.printError(msg("serve.save-failed"), err)
+	}
+
+	writeJSON(w, http.StatusCreated, j)
+}
+
+func (d *daemon) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	j, ok := d.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, msgErr("serve.job-not-found"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+func (d *daemon) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	j, ok := d.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, msgErr("serve.job-not-found"))
+		return
+	}
+
+	if j.State == jobRunning {
+		close(j.stop)
+	}
+	delete(d.jobs, j.ID)
+
+	if err := d.save(); err != nil {
+		d.printer.printError(msg("serve.save-failed"), err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *daemon) handlePauseJob(w http.ResponseWriter, r *http.Request) {
+	d.setJobState(w, r, jobPaused)
+}
+
+func (d *daemon) handleResumeJob(w http.ResponseWriter, r *http.Request) {
+	d.setJobState(w, r, jobRunning)
+}
+
+// setJobState pauses or resumes a job.
+// Statistics of a paused job are kept and continued once it is resumed.
+func (d *daemon) setJobState(w http.ResponseWriter, r *http.Request, state jobState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	j, ok := d.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, msgErr("serve.job-not-found"))
+		return
+	}
+
+	if j.State != state {
+		j.State = state
+		if state == jobRunning {
+			d.start(j)
+		} else {
+			close(j.stop)
+		}
+
+		if err := d.save(); err != nil {
+			d.printer.printError(msg("serve.save-failed"), err)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+func (d *daemon) handleJobStats(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	j, ok := d.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, msgErr("serve.job-not-found"))
+		return
+	}
+
+	stats := j.stats
+	stats.Timestamp = time.Now()
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// start starts the probe goroutine of j.
+//
+// If the goroutine of a previous run is still finishing its last probe,
+// the new one waits for it, so only one goroutine uses j.tcping at a time.
+// d.mu must be held by the caller.
+func (d *daemon) start(j *job) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	previous := j.done
+	j.stop, j.done = stop, done
+
+	go func() {
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		d.run(j, stop)
+	}()
+}
+
+// run probes the target of j until stop is closed.
+func (d *daemon) run(j *job, stop <-chan struct{}) {
+	t := j.tcping
+	t.ticker = time.NewTicker(t.userInput.intervalBetweenProbes)
+	defer t.ticker.Stop()
+
+	t.printStats()
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		// the target could not be resolved when the job was loaded
+		if !t.userInput.ip.IsValid() {
+			if err := resolveJobTarget(t, j.Spec); err != nil {
+				t.printError("%s", err)
+				<-t.ticker.C
+				continue
+			}
+			t.printStart(t.userInput.hostname, t.userInput.port)
+		}
+
+		if t.userInput.shouldRetryResolve {
+			retryResolveHostname(t)
+		}
+
+		probe(t)
+		t.printStats()
+	}
+}
+
+// newJobTcping creates a tcping for spec.
+// Unlike processUserInput, invalid input is reported instead of exiting.
+func newJobTcping(spec jobSpec) (*tcping, error) {
+	if spec.Host == "" {
+		return nil, msgErr("serve.host-required")
+	}
+	if spec.Port == 0 {
+		return nil, msgErr("error.port-range")
+	}
+	if spec.IPv4 && spec.IPv6 {
+		return nil, msgErr("error.ip-versions")
+	}
+
+	interval := 1.0
+	if spec.Interval != nil {
+		interval = *spec.Interval
+	}
+	timeout := 1.0
+	if spec.Timeout != nil {
+		timeout = *spec.Timeout
+	}
+
+	t := &tcping{
+		userInput: userInput{
+			hostname:                 spec.Host,
+			port:                     spec.Port,
+			useIPv4:                  spec.IPv4,
+			useIPv6:                  spec.IPv6,
+			retryHostnameLookupAfter: spec.RetryResolve,
+			timeout:                  secondsToDuration(timeout),
+			dnsTimeout:               secondsToDuration(defaultDNSTimeout),
+			intervalBetweenProbes:    secondsToDuration(interval),
+			showFailuresOnly:         spec.ShowFailuresOnly,
+		},
+		startTime: time.Now(),
+	}
+
+	if t.userInput.intervalBetweenProbes < 2*time.Millisecond {
+		return nil, msgErr("error.interval")
+	}
+
+	return t, nil
+}
+
+// resolveJobTarget resolves the hostname of t and sets up
+// everything that depends on the resolved address.
+func resolveJobTarget(t *tcping, spec jobSpec) error {
+	ip, err := lookupHostname(t)
+	if err != nil {
+		return err
+	}
+
+	t.userInput.ip = ip
+	t.hostnameChanges = []hostnameChange{
+		{ip, time.Now()},
+	}
+
+	t.destIsIP = t.userInput.hostname == ip.String()
+	t.userInput.shouldRetryResolve = t.userInput.retryHostnameLookupAfter > 0 && !t.destIsIP
+
+	if spec.Interface != "" {
+		t.userInput.networkInterface, err = newNetworkInterface(t, spec.Interface)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attach makes t the tcping of j, with its own printer on top of the daemon's printer.
+// d.mu must be held by the caller.
+func (d *daemon) attach(j *job, t *tcping) {
+	j.tcping = t
+	j.stats = newStatisticsData(*t)
+	t.printer = &jobPrinter{
+		printer: d.printer,
+		onStatistics: func(data JSONData) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			j.stats = data
+		},
+	}
+}
+
+// load restores the persisted jobs and starts the ones that were running.
+// A missing jobs file is not an error.
+func (d *daemon) load() error {
+	content, err := os.ReadFile(d.jobsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var jobs []*job
+	if err := json.Unmarshal(content, &jobs); err != nil {
+		return fmt.Errorf("%s: %w", d.jobsFile, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, j := range jobs {
+		t, err := newJobTcping(j.Spec)
+		if err != nil {
+			return fmt.Errorf(msg("serve.job"), j.ID, err)
+		}
+		d.attach(j, t)
+		d.jobs[j.ID] = j
+
+		if id, err := strconv.ParseUint(j.ID, 10, 64); err == nil {
+			d.nextID = max(d.nextID, id)
+		}
+
+		if j.State == jobRunning {
+			d.start(j)
+		}
+	}
+
+	return nil
+}
+
+// sortedJobs returns all jobs in the order they were created.
+// d.mu must be held by the caller.
+func (d *daemon) sortedJobs() []*job {
+	jobs := make([]*job, 0, len(d.jobs))
+	for _, j := range d.jobs {
+		jobs = append(jobs, j)
+	}
+	slices.SortFunc(jobs, func(a, b *job) int {
+		return a.Created.Compare(b.Created)
+	})
+
+	return jobs
+}
+
+// save persists the jobs to the jobs file.
+// The file is replaced atomically, so a crash never leaves a partial file.
+// d.mu must be held by the caller.
+func (d *daemon) save() error {
+	content, err := json.MarshalIndent(d.sortedJobs(), "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.jobsFile), 0o755); err != nil {
+		return err
+	}
+
+	tmp := d.jobsFile + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, d.jobsFile)
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
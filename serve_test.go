@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doRequest sends a request to the daemon's API and decodes the JSON response into v.
+func doRequest(t *testing.T, srv *httptest.Server, method, path string, body any, v any) int {
+	t.Helper()
+
+	var reader bytes.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader.Reset(content)
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, &reader)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	return resp.StatusCode
+}
+
+func TestDaemonJobs(t *testing.T) {
+	jobsFile := filepath.Join(t.TempDir(), "tcping", "jobs.json")
+	d := newDaemon(&dummyPrinter{}, jobsFile)
+	srv := httptest.NewServer(d.handler())
+	t.Cleanup(srv.Close)
+
+	interval := 0.01
+	// nothing listens on this port, so every probe fails fast
+	spec := jobSpec{Host: "127.0.0.1", Port: 12346, Interval: &interval}
+
+	var created job
+	status := doRequest(t, srv, http.MethodPost, "/jobs", spec, &created)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "1", created.ID)
+	assert.Equal(t, jobRunning, created.State)
+
+	assert.Eventually(t, func() bool {
+		var stats JSONData
+		doRequest(t, srv, http.MethodGet, "/jobs/1/stats", nil, &stats)
+		return stats.Type == statisticsEvent && stats.TotalUnsuccessfulProbes > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var paused job
+	status = doRequest(t, srv, http.MethodPost, "/jobs/1/pause", nil, &paused)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, jobPaused, paused.State)
+
+	var jobs []job
+	doRequest(t, srv, http.MethodGet, "/jobs", nil, &jobs)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, spec.Host, jobs[0].Spec.Host)
+
+	content, err := os.ReadFile(jobsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"state": "paused"`)
+
+	var resumed job
+	doRequest(t, srv, http.MethodPost, "/jobs/1/resume", nil, &resumed)
+	assert.Equal(t, jobRunning, resumed.State)
+
+	status = doRequest(t, srv, http.MethodDelete, "/jobs/1", nil, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status = doRequest(t, srv, http.MethodGet, "/jobs/1", nil, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestDaemonInvalidJob(t *testing.T) {
+	d := newDaemon(&dummyPrinter{}, filepath.Join(t.TempDir(), "jobs.json"))
+	srv := httptest.NewServer(d.handler())
+	t.Cleanup(srv.Close)
+
+	interval := 0.001
+	for _, spec := range []jobSpec{
+		{Port: 443},
+		{Host: "127.0.0.1"},
+		{Host: "127.0.0.1", Port: 443, IPv4: true, IPv6: true},
+		{Host: "127.0.0.1", Port: 443, Interval: &interval},
+	} {
+		var resp map[string]string
+		status := doRequest(t, srv, http.MethodPost, "/jobs", spec, &resp)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.NotEmpty(t, resp["error"])
+	}
+}
+
+func TestDaemonLoad(t *testing.T) {
+	jobsFile := filepath.Join(t.TempDir(), "jobs.json")
+
+	d := newDaemon(&dummyPrinter{}, jobsFile)
+	d.jobs["7"] = &job{
+		ID:      "7",
+		Spec:    jobSpec{Host: "127.0.0.1", Port: 12346},
+		State:   jobPaused,
+		Created: time.Now(),
+	}
+	require.NoError(t, d.save())
+
+	loaded := newDaemon(&dummyPrinter{}, jobsFile)
+	require.NoError(t, loaded.load())
+
+	require.Contains(t, loaded.jobs, "7")
+	assert.Equal(t, jobPaused, loaded.jobs["7"].State)
+	assert.Equal(t, uint64(7), loaded.nextID)
+	assert.Equal(t, statisticsEvent, loaded.jobs["7"].stats.Type)
+}
+
+func TestDaemonLoadMissingFile(t *testing.T) {
+	d := newDaemon(&dummyPrinter{}, filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, d.load())
+	assert.Empty(t, d.jobs)
+}
@@ -0,0 +1,431 @@
+// srv.go contains the discovery of targets through the SRV records of a name like _service._proto.name,
+// probing every target, or one after another by priority and weight, and following their changes
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSRVInterval is the default time between lookups of the SRV records, in seconds.
+const defaultSRVInterval = 60
+
+// isSRVName reports whether name is an SRV name like "_sip._tcp.example.com",
+// which is given in place of a host and port pair.
+func isSRVName(name string) bool {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	return len(labels) >= 3 &&
+		len(labels[0]) > 1 && strings.HasPrefix(labels[0], "_") &&
+		len(labels[1]) > 1 && strings.HasPrefix(labels[1], "_")
+}
+
+// splitTargets splits args into host and port pairs and SRV names, which stand alone.
+// ok is false when a host has no port.
+func splitTargets(args []string) (targets [][]string, ok bool) {
+	for i := 0; i < len(args); {
+		if isSRVName(args[i]) {
+			targets = append(targets, args[i:i+1])
+			i++
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return nil, false
+		}
+		targets = append(targets, args[i:i+2])
+		i += 2
+	}
+
+	return targets, true
+}
+
+// srvRecord is a target of an SRV name.
+type srvRecord struct {
+	Target   string `json:"target"`
+	Port     uint16 `json:"port"`
+	Priority uint16 `json:"priority"`
+	Weight   uint16 `json:"weight"`
+}
+
+// hostPort returns the "host:port" of the target.
+func (r srvRecord) hostPort() string {
+	return net.JoinHostPort(r.Target, strconv.Itoa(int(r.Port)))
+}
+
+// srvChange is a time the target was added to or removed from the SRV records of its name.
+type srvChange struct {
+	Added bool      `json:"added"`
+	When  time.Time `json:"when"`
+}
+
+// srvMember is a target found in the SRV records.
+type srvMember struct {
+	target  *tcping
+	removed bool // removed is true while the target is missing from the SRV records
+}
+
+// srvDiscovery finds the targets of an SRV name and looks the records up again on a schedule.
+//
+// It's shared by the targets, but only the loop of the first target probes them,
+// so that the targets found later are probed too.
+type srvDiscovery struct {
+	mu         sync.Mutex // mu guards members, as the statistics may be printed while looking up the records
+	name       string
+	weighted   bool          // weighted is true when the targets are tried one after another by priority and weight
+	interval   time.Duration // interval is the time between lookups, unless the TTL of the records is followed
+	sni        bool          // sni is true when --sni sets the server name of the TLS probes, instead of the target
+	members    []*srvMember
+	nextLookup time.Time
+}
+
+// newSRVTargets looks up the SRV records of name and creates a target for each of them.
+//
+// target is set up for the first record and the other targets are copied from it,
+// with their own hostname, port and address.
+func newSRVTargets(target *tcping, genericArgs genericUserInputArgs, name string) ([]*tcping, error) {
+	s := &srvDiscovery{
+		name:     name,
+		weighted: genericArgs.srvWeighted,
+		interval: genericArgs.srvInterval,
+		sni:      genericArgs.tlsOptions.serverName != "",
+	}
+
+	records, ttl, err := lookupSRV(target, name)
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleLookup(target, ttl)
+
+	targetArgs := genericArgs
+	targetArgs.args = []string{records[0].Target, strconv.Itoa(int(records[0].Port))}
+	setPort(target, targetArgs.args)
+	setGenericArgs(target, targetArgs)
+	target.userInput.srvName = name
+	target.srvRecord = records[0]
+	target.srv = s
+	s.members = append(s.members, &srvMember{target: target})
+
+	for _, record := range records[1:] {
+		t, err := s.newTarget(record)
+		if err != nil {
+			return nil, err
+		}
+		s.members = append(s.members, &srvMember{target: t})
+	}
+
+	return s.targets(), nil
+}
+
+// newTarget creates a target for record, copied from the first target.
+func (s *srvDiscovery) newTarget(record srvRecord) (*tcping, error) {
+	first := s.members[0].target
+	t := &tcping{
+		printer:   first.printer,
+		exporter:  first.exporter,
+		alerts:    slices.Clone(first.alerts),
+		userInput: first.userInput,
+		srv:       s,
+		srvRecord: record,
+		startTime: time.Now(),
+	}
+	t.userInput.hostname = record.Target
+	t.userInput.port = record.Port
+
+	var err error
+	if t.userInput.happyEyeballs != nil {
+		t.raceAddrs, err = resolveRaceAddrs(t)
+		if err == nil {
+			t.userInput.ip = t.raceAddrs[0]
+		}
+	} else {
+		t.userInput.ip, err = lookupHostname(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.hostnameChanges = []hostnameChange{{t.userInput.ip, t.startTime}}
+
+	if t.userInput.tlsConfig != nil && !s.sni {
+		t.userInput.tlsConfig = t.userInput.tlsConfig.Clone()
+		t.userInput.tlsConfig.ServerName = record.Target
+	}
+
+	if t.userInput.networkInterface.use {
+		t.userInput.networkInterface.remoteAddr = &net.TCPAddr{
+			IP:   t.userInput.ip.AsSlice(),
+			Port: int(t.userInput.port),
+		}
+	}
+
+	return t, nil
+}
+
+// first returns the target whose loop probes every target of the SRV name.
+func (s *srvDiscovery) first() *tcping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.members[0].target
+}
+
+// targets returns every target found in the SRV records, the removed ones included.
+func (s *srvDiscovery) targets() []*tcping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	targets := make([]*tcping, 0, len(s.members))
+	for _, m := range s.members {
+		targets = append(targets, m.target)
+	}
+	return targets
+}
+
+// activeTargets returns the targets currently in the SRV records.
+func (s *srvDiscovery) activeTargets() []*tcping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var targets []*tcping
+	for _, m := range s.members {
+		if !m.removed {
+			targets = append(targets, m.target)
+		}
+	}
+	return targets
+}
+
+// scheduleLookup sets the time of the next lookup of the SRV records,
+// when their TTL expires with --resolve-ttl, after --srv-interval otherwise.
+func (s *srvDiscovery) scheduleLookup(t *tcping, ttl time.Duration) {
+	wait := s.interval
+	if t.userInput.resolveTTL && ttl > 0 {
+		wait = max(ttl, time.Second)
+	}
+	s.nextLookup = time.Now().Add(wait)
+}
+
+// lookup looks the SRV records up again and reports the targets added to or removed from them.
+//
+// The targets are kept when the lookup fails, and a new target
+// that can't be resolved is tried again at the next lookup,
+// as tcping shouldn't exit once it has been running for a while.
+func (s *srvDiscovery) lookup() {
+	first := s.first()
+	records, ttl, err := lookupSRV(first, s.name)
+	s.scheduleLookup(first, ttl)
+	if err != nil {
+		first.printInfo("%s", err)
+		return
+	}
+
+	found := map[string]srvRecord{}
+	for _, record := range records {
+		found[record.hostPort()] = record
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	known := map[string]bool{}
+	for _, m := range s.members {
+		key := m.target.srvRecord.hostPort()
+		known[key] = true
+
+		record, ok := found[key]
+		switch {
+		case ok && m.removed:
+			m.removed = false
+			m.target.srvRecord = record
+			m.target.srvChanges = append(m.target.srvChanges, srvChange{Added: true, When: now})
+			first.printInfo(msg("srv.added"), s.name, key)
+		case ok:
+			// the priority and the weight may have changed
+			m.target.srvRecord = record
+		case !m.removed:
+			m.removed = true
+			m.target.srvChanges = append(m.target.srvChanges, srvChange{Added: false, When: now})
+			first.printInfo(msg("srv.removed"), s.name, key)
+		}
+	}
+
+	for _, record := range records {
+		if known[record.hostPort()] {
+			continue
+		}
+
+		t, err := s.newTarget(record)
+		if err != nil {
+			first.printInfo("%s", err)
+			continue
+		}
+		t.ticker = time.NewTicker(t.userInput.intervalBetweenProbes)
+		t.srvChanges = []srvChange{{Added: true, When: now}}
+		if t.exporter != nil {
+			t.exporter.register(t.userInput)
+		}
+
+		s.members = append(s.members, &srvMember{target: t})
+		first.printInfo(msg("srv.added"), s.name, record.hostPort())
+	}
+}
+
+// probe probes the targets of the SRV name, after looking up the records again when it's time to.
+//
+// Every target is probed at the same time, or with --srv-weighted,
+// one after another in the order of RFC 2782 until one of them succeeds.
+func (s *srvDiscovery) probe() {
+	if !time.Now().Before(s.nextLookup) {
+		s.lookup()
+	}
+
+	targets := s.activeTargets()
+	if s.weighted {
+		for _, t := range srvOrder(targets, rand.Intn) {
+			successes := t.totalSuccessfulProbes
+			resolveBeforeProbe(t)
+			probe(t)
+			if t.totalSuccessfulProbes > successes {
+				return
+			}
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolveBeforeProbe(t)
+			probe(t)
+		}()
+	}
+	wg.Wait()
+}
+
+// reset starts the statistics of the targets of the SRV name over,
+// except first, which is reset by its own loop.
+func (s *srvDiscovery) reset(first *tcping) {
+	for _, t := range s.targets() {
+		if t != first {
+			t.resetStats()
+		}
+	}
+}
+
+// srvOrder orders the targets the way RFC 2782 clients try them: by priority, the lowest first,
+// and among the targets of the same priority, by a random choice weighted by their weight.
+// intn returns a random number in [0, n).
+func srvOrder(targets []*tcping, intn func(n int) int) []*tcping {
+	sorted := slices.Clone(targets)
+	slices.SortStableFunc(sorted, func(a, b *tcping) int {
+		return cmp.Compare(a.srvRecord.Priority, b.srvRecord.Priority)
+	})
+
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].srvRecord.Priority == sorted[start].srvRecord.Priority {
+			end++
+		}
+		group := sorted[start:end]
+		start = end
+
+		// targets of weight 0 come first, so that they have a very small chance of being chosen
+		slices.SortStableFunc(group, func(a, b *tcping) int {
+			return cmp.Compare(min(a.srvRecord.Weight, 1), min(b.srvRecord.Weight, 1))
+		})
+
+		for i := range group {
+			sum := 0
+			for _, t := range group[i:] {
+				sum += int(t.srvRecord.Weight)
+			}
+
+			// the first target whose running sum of weights reaches the random number is chosen
+			pick := intn(sum + 1)
+			for j := i; j < len(group); j++ {
+				pick -= int(group[j].srvRecord.Weight)
+				if pick <= 0 {
+					chosen := group[j]
+					copy(group[i+1:j+1], group[i:j])
+					group[i] = chosen
+					break
+				}
+			}
+		}
+	}
+
+	return sorted
+}
+
+// lookupSRV looks up the SRV records of name with a timeout value of --dns-timeout,
+// through the server of --resolver or the system resolver.
+//
+// The records are returned by priority, with their lowest TTL,
+// which is only known with --resolver.
+func lookupSRV(tcping *tcping, name string) ([]srvRecord, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tcping.userInput.dnsTimeout)
+	defer cancel()
+
+	var records []srvRecord
+	var ttl time.Duration
+	var err error
+
+	if tcping.userInput.resolver != nil {
+		records, ttl, err = tcping.userInput.resolver.lookupSRV(ctx, name)
+	} else {
+		var srvs []*net.SRV
+		_, srvs, err = net.DefaultResolver.LookupSRV(ctx, "", "", name)
+		for _, srv := range srvs {
+			records = append(records, srvRecord{Target: srv.Target, Port: srv.Port, Priority: srv.Priority, Weight: srv.Weight})
+		}
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf(msg("srv.lookup-failed"), name, err)
+	}
+
+	for i := range records {
+		records[i].Target = strings.TrimSuffix(records[i].Target, ".")
+	}
+	// a target of "." means that the service isn't available, see RFC 2782
+	records = slices.DeleteFunc(records, func(r srvRecord) bool {
+		return r.Target == "" || r.Port == 0
+	})
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf(msg("srv.no-records"), name)
+	}
+
+	slices.SortFunc(records, func(a, b srvRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.Priority, b.Priority),
+			cmp.Compare(b.Weight, a.Weight),
+			cmp.Compare(a.Target, b.Target),
+			cmp.Compare(a.Port, b.Port),
+		)
+	})
+
+	return records, ttl, nil
+}
+
+// withSRVTargets adds the targets found in the SRV records after the start to targets.
+func withSRVTargets(targets []*tcping) []*tcping {
+	var all []*tcping
+	for _, t := range targets {
+		switch {
+		case t.srv == nil:
+			all = append(all, t)
+		case t.srv.first() == t:
+			all = append(all, t.srv.targets()...)
+		}
+	}
+	return all
+}
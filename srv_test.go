@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// srvData encodes the data of an SRV record.
+func srvData(priority, weight, port uint16, target string) []byte {
+	data := binary.BigEndian.AppendUint16(nil, priority)
+	data = binary.BigEndian.AppendUint16(data, weight)
+	data = binary.BigEndian.AppendUint16(data, port)
+	for _, label := range strings.Split(strings.TrimSuffix(target, "."), ".") {
+		if label != "" {
+			data = append(data, byte(len(label)))
+			data = append(data, label...)
+		}
+	}
+	return append(data, 0)
+}
+
+// fakeSRVServer is a DNS server answering SRV queries with records that can be changed.
+type fakeSRVServer struct {
+	mu      sync.Mutex
+	records []fakeDNSRecord
+}
+
+// set replaces the SRV records of the server.
+func (s *fakeSRVServer) set(records ...fakeDNSRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+// listen starts the server and returns the resolver to ask it.
+func (s *fakeSRVServer) listen(t *testing.T) *resolver {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go serveFakeDNSOverTCP(ln, func() map[uint16][]fakeDNSRecord {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return map[uint16][]fakeDNSRecord{dnsTypeSRV: s.records}
+	})
+
+	r, err := parseResolver("tcp://" + ln.Addr().String())
+	require.NoError(t, err)
+	return r
+}
+
+func TestIsSRVName(t *testing.T) {
+	for _, name := range []string{"_sip._tcp.example.com", "_xmpp-server._tcp.example.com.", "_http._tcp.local"} {
+		assert.True(t, isSRVName(name), name)
+	}
+	for _, name := range []string{"example.com", "_sip.example.com", "_._tcp.example.com", "_sip._tcp", "sip._tcp.example.com"} {
+		assert.False(t, isSRVName(name), name)
+	}
+}
+
+func TestSplitTargets(t *testing.T) {
+	targets, ok := splitTargets([]string{"example.com", "80", "_sip._tcp.example.com", "::1", "443"})
+	require.True(t, ok)
+	assert.Equal(t, [][]string{{"example.com", "80"}, {"_sip._tcp.example.com"}, {"::1", "443"}}, targets)
+
+	_, ok = splitTargets([]string{"_sip._tcp.example.com", "example.com"})
+	assert.False(t, ok, "a host without a port")
+}
+
+func TestSRVOrder(t *testing.T) {
+	target := func(priority, weight uint16) *tcping {
+		return &tcping{srvRecord: srvRecord{Priority: priority, Weight: weight}}
+	}
+	backup := target(20, 5)
+	zero, sixty, forty := target(10, 0), target(10, 60), target(10, 40)
+	targets := []*tcping{backup, zero, sixty, forty}
+
+	highest := func(n int) int { return n - 1 }
+	assert.Equal(t, []*tcping{forty, sixty, zero, backup}, srvOrder(targets, highest))
+
+	lowest := func(int) int { return 0 }
+	assert.Equal(t, []*tcping{zero, sixty, forty, backup}, srvOrder(targets, lowest),
+		"a target of weight 0 is only chosen first when the random number is 0")
+
+	assert.Equal(t, []*tcping{backup, zero, sixty, forty}, targets, "the targets are not reordered in place")
+}
+
+func TestParseDNSResponseSRV(t *testing.T) {
+	query, err := newDNSQuery(0x1234, "_sip._tcp.example.com", dnsTypeSRV)
+	require.NoError(t, err)
+
+	// the target is compressed to a pointer to the name of the question
+	records, _, err := parseDNSResponse(fakeDNSResponse(query, 0, map[uint16][]fakeDNSRecord{
+		dnsTypeSRV: {{dnsTypeSRV, 60, append(srvData(10, 20, 5060, "sip")[:10], 0xc0, 0x16)}},
+	}), 0x1234, dnsTypeSRV)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, srvRecord{Target: "sip.example.com", Port: 5060, Priority: 10, Weight: 20}, records[0].srv)
+
+	_, _, err = parseDNSResponse(fakeDNSResponse(query, 0, map[uint16][]fakeDNSRecord{
+		dnsTypeSRV: {{dnsTypeSRV, 60, []byte{0, 10, 0, 20}}},
+	}), 0x1234, dnsTypeSRV)
+	assert.Error(t, err, "a record too short to hold a target")
+}
+
+func TestLookupSRV(t *testing.T) {
+	var server fakeSRVServer
+	target := &tcping{userInput: userInput{resolver: server.listen(t), dnsTimeout: 5 * time.Second}}
+
+	server.set(
+		fakeDNSRecord{dnsTypeSRV, 300, srvData(20, 0, 5060, "backup.example.com.")},
+		fakeDNSRecord{dnsTypeSRV, 60, srvData(10, 5, 5060, "b.example.com.")},
+		fakeDNSRecord{dnsTypeSRV, 120, srvData(10, 50, 5061, "a.example.com.")},
+		fakeDNSRecord{dnsTypeSRV, 300, srvData(10, 0, 0, "c.example.com.")},
+	)
+	records, ttl, err := lookupSRV(target, "_sip._tcp.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []srvRecord{
+		{Target: "a.example.com", Port: 5061, Priority: 10, Weight: 50},
+		{Target: "b.example.com", Port: 5060, Priority: 10, Weight: 5},
+		{Target: "backup.example.com", Port: 5060, Priority: 20},
+	}, records, "by priority, the highest weight first, without the records of port 0")
+	assert.Equal(t, time.Minute, ttl, "the lowest TTL")
+
+	// a target of "." means that the service isn't available
+	server.set(fakeDNSRecord{dnsTypeSRV, 300, srvData(0, 0, 0, ".")})
+	_, _, err = lookupSRV(target, "_sip._tcp.example.com")
+	assert.Error(t, err)
+
+	server.set()
+	_, _, err = lookupSRV(target, "_sip._tcp.example.com")
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srvs, _, err := target.userInput.resolver.lookupSRV(ctx, "_sip._tcp.example.com")
+	require.NoError(t, err)
+	assert.Empty(t, srvs, "no records isn't an error of the resolver")
+}
+
+func TestSRVDiscoveryLookup(t *testing.T) {
+	var (
+		retryResolve         uint = 0
+		probesBeforeQuit     uint = 0
+		timeout                   = 1.0
+		secondsBetweenProbes      = 1.0
+		intName                   = ""
+		showFailuresOnly          = false
+		showSourceAddress         = false
+		useTLS                    = false
+	)
+
+	var server fakeSRVServer
+	first := fakeDNSRecord{dnsTypeSRV, 60, srvData(10, 10, 80, "127.0.0.1")}
+	second := fakeDNSRecord{dnsTypeSRV, 60, srvData(20, 0, 443, "127.0.0.2")}
+	server.set(first, second)
+
+	base := &tcping{
+		printer:   &dummyPrinter{},
+		userInput: userInput{resolver: server.listen(t), dnsTimeout: 5 * time.Second},
+	}
+	targets := newTargets(base, genericUserInputArgs{
+		retryResolve:         &retryResolve,
+		probesBeforeQuit:     &probesBeforeQuit,
+		timeout:              &timeout,
+		secondsBetweenProbes: &secondsBetweenProbes,
+		intName:              &intName,
+		showFailuresOnly:     &showFailuresOnly,
+		showSourceAddress:    &showSourceAddress,
+		tls:                  &useTLS,
+		srvInterval:          time.Minute,
+		args:                 []string{"_sip._tcp.example.com"},
+	})
+
+	require.Len(t, targets, 2)
+	assert.Equal(t, "_sip._tcp.example.com (127.0.0.1:80)", targets[0].userInput.target())
+	assert.Equal(t, "_sip._tcp.example.com (127.0.0.2:443)", targets[1].userInput.target())
+	assert.Same(t, targets[0].srv, targets[1].srv)
+	assert.Same(t, targets[0], targets[0].srv.first())
+
+	hostPorts := func(targets []*tcping) []string {
+		var hostPorts []string
+		for _, t := range targets {
+			hostPorts = append(hostPorts, t.userInput.hostPort())
+		}
+		return hostPorts
+	}
+	s := targets[0].srv
+
+	// the second target is replaced and the weight of the first one changes
+	server.set(
+		fakeDNSRecord{dnsTypeSRV, 60, srvData(10, 30, 80, "127.0.0.1")},
+		fakeDNSRecord{dnsTypeSRV, 60, srvData(20, 0, 8080, "127.0.0.3")},
+	)
+	s.lookup()
+
+	assert.Equal(t, []string{"127.0.0.1:80", "127.0.0.3:8080"}, hostPorts(s.activeTargets()))
+	assert.Equal(t, uint16(30), targets[0].srvRecord.Weight)
+	assert.Empty(t, targets[0].srvChanges)
+
+	require.Len(t, targets[1].srvChanges, 1)
+	assert.False(t, targets[1].srvChanges[0].Added)
+
+	all := withSRVTargets(targets)
+	require.Len(t, all, 3, "the removed target keeps its statistics")
+	require.Len(t, all[2].srvChanges, 1)
+	assert.True(t, all[2].srvChanges[0].Added)
+	assert.Equal(t, "_sip._tcp.example.com", all[2].userInput.srvName)
+	assert.NotNil(t, all[2].ticker)
+
+	// the removed target comes back
+	server.set(first, second)
+	s.lookup()
+
+	assert.Equal(t, []string{"127.0.0.1:80", "127.0.0.2:443"}, hostPorts(s.activeTargets()))
+	require.Len(t, targets[1].srvChanges, 2)
+	assert.True(t, targets[1].srvChanges[1].Added)
+	assert.Len(t, withSRVTargets(targets), 3)
+
+	// the targets are kept when the records can't be looked up
+	server.set()
+	s.lookup()
+	assert.Len(t, s.activeTargets(), 2)
+}
@@ -4,8 +4,14 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math"
+	"net/netip"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gookit/color"
@@ -16,6 +22,171 @@ const (
 	hourFormat = "15:04:05"
 )
 
+// probeDetails returns the details of a successful probe,
+// e.g. the DNS lookup, the TLS connection or the HTTP response, to append to its message.
+func probeDetails(info probeInfo) string {
+	details := ""
+
+	if dns := info.timings[phaseDNS]; dns > 0 {
+		details += fmt.Sprintf(" DNS=%.1f ms", dns)
+	}
+
+	if info.race != nil {
+		details += " HE=" + info.race.family().label()
+		if info.race.fellBack {
+			details += fmt.Sprintf(" fallback=%.1f ms", info.race.fallback)
+		}
+	}
+
+	if info.tls != nil {
+		details += fmt.Sprintf(" TLS=%.1f ms %s %s", info.tls.handshake, info.tls.version, info.tls.cipher)
+		if info.tls.alpn != "" {
+			details += fmt.Sprintf(" ALPN=%s", info.tls.alpn)
+		}
+		if info.tls.subject != "" {
+			details += " " + msgf("probe.certificate", info.tls.subject, info.tls.daysLeft())
+		}
+	}
+
+	if info.udp != nil {
+		details += " " + msgf("probe.udp-reply", info.udp.size)
+	}
+
+	if info.http != nil {
+		details += " " + msgf("probe.http", info.http.status, info.http.ttfb, info.http.total)
+	}
+
+	if info.state == stateFlapping {
+		details += " [" + stateFlapping.description() + "]"
+	}
+
+	return details
+}
+
+// certExpiryWarning returns a warning about a certificate that expires soon.
+func certExpiryWarning(info tlsInfo) string {
+	if days := info.daysLeft(); days >= 0 {
+		return msgf("probe.cert-expiring", info.subject, days, info.notAfter.Format(timeFormat))
+	}
+	return msgf("probe.cert-expired", info.subject, info.notAfter.Format(timeFormat))
+}
+
+// timestampPrefix returns the current time followed by a space,
+// to start the message of a probe with, if timestamps are shown.
+func timestampPrefix(showTimestamp bool) string {
+	if !showTimestamp {
+		return ""
+	}
+	return time.Now().Format(timeFormat) + " "
+}
+
+// probeAddress returns the address of the target as shown in the message of a probe:
+// its IP address, preceded by its hostname if it has one.
+func probeAddress(userInput userInput) string {
+	if userInput.hostname == "" {
+		return userInput.ip.String()
+	}
+	return fmt.Sprintf("%s (%s)", userInput.hostname, userInput.ip)
+}
+
+// replyMessage returns the message of a successful probe,
+// with its rtt rounded to the given number of decimals.
+func replyMessage(sourceAddr string, userInput userInput, streak uint, rtt float32, decimals int) string {
+	rttText := strconv.FormatFloat(float64(rtt), 'f', decimals, 32)
+	if userInput.showSourceAddress {
+		return msgf("probe.reply-source", probeAddress(userInput), userInput.port, sourceAddr, streak, rttText)
+	}
+	return msgf("probe.reply", probeAddress(userInput), userInput.port, streak, rttText)
+}
+
+// noReplyMessage returns the message of a failed probe.
+func noReplyMessage(userInput userInput, streak uint, kind errorKind, info probeInfo) string {
+	return msgf("probe.no-reply", probeAddress(userInput), userInput.port, streak, failureDescription(kind, info))
+}
+
+// stateChangeMessage returns the message of a target starting or stopping to flap.
+func stateChangeMessage(userInput userInput, state targetState) string {
+	if state == stateFlapping {
+		return msgf("probe.flapping", userInput.target(), durationToString(userInput.flapWindow), userInput.flapThreshold)
+	}
+	return msgf("probe.stopped-flapping", userInput.target(), state.description())
+}
+
+// addressMessage creates the line of an address in the summary of --all-addresses.
+func addressMessage(a addressStats) string {
+	message := msgf("address.stats", a.Addr, a.State.description(), a.PacketLoss)
+	if a.TotalSuccessfulProbes > 0 {
+		message += msgf("address.avg-rtt", a.LatencyAvg)
+	}
+	return message
+}
+
+// failingAddressesMessage lists the failing addresses of the summary of --all-addresses.
+func failingAddressesMessage(failing []netip.Addr) string {
+	addrs := make([]string, len(failing))
+	for i, addr := range failing {
+		addrs[i] = addr.String()
+	}
+	return fmt.Sprintf("%s: %s", msg("address.failing"), strings.Join(addrs, ", "))
+}
+
+// outageMessage describes an outage, e.g. "from ... to ... 5 seconds (5 probes) ongoing".
+func outageMessage(o outage) string {
+	message := fmt.Sprintf("%s %s (%s)", msgf("report.from-to", o.Start.Format(timeFormat), o.End.Format(timeFormat)),
+		durationToString(time.Duration(o.Duration*float64(time.Second))), msgf("report.probes", o.Probes))
+	if o.Ongoing {
+		message += " " + msg("report.ongoing")
+	}
+	return message
+}
+
+// outagesMessage is the line above the outages of the summary of --dual-stack.
+func outagesMessage(outages []outage) string {
+	if len(outages) == 0 {
+		return fmt.Sprintf("%s: %s", msg("report.outages"), msg("report.none"))
+	}
+	return fmt.Sprintf("%s: %s", msg("report.outages"), msgf("stats.times", len(outages)))
+}
+
+// dualStackCyclesMessage splits the cycles of the summary of --dual-stack by the IP versions that were down.
+func dualStackCyclesMessage(s dualStackSummary) string {
+	return msgf("dual.cycles", s.Cycles, s.BothUp, s.OnlyIPv4Down, s.OnlyIPv6Down, s.BothDown)
+}
+
+// singleFamilyOutagesMessage is the line above the periods in which only one IP version was down.
+func singleFamilyOutagesMessage(outages []familyOutage) string {
+	if len(outages) == 0 {
+		return fmt.Sprintf("%s: %s", msg("dual.single-family"), msg("report.none"))
+	}
+	return fmt.Sprintf("%s: %s", msg("dual.single-family"), msgf("stats.times", len(outages)))
+}
+
+// raceWinnersMessage lists the addresses that won a race in --happy-eyeballs mode,
+// e.g. "192.0.2.1 (8), 2001:db8::1 (2)".
+func raceWinnersMessage(winners []raceWinner) string {
+	list := make([]string, len(winners))
+	for i, w := range winners {
+		list[i] = fmt.Sprintf("%s (%d)", w.Addr, w.Wins)
+	}
+	return strings.Join(list, ", ")
+}
+
+// srvChangeMessage describes a change of the SRV records of the target, "added" or "removed".
+func srvChangeMessage(change srvChange) string {
+	if change.Added {
+		return msg("stats.srv-added")
+	}
+	return msg("stats.srv-removed")
+}
+
+// statisticsTarget returns the target in the title of the statistics.
+func statisticsTarget(t tcping) string {
+	if !t.destIsIP {
+		return fmt.Sprintf("%s (%s)", t.userInput.hostname, t.userInput.ip)
+	}
+	return t.userInput.hostname
+}
+
 // MARK: COLOR PRINTER
 
 var (
@@ -38,7 +209,7 @@ func newColorPrinter(showTimestamp *bool) *colorPrinter {
 }
 
 func (p *colorPrinter) printStart(hostname string, port uint16) {
-	colorLightCyan("正在TCP探测 %s 的 %d 端口\n", hostname, port)
+	colorLightCyan(msg("probe.start")+"\n", hostname, port)
 }
 
 func (p *colorPrinter) printStatistics(t tcping) {
@@ -50,13 +221,9 @@ func (p *colorPrinter) printStatistics(t tcping) {
 	}
 
 	/* general stats */
-	if !t.destIsIP {
-		colorYellow("\n--- %s (%s) TCPing 统计信息 ---\n", t.userInput.hostname, t.userInput.ip)
-	} else {
-		colorYellow("\n--- %s TCPing 统计信息 ---\n", t.userInput.hostname)
-	}
-	colorYellow("%d 个探测包发送到 %d 端口 | ", totalPackets, t.userInput.port)
-	colorYellow("%d 个探测包收到, ", t.totalSuccessfulProbes)
+	colorYellow("\n--- %s ---\n", msgf("stats.title", statisticsTarget(t)))
+	colorYellow(msg("stats.transmitted")+" | ", totalPackets, t.userInput.port)
+	colorYellow(msg("stats.received")+", ", t.totalSuccessfulProbes)
 
 	/* packet loss stats */
 	if packetLoss == 0 {
@@ -67,45 +234,68 @@ func (p *colorPrinter) printStatistics(t tcping) {
 		colorRed("%.2f%%", packetLoss)
 	}
 
-	colorYellow(" 丢失\n")
+	colorYellow(" %s\n", msg("stats.loss"))
 
 	/* successful packet stats */
-	colorYellow("成功探测包:   ")
+	colorYellow("%s: ", msg("stats.successful"))
 	colorGreen("%d\n", t.totalSuccessfulProbes)
 
 	/* unsuccessful packet stats */
-	colorYellow("失败探测包: ")
+	colorYellow("%s: ", msg("stats.unsuccessful"))
 	colorRed("%d\n", t.totalUnsuccessfulProbes)
 
-	colorYellow("最后一次成功探测: ")
+	/* failure reasons */
+	for _, kind := range errorKinds {
+		if count := t.failuresByKind[kind]; count > 0 {
+			colorYellow("  %s: ", kind.description())
+			colorRed("%d\n", count)
+		}
+	}
+
+	colorYellow("%s: ", msg("stats.last-success"))
 	if t.lastSuccessfulProbe.IsZero() {
-		colorRed("从未成功\n")
+		colorRed("%s\n", msg("stats.never-succeeded"))
 	} else {
 		colorGreen("%v\n", t.lastSuccessfulProbe.Format(timeFormat))
 	}
 
-	colorYellow("最后一次失败探测: ")
+	colorYellow("%s: ", msg("stats.last-failure"))
 	if t.lastUnsuccessfulProbe.IsZero() {
-		colorGreen("从未失败\n")
+		colorGreen("%s\n", msg("stats.never-failed"))
 	} else {
 		colorRed("%v\n", t.lastUnsuccessfulProbe.Format(timeFormat))
 	}
 
 	/* uptime and downtime stats */
-	colorYellow("总运行时间: ")
+	colorYellow("%s: ", msg("stats.total-uptime"))
 	colorGreen("%s\n", durationToString(t.totalUptime))
-	colorYellow("总暂停时间: ")
+	colorYellow("%s: ", msg("stats.total-downtime"))
 	colorRed("%s\n", durationToString(t.totalDowntime))
 
+	/* state stats */
+	colorYellow("%s: ", msg("stats.state"))
+	switch t.state() {
+	case stateUp:
+		colorGreen("%s\n", t.state().description())
+	case stateDown:
+		colorRed("%s\n", t.state().description())
+	default:
+		colorLightYellow("%s\n", t.state().description())
+	}
+	colorYellow("%s: ", msg("stats.state-changes"))
+	colorCyan(msg("stats.times"), t.stateChanges)
+	colorYellow(" | %s: ", msg("stats.flaps"))
+	colorCyan(msg("stats.times")+"\n", t.flaps)
+
 	/* longest uptime stats */
 	if t.longestUptime.duration != 0 {
 		uptime := durationToString(t.longestUptime.duration)
 
-		colorYellow("最长连续运行时间:   ")
+		colorYellow("%s: ", msg("stats.longest-uptime"))
 		colorGreen("%v ", uptime)
-		colorYellow("\n  从 ")
+		colorYellow("\n  %s ", msg("stats.from"))
 		colorLightBlue("%v ", t.longestUptime.start.Format(timeFormat))
-		colorYellow("\n  到 ")
+		colorYellow("\n  %s ", msg("stats.to"))
 		colorLightBlue("%v\n", t.longestUptime.end.Format(timeFormat))
 	}
 
@@ -113,134 +303,296 @@ func (p *colorPrinter) printStatistics(t tcping) {
 	if t.longestDowntime.duration != 0 {
 		downtime := durationToString(t.longestDowntime.duration)
 
-		colorYellow("最长连续暂停时间: ")
+		colorYellow("%s: ", msg("stats.longest-downtime"))
 		colorRed("%v ", downtime)
-		colorYellow("\n  从 ")
+		colorYellow("\n  %s ", msg("stats.from"))
 		colorLightBlue("%v ", t.longestDowntime.start.Format(timeFormat))
-		colorYellow("\n  到 ")
+		colorYellow("\n  %s ", msg("stats.to"))
 		colorLightBlue("%v\n", t.longestDowntime.end.Format(timeFormat))
 	}
 
 	/* resolve retry stats */
 	if !t.destIsIP {
-		colorYellow("重试解析主机名 ")
-		colorRed("%d ", t.retriedHostnameLookups)
-		colorYellow("次\n")
+		colorYellow("%s: ", msg("stats.retried-lookups"))
+		colorRed(msg("stats.times")+"\n", t.retriedHostnameLookups)
 
 		if len(t.hostnameChanges) >= 2 {
-			colorYellow("IP 地址变更:\n")
+			colorYellow("%s:\n", msg("stats.hostname-changes"))
 			for i := 0; i < len(t.hostnameChanges)-1; i++ {
-				colorYellow("  从 ")
+				colorYellow("  %s ", msg("stats.from"))
 				colorRed(t.hostnameChanges[i].Addr.String())
-				colorYellow(" 变更到 ")
+				colorYellow(" %s ", msg("stats.to"))
 				colorGreen(t.hostnameChanges[i+1].Addr.String())
-				colorYellow(" 于 ")
+				colorYellow(" %s ", msg("stats.at"))
 				colorLightBlue("%v\n", t.hostnameChanges[i+1].When.Format(timeFormat))
 			}
 		}
+
+		/* DNS stats */
+		if t.userInput.resolver != nil {
+			colorYellow("%s: ", msg("stats.resolver"))
+			colorLightBlue("%s\n", t.userInput.resolver)
+		}
+		colorYellow("%s\n", msgf("stats.dns-lookups", t.dns.lookups, t.dns.failures))
+		if result := t.dns.latency.result(); result.hasResults {
+			colorYellow("%s: ", msg("stats.dns-latency"))
+			colorCyan("%.1f/%.1f/%.1f", result.min, result.average, result.max)
+			colorYellow(" ms\n")
+		}
+	}
+
+	/* SRV stats */
+	if t.userInput.srvName != "" {
+		colorYellow("%s: ", msg("stats.srv-record"))
+		colorLightBlue("%s\n", msgf("stats.srv-record-value", t.userInput.srvName, t.srvRecord.Priority, t.srvRecord.Weight))
+
+		if len(t.srvChanges) > 0 {
+			colorYellow("%s:\n", msg("stats.srv-changes"))
+			for _, change := range t.srvChanges {
+				if change.Added {
+					colorGreen("  %s", srvChangeMessage(change))
+				} else {
+					colorRed("  %s", srvChangeMessage(change))
+				}
+				colorYellow(" %s ", msg("stats.at"))
+				colorLightBlue("%v\n", change.When.Format(timeFormat))
+			}
+		}
 	}
 
 	if t.rttResults.hasResults {
 		colorYellow("rtt ")
-		colorGreen("最低")
-		colorYellow("   ")
-		colorCyan("平均")
-		colorYellow("   ")
-		colorRed("最高: ")
-		colorGreen("\n    %.1f", t.rttResults.min)
-		colorYellow("   ")
+		colorGreen(msg("stats.min"))
+		colorYellow("/")
+		colorCyan(msg("stats.avg"))
+		colorYellow("/")
+		colorRed(msg("stats.max"))
+		colorYellow(": ")
+		colorGreen("%.1f", t.rttResults.min)
+		colorYellow("/")
 		colorCyan("%.1f", t.rttResults.average)
-		colorYellow("   ")
+		colorYellow("/")
 		colorRed("%.1f", t.rttResults.max)
-		colorYellow(" 毫秒\n")
+		colorYellow(" ms\n")
+
+		colorYellow("%s: ", msg("stats.rtt-percentiles"))
+		colorCyan("%.1f/%.1f/%.1f/%.1f", t.rttResults.median, t.rttResults.p90, t.rttResults.p95, t.rttResults.p99)
+		colorYellow(" ms\n")
+		colorYellow("%s: ", msg("stats.rtt-stddev"))
+		colorCyan("%.1f", t.rttResults.stdDev)
+		colorYellow(" ms | %s: ", msg("stats.jitter"))
+		colorCyan("%.1f", t.rttResults.jitter)
+		colorYellow(" ms\n")
+	}
+
+	/* Phase stats */
+	for p := range phaseCount {
+		if result := t.phases[p].result(); result.hasResults {
+			colorYellow("%s: ", msgf("stats.phase", p.description()))
+			colorCyan("%.1f/%.1f/%.1f", result.min, result.average, result.max)
+			colorYellow(" ms\n")
+		}
+	}
+
+	/* TLS stats */
+	if t.lastTLS != nil {
+		colorYellow("TLS: ")
+		colorCyan("%s %s\n", t.lastTLS.version, t.lastTLS.cipher)
+		colorYellow("%s: ", msg("stats.certificate"))
+		colorCyan("%s\n", t.lastTLS.subject)
+		colorYellow("%s: ", msg("stats.cert-expiry"))
+		if t.lastTLS.certExpiring(t.userInput) {
+			colorRed(msg("stats.days-left")+"\n", t.lastTLS.notAfter.Format(timeFormat), t.lastTLS.daysLeft())
+		} else {
+			colorGreen(msg("stats.days-left")+"\n", t.lastTLS.notAfter.Format(timeFormat), t.lastTLS.daysLeft())
+		}
+	}
+
+	/* Happy Eyeballs stats */
+	if t.userInput.happyEyeballs != nil {
+		race := t.race.summary()
+		colorYellow("%s\n", msgf("stats.races", race.Races, race.IPv6Wins, race.IPv4Wins))
+		colorYellow("%s: ", msg("stats.ipv6-losses"))
+		if race.IPv6Losses > 0 {
+			colorRed(msg("stats.times")+"\n", race.IPv6Losses)
+		} else {
+			colorGreen(msg("stats.times")+"\n", race.IPv6Losses)
+		}
+		if race.Fallbacks > 0 {
+			colorYellow("%s: ", msg("stats.fallbacks"))
+			colorCyan("%s\n", msgf("stats.fallback-times", race.Fallbacks, race.FallbackAvg, race.FallbackMax))
+		}
+		if len(race.Winners) > 0 {
+			colorYellow("%s: ", msg("stats.race-winners"))
+			colorCyan("%s\n", raceWinnersMessage(race.Winners))
+		}
+		if race.IPv6Losing {
+			colorRed("%s\n", msg("stats.ipv6-losing"))
+		}
 	}
 
 	colorYellow("--------------------------------------\n")
-	colorYellow("TCPing 开始时间: %v\n", t.startTime.Format(timeFormat))
+	colorYellow("%s: %v\n", msg("stats.started"), t.startTime.Format(timeFormat))
 
 	/* If the program was not terminated, no need to show the end time */
 	if !t.endTime.IsZero() {
-		colorYellow("TCPing 结束时间: %v\n", t.endTime.Format(timeFormat))
+		colorYellow("%s: %v\n", msg("stats.ended"), t.endTime.Format(timeFormat))
 	}
 
 	durationTime := time.Time{}.Add(t.totalDowntime + t.totalUptime)
-	colorYellow("持续时间: %v\n\n", durationTime.Format(hourFormat))
+	colorYellow("%s: %v\n\n", msg("stats.duration"), durationTime.Format(hourFormat))
 }
 
-func (p *colorPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32) {
-	timestamp := ""
-	if *p.showTimestamp {
-		timestamp = time.Now().Format(timeFormat)
+func (p *colorPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
+	colorLightGreen("%s%s%s\n", timestampPrefix(*p.showTimestamp), replyMessage(sourceAddr, userInput, streak, rtt, 1), probeDetails(info))
+
+	if info.tls != nil && info.tls.certExpiring(userInput) {
+		colorRed("  %s\n", certExpiryWarning(*info.tls))
 	}
-	if userInput.hostname == "" {
-		if timestamp == "" {
-			if userInput.showSourceAddress {
-				colorLightGreen("响应自 %s 端口 %d 使用 %s TCP_conn=%d 时间=%.1f ms\n", userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				colorLightGreen("响应自 %s 端口 %d TCP_conn=%d 时间=%.1f ms\n", userInput.ip.String(), userInput.port, streak, rtt)
-			}
+}
+
+func (p *colorPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
+	colorRed("%s%s\n", timestampPrefix(*p.showTimestamp), noReplyMessage(userInput, streak, kind, info))
+}
+
+func (p *colorPrinter) printTotalDownTime(userInput userInput, downtime time.Duration) {
+	colorYellow(msg("probe.downtime")+"\n", probeAddress(userInput), userInput.port, durationToString(downtime))
+}
+
+func (p *colorPrinter) printStateChange(userInput userInput, state targetState) {
+	colorLightYellow("%s\n", stateChangeMessage(userInput, state))
+}
+
+func (p *colorPrinter) printCheckResult(userInput userInput, result checkResult) {
+	if result.status == checkHealthy {
+		colorGreen("%s %s\n", userInput.target(), result.description())
+	} else {
+		colorRed("%s %s (%s)\n", userInput.target(), result.description(), msgf("check.exit-code", result.status))
+	}
+}
+
+func (p *colorPrinter) printAddressSummary(summary addressSummary) {
+	colorYellow("\n--- %s ---\n", msgf("address.title", summary.target()))
+	for _, a := range summary.Addresses {
+		if a.failing() {
+			colorRed("%s\n", addressMessage(a))
 		} else {
-			if userInput.showSourceAddress {
-				colorLightGreen("%s 响应自 %s 端口 %d 使用 %s TCP_conn=%d 时间=%.1f ms\n", timestamp, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				colorLightGreen("%s 响应自 %s 端口 %d TCP_conn=%d 时间=%.1f ms\n", timestamp, userInput.ip.String(), userInput.port, streak, rtt)
-			}
+			colorGreen("%s\n", addressMessage(a))
 		}
+	}
+
+	if failing := summary.failing(); len(failing) > 0 {
+		colorRed("%s\n", failingAddressesMessage(failing))
 	} else {
-		if timestamp == "" {
-			if userInput.showSourceAddress {
-				colorLightGreen("响应自 %s (%s) 端口 %d 使用 %s TCP_conn=%d 时间=%.1f ms\n", userInput.hostname, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				colorLightGreen("响应自 %s (%s) 端口 %d TCP_conn=%d 时间=%.1f ms\n", userInput.hostname, userInput.ip.String(), userInput.port, streak, rtt)
-			}
+		colorGreen("%s\n", msg("address.all-up"))
+	}
+}
+
+func (p *colorPrinter) printDualStackSummary(summary dualStackSummary) {
+	colorYellow("\n--- %s ---\n", msgf("dual.title", summary.target()))
+	for _, f := range summary.Families {
+		if f.failing() {
+			colorRed("%s %s\n", f.Family.label(), addressMessage(f.addressStats))
 		} else {
-			if userInput.showSourceAddress {
-				colorLightGreen("%s 响应自 %s (%s) 端口 %d 使用 %s TCP_conn=%d 时间=%.1f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				colorLightGreen("%s 响应自 %s (%s) 端口 %d TCP_conn=%d 时间=%.1f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, streak, rtt)
-			}
+			colorGreen("%s %s\n", f.Family.label(), addressMessage(f.addressStats))
+		}
+
+		colorYellow("  %s\n", outagesMessage(f.Outages))
+		for _, o := range f.Outages {
+			colorRed("    %s\n", outageMessage(o))
 		}
 	}
-}
 
-func (p *colorPrinter) printProbeFail(userInput userInput, streak uint) {
-	timestamp := ""
-	if *p.showTimestamp {
-		timestamp = time.Now().Format(timeFormat)
+	colorYellow("%s\n", dualStackCyclesMessage(summary))
+	if summary.RTTDelta != nil {
+		colorYellow(msg("dual.rtt-delta")+"\n", *summary.RTTDelta)
 	}
-	if userInput.hostname == "" {
-		if timestamp == "" {
-			colorRed("No 响应自 %s 端口 %d TCP_conn=%d\n", userInput.ip, userInput.port, streak)
-		} else {
-			colorRed("%s 未收到来自 %s 端口 %d 的响应 TCP_conn=%d\n", timestamp, userInput.ip, userInput.port, streak)
+
+	if len(summary.SingleFamilyOutages) == 0 {
+		colorGreen("%s\n", singleFamilyOutagesMessage(summary.SingleFamilyOutages))
+	} else {
+		colorRed("%s\n", singleFamilyOutagesMessage(summary.SingleFamilyOutages))
+	}
+	for _, o := range summary.SingleFamilyOutages {
+		colorRed("  %s %s\n", o.Family.label(), outageMessage(o.outage))
+	}
+}
+
+func (p *colorPrinter) printSessions(file string, sessions []reportSession) {
+	colorYellow(msg("report.sessions")+"\n", file, len(sessions))
+	for _, s := range sessions {
+		colorLightCyan("  %s", s.Name)
+		colorYellow(" %s %s", s.Target, msgf("report.probes", s.Probes))
+		if s.Start != nil {
+			colorYellow(" %s", msgf("report.from-to", s.Start.Format(timeFormat), s.End.Format(timeFormat)))
 		}
+		fmt.Println()
+	}
+}
+
+func (p *colorPrinter) printReport(r sessionReport) {
+	colorYellow("--- %s ---\n", msgf("report.title", r.session.Name))
+
+	if !r.timed {
+		colorLightYellow("%s\n", msg("report.untimed"))
 	} else {
-		if timestamp == "" {
-			colorRed("未收到来自 %s (%s) 端口 %d 的响应 TCP_conn=%d\n", userInput.hostname, userInput.ip, userInput.port, streak)
+		colorYellow("%s: ", msg("report.outages"))
+		if len(r.outages) == 0 {
+			colorGreen("%s\n", msg("report.none"))
 		} else {
-			colorRed("%s 未收到来自 %s (%s) 端口 %d 的响应 TCP_conn=%d\n", timestamp, userInput.hostname, userInput.ip, userInput.port, streak)
+			colorRed(msg("stats.times")+"\n", len(r.outages))
+		}
+		for _, o := range r.outages {
+			colorYellow("  %s ", msgf("report.from-to", o.Start.Format(timeFormat), o.End.Format(timeFormat)))
+			colorRed("%s", durationToString(time.Duration(o.Duration*float64(time.Second))))
+			colorYellow(" (%s)", msgf("report.probes", o.Probes))
+			if o.Ongoing {
+				colorRed(" %s", msg("report.ongoing"))
+			}
+			fmt.Println()
+		}
+
+		colorYellow("%s:\n", msg("report.hourly"))
+		for _, h := range r.hourly {
+			colorYellow("  %s ", h.Hour.Format(timeFormat))
+			switch {
+			case h.Availability == 100:
+				colorGreen("%6.2f%%", h.Availability)
+			case h.Availability >= 80:
+				colorLightYellow("%6.2f%%", h.Availability)
+			default:
+				colorRed("%6.2f%%", h.Availability)
+			}
+			colorYellow(" (%d/%d)\n", h.SuccessfulProbes, h.Probes)
 		}
 	}
-}
 
-func (p *colorPrinter) printTotalDownTime(downtime time.Duration) {
-	colorYellow("未收到响应 %s\n", durationToString(downtime))
+	colorYellow("%s:\n", msg("report.histogram"))
+	largest := largestCount(r.histogram)
+	for _, b := range r.histogram {
+		colorYellow("  ≤ %-6s %6d ", b.Le, b.Count)
+		colorLightBlue("%s\n", histogramBar(b.Count, largest, histogramWidth))
+	}
 }
 
 func (p *colorPrinter) printRetryingToResolve(hostname string) {
-	colorLightYellow("重试解析主机名 %s\n", hostname)
+	colorLightYellow(msg("probe.retrying")+"\n", hostname)
 }
 
 func (p *colorPrinter) printInfo(format string, args ...any) {
 	colorLightBlue(format+"\n", args...)
 }
 
+func (p *colorPrinter) printWarning(format string, args ...any) {
+	colorYellow(format+"\n", args...)
+}
+
 func (p *colorPrinter) printError(format string, args ...any) {
 	colorRed(format+"\n", args...)
 }
 
 func (p *colorPrinter) printVersion() {
-	colorGreen("TCPing 版本 %s\n", version)
+	colorGreen(msg("usage.version")+"\n", version)
 }
 
 // MARK: PLAIN PRINTER
@@ -254,7 +606,7 @@ func newPlainPrinter(showTimestamp *bool) *plainPrinter {
 }
 
 func (p *plainPrinter) printStart(hostname string, port uint16) {
-	fmt.Printf("TCPinging %s 端口 %d\n", hostname, port)
+	fmt.Printf(msg("probe.start")+"\n", hostname, port)
 }
 
 func (p *plainPrinter) printStatistics(t tcping) {
@@ -266,164 +618,268 @@ func (p *plainPrinter) printStatistics(t tcping) {
 	}
 
 	/* general stats */
-	if !t.destIsIP {
-		fmt.Printf("\n--- %s (%s) TCPing 统计信息 ---\n", t.userInput.hostname, t.userInput.ip)
-	} else {
-		fmt.Printf("\n--- %s TCPing 统计信息 ---\n", t.userInput.hostname)
-	}
-	fmt.Printf("%d 个探测包 发送到 %s 端口 %d | %d 个响应包\n", totalPackets, t.userInput.hostname, t.userInput.port, t.totalSuccessfulProbes)
+	fmt.Printf("\n--- %s ---\n", msgf("stats.title", statisticsTarget(t)))
+	fmt.Printf(msg("stats.transmitted")+" | "+msg("stats.received")+"\n", totalPackets, t.userInput.port, t.totalSuccessfulProbes)
 
 	/* packet loss stats */
-	fmt.Printf("%.2f%% 包丢失\n", packetLoss)
+	fmt.Printf("%.2f%% %s\n", packetLoss, msg("stats.loss"))
 
 	/* successful packet stats */
-	fmt.Printf("%d 个成功探测包\n", t.totalSuccessfulProbes)
+	fmt.Printf("%s: %d\n", msg("stats.successful"), t.totalSuccessfulProbes)
 
 	/* unsuccessful packet stats */
-	fmt.Printf("%d 个失败探测包\n", t.totalUnsuccessfulProbes)
+	fmt.Printf("%s: %d\n", msg("stats.unsuccessful"), t.totalUnsuccessfulProbes)
 
-	fmt.Printf("最后一个成功探测包:   ")
+	/* failure reasons */
+	for _, kind := range errorKinds {
+		if count := t.failuresByKind[kind]; count > 0 {
+			fmt.Printf("  %s: %d\n", kind.description(), count)
+		}
+	}
+
+	fmt.Printf("%s: ", msg("stats.last-success"))
 	if t.lastSuccessfulProbe.IsZero() {
-		fmt.Printf("从未成功\n")
+		fmt.Printf("%s\n", msg("stats.never-succeeded"))
 	} else {
 		fmt.Printf("%v\n", t.lastSuccessfulProbe.Format(timeFormat))
 	}
 
-	fmt.Printf("最后一个失败探测包: ")
+	fmt.Printf("%s: ", msg("stats.last-failure"))
 	if t.lastUnsuccessfulProbe.IsZero() {
-		fmt.Printf("从未失败\n")
+		fmt.Printf("%s\n", msg("stats.never-failed"))
 	} else {
 		fmt.Printf("%v\n", t.lastUnsuccessfulProbe.Format(timeFormat))
 	}
 
 	/* uptime and downtime stats */
-	fmt.Printf("总运行时间: %s\n", durationToString(t.totalUptime))
-	fmt.Printf("总暂停时间: %s\n", durationToString(t.totalDowntime))
+	fmt.Printf("%s: %s\n", msg("stats.total-uptime"), durationToString(t.totalUptime))
+	fmt.Printf("%s: %s\n", msg("stats.total-downtime"), durationToString(t.totalDowntime))
+	fmt.Printf("%s: %s\n", msg("stats.state"), t.state().description())
+	fmt.Printf("%s: %s | %s: %s\n", msg("stats.state-changes"), msgf("stats.times", t.stateChanges), msg("stats.flaps"), msgf("stats.times", t.flaps))
 
 	/* longest uptime stats */
 	if t.longestUptime.duration != 0 {
 		uptime := durationToString(t.longestUptime.duration)
 
-		fmt.Printf("最长连续运行时间: ")
+		fmt.Printf("%s: ", msg("stats.longest-uptime"))
 		fmt.Printf("%v ", uptime)
-		fmt.Printf("从 %v ", t.longestUptime.start.Format(timeFormat))
-		fmt.Printf("到 %v\n", t.longestUptime.end.Format(timeFormat))
+		fmt.Printf("%s %v ", msg("stats.from"), t.longestUptime.start.Format(timeFormat))
+		fmt.Printf("%s %v\n", msg("stats.to"), t.longestUptime.end.Format(timeFormat))
 	}
 
 	/* longest downtime stats */
 	if t.longestDowntime.duration != 0 {
 		downtime := durationToString(t.longestDowntime.duration)
 
-		fmt.Printf("最长连续暂停时间: %v ", downtime)
-		fmt.Printf("从 %v ", t.longestDowntime.start.Format(timeFormat))
-		fmt.Printf("到 %v\n", t.longestDowntime.end.Format(timeFormat))
+		fmt.Printf("%s: %v ", msg("stats.longest-downtime"), downtime)
+		fmt.Printf("%s %v ", msg("stats.from"), t.longestDowntime.start.Format(timeFormat))
+		fmt.Printf("%s %v\n", msg("stats.to"), t.longestDowntime.end.Format(timeFormat))
 	}
 
 	/* resolve retry stats */
 	if !t.destIsIP {
-		fmt.Printf("重试解析主机名 %d 次\n", t.retriedHostnameLookups)
+		fmt.Printf("%s: %s\n", msg("stats.retried-lookups"), msgf("stats.times", t.retriedHostnameLookups))
 
 		if len(t.hostnameChanges) >= 2 {
-			fmt.Printf("主机名解析变更:\n")
+			fmt.Printf("%s:\n", msg("stats.hostname-changes"))
 			for i := 0; i < len(t.hostnameChanges)-1; i++ {
-				fmt.Printf("  从 %s", t.hostnameChanges[i].Addr.String())
-				fmt.Printf(" 到 %s", t.hostnameChanges[i+1].Addr.String())
-				fmt.Printf(" 于 %v\n", t.hostnameChanges[i+1].When.Format(timeFormat))
+				fmt.Printf("  %s %s", msg("stats.from"), t.hostnameChanges[i].Addr.String())
+				fmt.Printf(" %s %s", msg("stats.to"), t.hostnameChanges[i+1].Addr.String())
+				fmt.Printf(" %s %v\n", msg("stats.at"), t.hostnameChanges[i+1].When.Format(timeFormat))
+			}
+		}
+
+		if t.userInput.resolver != nil {
+			fmt.Printf("%s: %s\n", msg("stats.resolver"), t.userInput.resolver)
+		}
+		fmt.Printf("%s\n", msgf("stats.dns-lookups", t.dns.lookups, t.dns.failures))
+		if result := t.dns.latency.result(); result.hasResults {
+			fmt.Printf("%s: %.1f/%.1f/%.1f ms\n", msg("stats.dns-latency"), result.min, result.average, result.max)
+		}
+	}
+
+	if t.userInput.srvName != "" {
+		fmt.Printf("%s: %s\n", msg("stats.srv-record"), msgf("stats.srv-record-value", t.userInput.srvName, t.srvRecord.Priority, t.srvRecord.Weight))
+
+		if len(t.srvChanges) > 0 {
+			fmt.Printf("%s:\n", msg("stats.srv-changes"))
+			for _, change := range t.srvChanges {
+				fmt.Printf("  %s %s %v\n", srvChangeMessage(change), msg("stats.at"), change.When.Format(timeFormat))
 			}
 		}
 	}
 
 	if t.rttResults.hasResults {
-		fmt.Printf("rtt 最小/平均/最大: ")
+		fmt.Printf("rtt %s/%s/%s: ", msg("stats.min"), msg("stats.avg"), msg("stats.max"))
 		fmt.Printf("%.1f/%.1f/%.1f ms\n", t.rttResults.min, t.rttResults.average, t.rttResults.max)
+		fmt.Printf("%s: ", msg("stats.rtt-percentiles"))
+		fmt.Printf("%.1f/%.1f/%.1f/%.1f ms\n", t.rttResults.median, t.rttResults.p90, t.rttResults.p95, t.rttResults.p99)
+		fmt.Printf("%s: %.1f ms | %s: %.1f ms\n", msg("stats.rtt-stddev"), t.rttResults.stdDev, msg("stats.jitter"), t.rttResults.jitter)
+	}
+
+	for p := range phaseCount {
+		if result := t.phases[p].result(); result.hasResults {
+			fmt.Printf("%s: %.1f/%.1f/%.1f ms\n", msgf("stats.phase", p.description()), result.min, result.average, result.max)
+		}
+	}
+
+	if t.lastTLS != nil {
+		fmt.Printf("TLS: %s %s\n", t.lastTLS.version, t.lastTLS.cipher)
+		fmt.Printf("%s: %s\n", msg("stats.certificate"), t.lastTLS.subject)
+		fmt.Printf("%s: %s\n", msg("stats.cert-expiry"), msgf("stats.days-left", t.lastTLS.notAfter.Format(timeFormat), t.lastTLS.daysLeft()))
+	}
+
+	if t.userInput.happyEyeballs != nil {
+		race := t.race.summary()
+		fmt.Printf("%s\n", msgf("stats.races", race.Races, race.IPv6Wins, race.IPv4Wins))
+		fmt.Printf("%s: %s\n", msg("stats.ipv6-losses"), msgf("stats.times", race.IPv6Losses))
+		if race.Fallbacks > 0 {
+			fmt.Printf("%s: %s\n", msg("stats.fallbacks"), msgf("stats.fallback-times", race.Fallbacks, race.FallbackAvg, race.FallbackMax))
+		}
+		if len(race.Winners) > 0 {
+			fmt.Printf("%s: %s\n", msg("stats.race-winners"), raceWinnersMessage(race.Winners))
+		}
+		if race.IPv6Losing {
+			fmt.Printf("%s\n", msg("stats.ipv6-losing"))
+		}
 	}
 
 	fmt.Printf("--------------------------------------\n")
-	fmt.Printf("TCPing 开始时间: %v\n", t.startTime.Format(timeFormat))
+	fmt.Printf("%s: %v\n", msg("stats.started"), t.startTime.Format(timeFormat))
 
 	/* If the program was not terminated, no need to show the end time */
 	if !t.endTime.IsZero() {
-		fmt.Printf("TCPing 结束时间:   %v\n", t.endTime.Format(timeFormat))
+		fmt.Printf("%s: %v\n", msg("stats.ended"), t.endTime.Format(timeFormat))
 	}
 
 	durationTime := time.Time{}.Add(t.totalDowntime + t.totalUptime)
-	fmt.Printf("持续时间 (HH:MM:SS): %v\n\n", durationTime.Format(hourFormat))
+	fmt.Printf("%s: %v\n\n", msg("stats.duration"), durationTime.Format(hourFormat))
 }
 
-func (p *plainPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32) {
-	timestamp := ""
-	if *p.showTimestamp {
-		timestamp = time.Now().Format(timeFormat)
+func (p *plainPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
+	fmt.Printf("%s%s%s\n", timestampPrefix(*p.showTimestamp), replyMessage(sourceAddr, userInput, streak, rtt, 3), probeDetails(info))
+
+	if info.tls != nil && info.tls.certExpiring(userInput) {
+		fmt.Printf("  %s\n", certExpiryWarning(*info.tls))
 	}
-	if userInput.hostname == "" {
-		if timestamp == "" {
-			if userInput.showSourceAddress {
-				fmt.Printf("回复 %s 端口 %d 使用 %s TCP_conn=%d 时间=%.1f ms\n", userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				fmt.Printf("回复 %s 端口 %d TCP_conn=%d 时间=%.1f ms\n", userInput.ip.String(), userInput.port, streak, rtt)
-			}
-		} else {
-			if userInput.showSourceAddress {
-				fmt.Printf("%s 回复 %s 端口 %d 使用 %s TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				fmt.Printf("%s 回复 %s 端口 %d TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.ip.String(), userInput.port, streak, rtt)
-			}
-		}
+}
+
+func (p *plainPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
+	fmt.Printf("%s%s\n", timestampPrefix(*p.showTimestamp), noReplyMessage(userInput, streak, kind, info))
+}
+
+func (p *plainPrinter) printTotalDownTime(userInput userInput, downtime time.Duration) {
+	fmt.Printf(msg("probe.downtime")+"\n", probeAddress(userInput), userInput.port, durationToString(downtime))
+}
+
+func (p *plainPrinter) printStateChange(userInput userInput, state targetState) {
+	fmt.Printf("%s\n", stateChangeMessage(userInput, state))
+}
+
+func (p *plainPrinter) printCheckResult(userInput userInput, result checkResult) {
+	if result.status == checkHealthy {
+		fmt.Printf("%s %s\n", userInput.target(), result.description())
 	} else {
-		if timestamp == "" {
-			if userInput.showSourceAddress {
-				fmt.Printf("%s 回复 %s (%s) 端口 %d 使用 %s TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				fmt.Printf("%s 回复 %s (%s) 端口 %d TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, streak, rtt)
-			}
-		} else {
-			if userInput.showSourceAddress {
-				fmt.Printf("%s 回复 %s (%s) 端口 %d 使用 %s TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, sourceAddr, streak, rtt)
-			} else {
-				fmt.Printf("%s 回复 %s (%s) 端口 %d TCP_conn=%d 时间=%.3f ms\n", timestamp, userInput.hostname, userInput.ip.String(), userInput.port, streak, rtt)
-			}
-		}
+		fmt.Printf("%s %s (%s)\n", userInput.target(), result.description(), msgf("check.exit-code", result.status))
 	}
 }
 
-func (p *plainPrinter) printProbeFail(userInput userInput, streak uint) {
-	timestamp := ""
-	if *p.showTimestamp {
-		timestamp = time.Now().Format(timeFormat)
+func (p *plainPrinter) printAddressSummary(summary addressSummary) {
+	fmt.Printf("\n--- %s ---\n", msgf("address.title", summary.target()))
+	for _, a := range summary.Addresses {
+		fmt.Printf("%s\n", addressMessage(a))
 	}
-	if userInput.hostname == "" {
-		if timestamp == "" {
-			fmt.Printf("%s 没有回复 %s 端口 %d TCP_conn=%d\n", timestamp, userInput.ip, userInput.port, streak)
-		} else {
-			fmt.Printf("%s 没有回复 %s 端口 %d TCP_conn=%d\n", timestamp, userInput.ip, userInput.port, streak)
+
+	if failing := summary.failing(); len(failing) > 0 {
+		fmt.Printf("%s\n", failingAddressesMessage(failing))
+	} else {
+		fmt.Printf("%s\n", msg("address.all-up"))
+	}
+}
+
+func (p *plainPrinter) printDualStackSummary(summary dualStackSummary) {
+	fmt.Printf("\n--- %s ---\n", msgf("dual.title", summary.target()))
+	for _, f := range summary.Families {
+		fmt.Printf("%s %s\n", f.Family.label(), addressMessage(f.addressStats))
+		fmt.Printf("  %s\n", outagesMessage(f.Outages))
+		for _, o := range f.Outages {
+			fmt.Printf("    %s\n", outageMessage(o))
 		}
+	}
+
+	fmt.Printf("%s\n", dualStackCyclesMessage(summary))
+	if summary.RTTDelta != nil {
+		fmt.Printf(msg("dual.rtt-delta")+"\n", *summary.RTTDelta)
+	}
+
+	fmt.Printf("%s\n", singleFamilyOutagesMessage(summary.SingleFamilyOutages))
+	for _, o := range summary.SingleFamilyOutages {
+		fmt.Printf("  %s %s\n", o.Family.label(), outageMessage(o.outage))
+	}
+}
+
+func (p *plainPrinter) printSessions(file string, sessions []reportSession) {
+	fmt.Printf(msg("report.sessions")+"\n", file, len(sessions))
+	for _, s := range sessions {
+		fmt.Printf("  %s %s %s", s.Name, s.Target, msgf("report.probes", s.Probes))
+		if s.Start != nil {
+			fmt.Printf(" %s", msgf("report.from-to", s.Start.Format(timeFormat), s.End.Format(timeFormat)))
+		}
+		fmt.Println()
+	}
+}
+
+func (p *plainPrinter) printReport(r sessionReport) {
+	fmt.Printf("--- %s ---\n", msgf("report.title", r.session.Name))
+
+	if !r.timed {
+		fmt.Printf("%s\n", msg("report.untimed"))
 	} else {
-		if timestamp == "" {
-			fmt.Printf("%s 没有回复 %s (%s) 端口 %d TCP_conn=%d\n", timestamp, userInput.hostname, userInput.ip, userInput.port, streak)
+		if len(r.outages) == 0 {
+			fmt.Printf("%s: %s\n", msg("report.outages"), msg("report.none"))
 		} else {
-			fmt.Printf("%s 没有回复 %s (%s) 端口 %d TCP_conn=%d\n", timestamp, userInput.hostname, userInput.ip, userInput.port, streak)
+			fmt.Printf("%s: %s\n", msg("report.outages"), msgf("stats.times", len(r.outages)))
+		}
+		for _, o := range r.outages {
+			fmt.Printf("  %s %s (%s)", msgf("report.from-to", o.Start.Format(timeFormat), o.End.Format(timeFormat)),
+				durationToString(time.Duration(o.Duration*float64(time.Second))), msgf("report.probes", o.Probes))
+			if o.Ongoing {
+				fmt.Printf(" %s", msg("report.ongoing"))
+			}
+			fmt.Println()
+		}
+
+		fmt.Printf("%s:\n", msg("report.hourly"))
+		for _, h := range r.hourly {
+			fmt.Printf("  %s %6.2f%% (%d/%d)\n", h.Hour.Format(timeFormat), h.Availability, h.SuccessfulProbes, h.Probes)
 		}
 	}
-}
 
-func (p *plainPrinter) printTotalDownTime(downtime time.Duration) {
-	fmt.Printf("%s 没有回复任何内容\n", durationToString(downtime))
+	fmt.Printf("%s:\n", msg("report.histogram"))
+	largest := largestCount(r.histogram)
+	for _, b := range r.histogram {
+		fmt.Printf("  ≤ %-6s %6d %s\n", b.Le, b.Count, histogramBar(b.Count, largest, histogramWidth))
+	}
 }
 
 func (p *plainPrinter) printRetryingToResolve(hostname string) {
-	fmt.Printf("%s 重试解析主机名 %s\n", time.Now().Format(timeFormat), hostname)
+	fmt.Printf("%s%s\n", timestampPrefix(true), msgf("probe.retrying", hostname))
 }
 
 func (p *plainPrinter) printInfo(format string, args ...any) {
 	fmt.Printf(format+"\n", args...)
 }
 
+func (p *plainPrinter) printWarning(format string, args ...any) {
+	fmt.Printf(format+"\n", args...)
+}
+
 func (p *plainPrinter) printError(format string, args ...any) {
 	fmt.Printf(format+"\n", args...)
 }
 
 func (p *plainPrinter) printVersion() {
-	fmt.Printf("%s TCPING 版本 %s\n", time.Now().Format(timeFormat), version)
+	fmt.Printf("%s%s\n", timestampPrefix(true), msgf("usage.version", version))
 }
 
 // MARK: JSON PRINTER
@@ -462,16 +918,39 @@ const (
 	retryEvent JSONEventType = "retry"
 	// retrySuccessEvent is an event type for [printTotalDowntime] method.
 	retrySuccessEvent JSONEventType = "retry-success"
+	// stateChangeEvent is an event type for [printStateChange] method.
+	stateChangeEvent JSONEventType = "state-change"
+	// checkEvent is an event type for [printCheckResult] method.
+	checkEvent JSONEventType = "check"
+	// addressSummaryEvent is an event type for [printAddressSummary] method.
+	addressSummaryEvent JSONEventType = "address-summary"
+	// dualStackEvent is an event type for [printDualStackSummary] method.
+	dualStackEvent JSONEventType = "dual-stack"
+	// sessionsEvent is an event type for [printSessions] method.
+	sessionsEvent JSONEventType = "sessions"
+	// reportEvent is an event type for [printReport] method.
+	reportEvent JSONEventType = "report"
 	// statisticsEvent is a event type for [printStatistics] method.
 	statisticsEvent JSONEventType = "statistics"
 	// infoEvent is a event type for [printInfo] method.
 	infoEvent JSONEventType = "info"
+	// warningEvent is a event type for [printWarning] method.
+	warningEvent JSONEventType = "warning"
 	// versionEvent is a event type for [printVersion] method.
 	versionEvent JSONEventType = "version"
 	// errorEvent is a event type for [printError] method.
 	errorEvent JSONEventType = "error"
 )
 
+// phaseData is the min/avg/max duration of a probe phase in ms.
+//
+// The fields are strings for the same reason as LatencyMin.
+type phaseData struct {
+	Min string `json:"min"`
+	Avg string `json:"avg"`
+	Max string `json:"max"`
+}
+
 // JSONData contains all possible fields for JSON output.
 // Because one event usually contains only a subset of fields,
 // other fields will be omitted in the output.
@@ -494,15 +973,81 @@ type JSONData struct {
 	Port                 uint16           `json:"port,omitempty"`
 	Rtt                  float32          `json:"time,omitempty"`
 
+	// Resolver is the DNS server of --resolver.
+	Resolver string `json:"resolver,omitempty"`
+	// DNSLookups and DNSFailures count the lookups of the hostname for the stats event.
+	DNSLookups  uint `json:"dns_lookups,omitempty"`
+	DNSFailures uint `json:"dns_failures,omitempty"`
+	// DNSLookupTime is the min/avg/max duration of the successful lookups.
+	DNSLookupTime *phaseData `json:"dns_lookup_time,omitempty"`
+
+	// SRVName is the SRV name the target was found through, and SRVRecord its record.
+	SRVName   string     `json:"srv_name,omitempty"`
+	SRVRecord *srvRecord `json:"srv_record,omitempty"`
+	// SRVChanges are the times the target was added to or removed from the SRV records.
+	SRVChanges []srvChange `json:"srv_changes,omitempty"`
+
 	// Success is a special field from probe messages, containing information
 	// whether request was successful or not.
 	// It's a pointer on purpose, otherwise success=false will be omitted,
 	// but we still need to omit it for non-probe messages.
 	Success *bool `json:"success,omitempty"`
 
+	// ErrorKind is the reason of a failed probe, e.g. "timeout" or "refused".
+	ErrorKind errorKind `json:"error_kind,omitempty"`
+	// ErrorLayer is the layer a probe failed at: "tcp", "tls" or "http".
+	ErrorLayer string `json:"error_layer,omitempty"`
+
+	// TLS fields are set for successful probes in --tls mode
+	// and for the statistics, from the last successful probe.
+
+	TLSVersion string `json:"tls_version,omitempty"`
+	TLSCipher  string `json:"tls_cipher,omitempty"`
+	TLSALPN    string `json:"tls_alpn,omitempty"`
+	// TLSHandshake is the duration of the TLS handshake in ms.
+	TLSHandshake float32    `json:"tls_handshake,omitempty"`
+	CertSubject  string     `json:"cert_subject,omitempty"`
+	CertNotAfter *time.Time `json:"cert_not_after,omitempty"`
+	CertDaysLeft *int       `json:"cert_days_left,omitempty"`
+	// CertExpiring is true when the certificate expires within --cert-expiry-warning days.
+	CertExpiring bool `json:"cert_expiring,omitempty"`
+
+	// UDPReplySize is the size of the reply in bytes in --udp mode.
+	UDPReplySize int `json:"udp_reply_size,omitempty"`
+
+	// Race fields are set for successful probes in --happy-eyeballs mode.
+
+	// RaceFamily is the IP version of the address that won the race.
+	RaceFamily ipFamily `json:"race_family,omitempty"`
+	// RaceFallback is the time in ms until the winning attempt was started,
+	// omitted when the first address won.
+	RaceFallback float32 `json:"race_fallback,omitempty"`
+	// HappyEyeballs is the result of the races for the stats event.
+	HappyEyeballs *raceSummary `json:"happy_eyeballs,omitempty"`
+
+	// HTTP fields are set for probes in --http mode that received a response.
+
+	HTTPStatus int `json:"http_status,omitempty"`
+	// HTTPTTFB is the time to the first byte of the response in ms.
+	HTTPTTFB float32 `json:"http_ttfb,omitempty"`
+	// HTTPTotal is the time to the end of the response in ms.
+	HTTPTotal float32 `json:"http_total,omitempty"`
+
 	// Latency in ms for a successful probe messages.
 	Latency float32 `json:"latency,omitempty"`
 
+	// Phase durations in ms of a probe. A phase that did not happen
+	// is omitted, e.g. DNSTime is only set after (re)resolving the hostname.
+
+	DNSTime       float32 `json:"dns_time,omitempty"`
+	ConnectTime   float32 `json:"connect_time,omitempty"`
+	TLSTime       float32 `json:"tls_time,omitempty"`
+	FirstByteTime float32 `json:"first_byte_time,omitempty"`
+
+	// Phases holds the min/avg/max duration of every probe phase
+	// for the stats event, keyed by the phase, e.g. "dns" or "connect".
+	Phases map[string]phaseData `json:"phases,omitempty"`
+
 	// LatencyMin is a latency stat for the stats event.
 	//
 	// It's a string on purpose, as we'd like to have exactly
@@ -518,12 +1063,20 @@ type JSONData struct {
 	// It's a string on purpose, as we'd like to have exactly
 	// 3 decimal places without doing extra math.
 	LatencyMax string `json:"latency_max,omitempty"`
+	// LatencyMedian, LatencyP90, LatencyP95 and LatencyP99
+	// are latency percentiles for the stats event.
+	LatencyMedian string `json:"latency_median,omitempty"`
+	LatencyP90    string `json:"latency_p90,omitempty"`
+	LatencyP95    string `json:"latency_p95,omitempty"`
+	LatencyP99    string `json:"latency_p99,omitempty"`
+	// LatencyStdDev is the standard deviation of latencies for the stats event.
+	LatencyStdDev string `json:"latency_stddev,omitempty"`
+	// Jitter is the mean absolute difference between
+	// consecutive latencies for the stats event.
+	Jitter string `json:"jitter,omitempty"`
 
 	// TotalDuration is a total amount of seconds that program was running.
-	//
-	// It's a string on purpose, as we'd like to have exactly
-	// 3 decimal places without doing extra math.
-	TotalDuration string `json:"total_duration,omitempty"`
+	TotalDuration float64 `json:"total_duration,omitempty"`
 	// StartTimestamp is used as a start time of TotalDuration for stats messages.
 	StartTimestamp *time.Time `json:"start_timestamp,omitempty"`
 	// EndTimestamp is used as an end of TotalDuration for stats messages.
@@ -533,18 +1086,12 @@ type JSONData struct {
 	LastUnsuccessfulProbe *time.Time `json:"last_unsuccessful_probe,omitempty"`
 
 	// LongestUptime in seconds.
-	//
-	// It's a string on purpose, as we'd like to have exactly
-	// 3 decimal places without doing extra math.
-	LongestUptime      string     `json:"longest_uptime,omitempty"`
+	LongestUptime      float64    `json:"longest_uptime,omitempty"`
 	LongestUptimeEnd   *time.Time `json:"longest_uptime_end,omitempty"`
 	LongestUptimeStart *time.Time `json:"longest_uptime_start,omitempty"`
 
 	// LongestDowntime in seconds.
-	//
-	// It's a string on purpose, as we'd like to have exactly
-	// 3 decimal places without doing extra math.
-	LongestDowntime      string     `json:"longest_downtime,omitempty"`
+	LongestDowntime      float64    `json:"longest_downtime,omitempty"`
 	LongestDowntimeEnd   *time.Time `json:"longest_downtime_end,omitempty"`
 	LongestDowntimeStart *time.Time `json:"longest_downtime_start,omitempty"`
 
@@ -560,6 +1107,51 @@ type JSONData struct {
 	TotalUptime float64 `json:"total_uptime,omitempty"`
 	// TotalDowntime in seconds.
 	TotalDowntime float64 `json:"total_downtime,omitempty"`
+	// FailuresByKind counts failed probes per error kind for the stats event.
+	FailuresByKind map[errorKind]uint `json:"failures_by_kind,omitempty"`
+
+	// State is the declared state of the target: "up", "down" or "flapping".
+	// It's set for probe, state change and stats events.
+	State targetState `json:"state,omitempty"`
+	// StateChanges counts the changes between up and down for the stats event.
+	StateChanges uint `json:"state_changes,omitempty"`
+	// Flaps counts the times the target started flapping for the stats event.
+	Flaps uint `json:"flaps,omitempty"`
+
+	// Check fields are set for the check event of --check.
+
+	// CheckStatus is "healthy", or the failed check, e.g. "loss" or "p95_rtt".
+	CheckStatus string `json:"check_status,omitempty"`
+	// CheckValue is the measured loss in percent or RTT in ms of a failed check.
+	CheckValue float64 `json:"check_value,omitempty"`
+	// CheckThreshold is the threshold of a failed check.
+	CheckThreshold float64 `json:"check_threshold,omitempty"`
+	// ExitCode is the exit code of the check of this target.
+	// It's a pointer on purpose, so that the healthy 0 is not omitted.
+	ExitCode *int `json:"exit_code,omitempty"`
+
+	// Address fields are set for the address-summary event of --all-addresses.
+
+	// Addresses are the results of every address of the hostname.
+	Addresses []addressStats `json:"addresses,omitempty"`
+	// FailingAddresses are the addresses that are down or flapping.
+	FailingAddresses []netip.Addr `json:"failing_addresses,omitempty"`
+
+	// DualStack is the comparison of IPv4 and IPv6 of the dual-stack event of --dual-stack.
+	DualStack *dualStackSummary `json:"dual_stack,omitempty"`
+
+	// Report fields are set for the sessions and report events of the report subcommand.
+
+	// Sessions are the sessions saved in a file.
+	Sessions []reportSession `json:"sessions,omitempty"`
+	// Session is the analysed session.
+	Session *reportSession `json:"session,omitempty"`
+	// Outages are the periods in which every probe failed.
+	Outages []outage `json:"outages,omitempty"`
+	// HourlyAvailability is the share of successful probes per hour.
+	HourlyAvailability []hourlyAvailability `json:"hourly_availability,omitempty"`
+	// RTTHistogram counts the RTTs per bucket. Unlike Prometheus, the buckets are not cumulative.
+	RTTHistogram []histogramBucket `json:"rtt_histogram,omitempty"`
 }
 
 // printStart prints the initial message before doing probes.
@@ -573,7 +1165,7 @@ func (p *jsonPrinter) printStart(hostname string, port uint16) {
 }
 
 // printReply prints TCP probe replies according to our policies in JSON format.
-func (p *jsonPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32) {
+func (p *jsonPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
 	var (
 		// for *bool fields
 		f    = false
@@ -593,29 +1185,32 @@ func (p *jsonPrinter) printProbeSuccess(sourceAddr string, userInput userInput,
 		data.LocalAddr = sourceAddr
 	}
 
+	details := probeDetails(info)
+	data.State = info.state
+	if info.tls != nil {
+		setTLSData(&data, userInput, *info.tls)
+	}
+	setPhaseData(&data, info.timings)
+	if info.udp != nil {
+		data.UDPReplySize = info.udp.size
+	}
+	if info.race != nil {
+		data.RaceFamily = info.race.family()
+		data.RaceFallback = info.race.fallback
+	}
+	if info.http != nil {
+		setHTTPData(&data, *info.http)
+	}
+
 	if userInput.hostname != "" {
 		data.DestIsIP = &f
-		if userInput.showSourceAddress {
-			data.Message = fmt.Sprintf("%s 回复 %s (%s) 端口 %d 使用 %s 时间=%.1f ms",
-				time.Now().Format(timeFormat), userInput.hostname, userInput.ip.String(), userInput.port, sourceAddr, rtt)
-		} else {
-			data.Message = fmt.Sprintf("%s 回复 %s (%s) 端口 %d 时间=%.1f ms",
-				time.Now().Format(timeFormat), userInput.hostname, userInput.ip.String(), userInput.port, rtt)
-		}
-	} else {
-		if userInput.showSourceAddress {
-			data.Message = fmt.Sprintf("%s 回复 %s (%s) 端口 %d 使用 %s 时间=%.1f ms",
-				time.Now().Format(timeFormat), userInput.ip.String(), userInput.port, sourceAddr, rtt)
-		} else {
-			data.Message = fmt.Sprintf("%s 回复 %s (%s) 端口 %d 时间=%.1f ms",
-				time.Now().Format(timeFormat), userInput.ip.String(), userInput.port, rtt)
-		}
 	}
+	data.Message = timestampPrefix(true) + replyMessage(sourceAddr, userInput, streak, rtt, 1) + details
 
 	p.print(data)
 }
 
-func (p *jsonPrinter) printProbeFail(userInput userInput, streak uint) {
+func (p *jsonPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
 	var (
 		// for *bool fields
 		f    = false
@@ -627,29 +1222,69 @@ func (p *jsonPrinter) printProbeFail(userInput userInput, streak uint) {
 			Port:                    userInput.port,
 			DestIsIP:                &t,
 			Success:                 &f,
+			ErrorKind:               kind,
+			ErrorLayer:              userInput.errorLayer(kind),
 			TotalUnsuccessfulProbes: streak,
+			State:                   info.state,
 		}
 	)
 
+	if info.http != nil {
+		setHTTPData(&data, *info.http)
+	}
+
 	if userInput.hostname != "" {
 		data.DestIsIP = &f
-		data.Message = fmt.Sprintf("%s 没有回复 %s (%s) 端口 %d",
-			time.Now().Format(timeFormat), userInput.hostname, userInput.ip.String(), userInput.port)
-	} else {
-		data.Message = fmt.Sprintf("%s 没有回复 %s (%s) 端口 %d",
-			time.Now().Format(timeFormat), userInput.ip.String(), userInput.port)
 	}
+	data.Message = timestampPrefix(true) + noReplyMessage(userInput, streak, kind, info)
 
 	p.print(data)
 }
 
+// setTLSData sets the TLS fields of data.
+func setTLSData(data *JSONData, userInput userInput, info tlsInfo) {
+	data.TLSVersion = info.version
+	data.TLSCipher = info.cipher
+	data.TLSALPN = info.alpn
+	data.TLSHandshake = info.handshake
+	data.CertSubject = info.subject
+
+	if !info.notAfter.IsZero() {
+		daysLeft := info.daysLeft()
+		data.CertNotAfter = &info.notAfter
+		data.CertDaysLeft = &daysLeft
+		data.CertExpiring = info.certExpiring(userInput)
+	}
+}
+
+// setPhaseData sets the phase durations of a probe.
+func setPhaseData(data *JSONData, timings phaseTimings) {
+	data.DNSTime = timings[phaseDNS]
+	data.ConnectTime = timings[phaseConnect]
+	data.TLSTime = timings[phaseTLS]
+	data.FirstByteTime = timings[phaseFirstByte]
+}
+
+// setHTTPData sets the HTTP fields of data.
+func setHTTPData(data *JSONData, info httpInfo) {
+	data.HTTPStatus = info.status
+	data.HTTPTTFB = info.ttfb
+	data.HTTPTotal = info.total
+}
+
 // printStatistics prints all gathered stats when program exits.
 func (p *jsonPrinter) printStatistics(t tcping) {
+	p.print(newStatisticsData(t))
+}
+
+// newStatisticsData creates the data of a statistics event.
+func newStatisticsData(t tcping) JSONData {
 	data := JSONData{
 		Type:     statisticsEvent,
-		Message:  fmt.Sprintf("%s 统计信息 %s", time.Now().Format(timeFormat), t.userInput.hostname),
+		Message:  timestampPrefix(true) + msgf("stats.title", statisticsTarget(t)),
 		Addr:     t.userInput.ip.String(),
 		Hostname: t.userInput.hostname,
+		Port:     t.userInput.port,
 
 		StartTimestamp:          &t.startTime,
 		TotalDowntime:           t.totalDowntime.Seconds(),
@@ -657,10 +1292,18 @@ func (p *jsonPrinter) printStatistics(t tcping) {
 		TotalSuccessfulProbes:   t.totalSuccessfulProbes,
 		TotalUnsuccessfulProbes: t.totalUnsuccessfulProbes,
 		TotalUptime:             t.totalUptime.Seconds(),
+		State:                   t.state(),
+		StateChanges:            t.stateChanges,
+		Flaps:                   t.flaps,
 	}
 
+	// copies are kept, as the data may outlive the current probe
 	if len(t.hostnameChanges) > 1 {
-		data.HostnameChanges = t.hostnameChanges
+		data.HostnameChanges = slices.Clone(t.hostnameChanges)
+	}
+
+	if len(t.failuresByKind) > 0 {
+		data.FailuresByKind = maps.Clone(t.failuresByKind)
 	}
 
 	loss := (float32(data.TotalUnsuccessfulProbes) / float32(data.TotalPackets)) * 100
@@ -677,25 +1320,74 @@ func (p *jsonPrinter) printStatistics(t tcping) {
 	}
 
 	if t.longestUptime.duration != 0 {
-		data.LongestUptime = fmt.Sprintf("%.0f", t.longestUptime.duration.Seconds())
+		data.LongestUptime = t.longestUptime.duration.Seconds()
 		data.LongestUptimeStart = &t.longestUptime.start
 		data.LongestUptimeEnd = &t.longestUptime.end
 	}
 
 	if t.longestDowntime.duration != 0 {
-		data.LongestDowntime = fmt.Sprintf("%.0f", t.longestDowntime.duration.Seconds())
+		data.LongestDowntime = t.longestDowntime.duration.Seconds()
 		data.LongestDowntimeStart = &t.longestDowntime.start
 		data.LongestDowntimeEnd = &t.longestDowntime.end
 	}
 
 	if !t.destIsIP {
 		data.HostnameResolveTries = t.retriedHostnameLookups
+		data.DNSLookups = t.dns.lookups
+		data.DNSFailures = t.dns.failures
+		if t.userInput.resolver != nil {
+			data.Resolver = t.userInput.resolver.String()
+		}
+		if result := t.dns.latency.result(); result.hasResults {
+			data.DNSLookupTime = &phaseData{
+				Min: fmt.Sprintf("%.1f", result.min),
+				Avg: fmt.Sprintf("%.1f", result.average),
+				Max: fmt.Sprintf("%.1f", result.max),
+			}
+		}
+	}
+
+	if t.userInput.srvName != "" {
+		data.SRVName = t.userInput.srvName
+		record := t.srvRecord
+		data.SRVRecord = &record
+		data.SRVChanges = slices.Clone(t.srvChanges)
 	}
 
 	if t.rttResults.hasResults {
 		data.LatencyMin = fmt.Sprintf("%.1f", t.rttResults.min)
 		data.LatencyAvg = fmt.Sprintf("%.1f", t.rttResults.average)
 		data.LatencyMax = fmt.Sprintf("%.1f", t.rttResults.max)
+		data.LatencyMedian = fmt.Sprintf("%.1f", t.rttResults.median)
+		data.LatencyP90 = fmt.Sprintf("%.1f", t.rttResults.p90)
+		data.LatencyP95 = fmt.Sprintf("%.1f", t.rttResults.p95)
+		data.LatencyP99 = fmt.Sprintf("%.1f", t.rttResults.p99)
+		data.LatencyStdDev = fmt.Sprintf("%.1f", t.rttResults.stdDev)
+		data.Jitter = fmt.Sprintf("%.1f", t.rttResults.jitter)
+	}
+
+	for p := range phaseCount {
+		result := t.phases[p].result()
+		if !result.hasResults {
+			continue
+		}
+		if data.Phases == nil {
+			data.Phases = map[string]phaseData{}
+		}
+		data.Phases[p.String()] = phaseData{
+			Min: fmt.Sprintf("%.1f", result.min),
+			Avg: fmt.Sprintf("%.1f", result.average),
+			Max: fmt.Sprintf("%.1f", result.max),
+		}
+	}
+
+	if t.lastTLS != nil {
+		setTLSData(&data, t.userInput, *t.lastTLS)
+	}
+
+	if t.userInput.happyEyeballs != nil {
+		race := t.race.summary()
+		data.HappyEyeballs = &race
 	}
 
 	if !t.endTime.IsZero() {
@@ -703,27 +1395,109 @@ func (p *jsonPrinter) printStatistics(t tcping) {
 	}
 
 	totalDuration := t.totalDowntime + t.totalUptime
-	data.TotalDuration = fmt.Sprintf("%.0f", totalDuration.Seconds())
+	data.TotalDuration = totalDuration.Seconds()
 
-	p.print(data)
+	return data
 }
 
 // printTotalDownTime prints the total downtime,
 // if the next retry was successful.
-func (p *jsonPrinter) printTotalDownTime(downtime time.Duration) {
+func (p *jsonPrinter) printTotalDownTime(userInput userInput, downtime time.Duration) {
 	p.print(JSONData{
 		Type:          retrySuccessEvent,
-		Message:       fmt.Sprintf("%s 没有回复 %s", time.Now().Format(timeFormat), durationToString(downtime)),
+		Message:       timestampPrefix(true) + msgf("probe.downtime", probeAddress(userInput), userInput.port, durationToString(downtime)),
+		Hostname:      userInput.hostname,
+		Addr:          userInput.ip.String(),
+		Port:          userInput.port,
 		TotalDowntime: downtime.Seconds(),
 	})
 }
 
+// printStateChange prints the state of the target,
+// when it starts or stops flapping.
+func (p *jsonPrinter) printStateChange(userInput userInput, state targetState) {
+	p.print(JSONData{
+		Type:     stateChangeEvent,
+		Message:  timestampPrefix(true) + stateChangeMessage(userInput, state),
+		Hostname: userInput.hostname,
+		Addr:     userInput.ip.String(),
+		Port:     userInput.port,
+		State:    state,
+	})
+}
+
+// printCheckResult prints the health check of a target in --check mode.
+func (p *jsonPrinter) printCheckResult(userInput userInput, result checkResult) {
+	exitCode := int(result.status)
+	p.print(JSONData{
+		Type:           checkEvent,
+		Message:        fmt.Sprintf("%s %s %s", time.Now().Format(timeFormat), userInput.target(), result.description()),
+		Hostname:       userInput.hostname,
+		Addr:           userInput.ip.String(),
+		Port:           userInput.port,
+		CheckStatus:    result.status.String(),
+		CheckValue:     result.value,
+		CheckThreshold: result.threshold,
+		ExitCode:       &exitCode,
+	})
+}
+
+// printAddressSummary prints the addresses of a hostname in --all-addresses mode.
+func (p *jsonPrinter) printAddressSummary(summary addressSummary) {
+	failing := summary.failing()
+	p.print(JSONData{
+		Type:             addressSummaryEvent,
+		Message:          timestampPrefix(true) + msgf("address.summary", summary.target(), len(failing), len(summary.Addresses)),
+		Hostname:         summary.Hostname,
+		Port:             summary.Port,
+		Addresses:        summary.Addresses,
+		FailingAddresses: failing,
+	})
+}
+
+// printDualStackSummary prints the comparison of IPv4 and IPv6 of a hostname in --dual-stack mode.
+func (p *jsonPrinter) printDualStackSummary(summary dualStackSummary) {
+	p.print(JSONData{
+		Type: dualStackEvent,
+		Message: timestampPrefix(true) + msgf("dual.summary", summary.target(),
+			summary.Families[0].PacketLoss, summary.Families[1].PacketLoss, len(summary.SingleFamilyOutages)),
+		Hostname:  summary.Hostname,
+		Port:      summary.Port,
+		DualStack: &summary,
+	})
+}
+
+// printSessions prints the sessions saved in a file, for the report subcommand.
+func (p *jsonPrinter) printSessions(file string, sessions []reportSession) {
+	p.print(JSONData{
+		Type:     sessionsEvent,
+		Message:  msgf("report.sessions-json", file, len(sessions)),
+		Sessions: sessions,
+	})
+}
+
+// printReport prints the analysis of a session, for the report subcommand.
+func (p *jsonPrinter) printReport(r sessionReport) {
+	session := r.session
+	p.print(JSONData{
+		Type:               reportEvent,
+		Message:            msgf("report.title", r.session.Name),
+		Hostname:           r.stats.userInput.hostname,
+		Addr:               r.stats.userInput.ip.String(),
+		Port:               r.stats.userInput.port,
+		Session:            &session,
+		Outages:            r.outages,
+		HourlyAvailability: r.hourly,
+		RTTHistogram:       r.histogram,
+	})
+}
+
 // printRetryingToResolve print the message retrying to resolve,
 // after n failed probes.
 func (p *jsonPrinter) printRetryingToResolve(hostname string) {
 	p.print(JSONData{
 		Type:     retryEvent,
-		Message:  fmt.Sprintf("%s 重试解析 %s", time.Now().Format(timeFormat), hostname),
+		Message:  timestampPrefix(true) + msgf("probe.retrying", hostname),
 		Hostname: hostname,
 	})
 }
@@ -735,6 +1509,13 @@ func (p *jsonPrinter) printInfo(format string, args ...any) {
 	})
 }
 
+func (p *jsonPrinter) printWarning(format string, args ...any) {
+	p.print(JSONData{
+		Type:    warningEvent,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
 func (p *jsonPrinter) printError(format string, args ...any) {
 	p.print(JSONData{
 		Type:    errorEvent,
@@ -745,10 +1526,130 @@ func (p *jsonPrinter) printError(format string, args ...any) {
 func (p *jsonPrinter) printVersion() {
 	p.print(JSONData{
 		Type:    versionEvent,
-		Message: fmt.Sprintf("%s TCPING 版本 %s\n", time.Now().Format(timeFormat), version),
+		Message: timestampPrefix(true) + msgf("usage.version", version),
 	})
 }
 
+// MARK: SYNC PRINTER
+
+// syncPrinter wraps a printer and serializes calls to it.
+//
+// It is used when several targets are probed concurrently,
+// so that their output does not get interleaved.
+type syncPrinter struct {
+	mu      sync.Mutex
+	printer printer
+}
+
+func newSyncPrinter(p printer) *syncPrinter {
+	return &syncPrinter{printer: p}
+}
+
+func (p *syncPrinter) printStart(hostname string, port uint16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printStart(hostname, port)
+}
+
+func (p *syncPrinter) printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printProbeSuccess(sourceAddr, userInput, streak, rtt, info)
+}
+
+func (p *syncPrinter) printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printProbeFail(userInput, streak, kind, info)
+}
+
+func (p *syncPrinter) printRetryingToResolve(hostname string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printRetryingToResolve(hostname)
+}
+
+func (p *syncPrinter) printStateChange(userInput userInput, state targetState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printStateChange(userInput, state)
+}
+
+func (p *syncPrinter) printCheckResult(userInput userInput, result checkResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printCheckResult(userInput, result)
+}
+
+func (p *syncPrinter) printAddressSummary(summary addressSummary) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printAddressSummary(summary)
+}
+
+func (p *syncPrinter) printDualStackSummary(summary dualStackSummary) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printDualStackSummary(summary)
+}
+
+func (p *syncPrinter) printSessions(file string, sessions []reportSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printSessions(file, sessions)
+}
+
+func (p *syncPrinter) printReport(r sessionReport) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printReport(r)
+}
+
+func (p *syncPrinter) printTotalDownTime(userInput userInput, downtime time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printTotalDownTime(userInput, downtime)
+}
+
+func (p *syncPrinter) printStatistics(t tcping) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printStatistics(t)
+}
+
+func (p *syncPrinter) printVersion() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printVersion()
+}
+
+func (p *syncPrinter) printInfo(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printInfo(format, args...)
+}
+
+func (p *syncPrinter) printWarning(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printWarning(format, args...)
+}
+
+func (p *syncPrinter) printError(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printer.printError(format, args...)
+}
+
+// durationToSeconds formats a duration as a number of seconds
+// with millisecond precision, e.g. "90.500".
+//
+// It's used instead of durationToString by the machine-readable outputs,
+// whose durations must not depend on the language or need parsing.
+func durationToSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
+
 // durationToString creates a human-readable string for a given duration
 func durationToString(duration time.Duration) string {
 	hours := math.Floor(duration.Hours())
@@ -763,30 +1664,37 @@ func durationToString(duration time.Duration) string {
 
 	seconds := duration.Seconds()
 
+	h := durationUnit(hours, 0, "duration.hour", "duration.hours")
+	m := durationUnit(minutes, 0, "duration.minute", "duration.minutes")
+	s := durationUnit(seconds, 0, "duration.second", "duration.seconds")
+
 	switch {
 	// Hours
-	case hours >= 2:
-		return fmt.Sprintf("%s %.0f 小时 %.0f 分钟 %.0f 秒", time.Now().Format(timeFormat), hours, minutes, seconds)
 	case hours == 1 && minutes == 0 && seconds == 0:
-		return fmt.Sprintf("%s %.0f 小时", time.Now().Format(timeFormat), hours)
-	case hours == 1:
-		return fmt.Sprintf("%s %.0f 小时 %.0f 分钟 %.0f 秒", time.Now().Format(timeFormat), hours, minutes, seconds)
+		return h
+	case hours >= 1:
+		return h + " " + m + " " + s
 
 	// Minutes
-	case minutes >= 2:
-		return fmt.Sprintf("%s %.0f 分钟 %.0f 秒", time.Now().Format(timeFormat), minutes, seconds)
 	case minutes == 1 && seconds == 0:
-		return fmt.Sprintf("%s %.0f 分钟", time.Now().Format(timeFormat), minutes)
-	case minutes == 1:
-		return fmt.Sprintf("%s %.0f 分钟 %.0f 秒", time.Now().Format(timeFormat), minutes, seconds)
+		return m
+	case minutes >= 1:
+		return m + " " + s
 
 	// Seconds
-	case seconds == 0 || seconds == 1 || seconds >= 1 && seconds < 1.1:
-		return fmt.Sprintf("%s %.0f 秒", time.Now().Format(timeFormat), seconds)
-	case seconds < 1:
-		return fmt.Sprintf("%s %.1f 秒", time.Now().Format(timeFormat), seconds)
-
+	case seconds > 0 && seconds < 1:
+		return durationUnit(seconds, 1, "duration.second", "duration.seconds")
 	default:
-		return fmt.Sprintf("%s %.0f 秒", time.Now().Format(timeFormat), seconds)
+		return s
+	}
+}
+
+// durationUnit formats a number of hours, minutes or seconds,
+// using the singular message if it's exactly one.
+func durationUnit(value float64, decimals int, singular, plural string) string {
+	number := strconv.FormatFloat(value, 'f', decimals, 64)
+	if number == "1" {
+		return msgf(singular, number)
 	}
+	return msgf(plural, number)
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,21 +11,29 @@ import (
 
 	"github.com/gookit/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // dummyPrinter is a fake test implementation
 // of a printer that does nothing.
 type dummyPrinter struct{}
 
-func (fp *dummyPrinter) printStart(_ string, _ uint16)                              {}
-func (fp *dummyPrinter) printProbeSuccess(_ string, _ userInput, _ uint, _ float32) {}
-func (fp *dummyPrinter) printProbeFail(_ userInput, _ uint)                         {}
-func (fp *dummyPrinter) printRetryingToResolve(_ string)                            {}
-func (fp *dummyPrinter) printTotalDownTime(_ time.Duration)                         {}
-func (fp *dummyPrinter) printStatistics(_ tcping)                                   {}
-func (fp *dummyPrinter) printVersion()                                              {}
-func (fp *dummyPrinter) printInfo(_ string, _ ...interface{})                       {}
-func (fp *dummyPrinter) printError(_ string, _ ...interface{})                      {}
+func (fp *dummyPrinter) printStart(_ string, _ uint16)                                           {}
+func (fp *dummyPrinter) printProbeSuccess(_ string, _ userInput, _ uint, _ float32, _ probeInfo) {}
+func (fp *dummyPrinter) printProbeFail(_ userInput, _ uint, _ errorKind, _ probeInfo)            {}
+func (fp *dummyPrinter) printRetryingToResolve(_ string)                                         {}
+func (fp *dummyPrinter) printTotalDownTime(_ userInput, _ time.Duration)                         {}
+func (fp *dummyPrinter) printStateChange(_ userInput, _ targetState)                             {}
+func (fp *dummyPrinter) printCheckResult(_ userInput, _ checkResult)                             {}
+func (fp *dummyPrinter) printAddressSummary(_ addressSummary)                                    {}
+func (fp *dummyPrinter) printDualStackSummary(_ dualStackSummary)                                {}
+func (fp *dummyPrinter) printSessions(_ string, _ []reportSession)                               {}
+func (fp *dummyPrinter) printReport(_ sessionReport)                                             {}
+func (fp *dummyPrinter) printStatistics(_ tcping)                                                {}
+func (fp *dummyPrinter) printVersion()                                                           {}
+func (fp *dummyPrinter) printInfo(_ string, _ ...interface{})                                    {}
+func (fp *dummyPrinter) printWarning(_ string, _ ...interface{})                                 {}
+func (fp *dummyPrinter) printError(_ string, _ ...interface{})                                   {}
 
 func TestDurationToString(t *testing.T) {
 	t.Parallel()
@@ -99,6 +108,44 @@ func TestDurationToString(t *testing.T) {
 	}
 }
 
+func TestDurationToSeconds(t *testing.T) {
+	assert.Equal(t, "0.000", durationToSeconds(0))
+	assert.Equal(t, "0.500", durationToSeconds(500*time.Millisecond))
+	assert.Equal(t, "3723.000", durationToSeconds(time.Hour+2*time.Minute+3*time.Second))
+
+	// the language of the messages doesn't change machine-readable durations
+	useLanguage(t, languageChinese)
+	assert.Equal(t, "90.250", durationToSeconds(90*time.Second+250*time.Millisecond))
+}
+
+func TestStatisticsDataDurations(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	stats := tcping{
+		startTime:     start,
+		endTime:       start.Add(100 * time.Second),
+		totalUptime:   90 * time.Second,
+		totalDowntime: 10500 * time.Millisecond,
+		longestUptime: longestTime{
+			start:    start,
+			end:      start.Add(90 * time.Second),
+			duration: 90 * time.Second,
+		},
+	}
+
+	b, err := json.Marshal(newStatisticsData(stats))
+	require.NoError(t, err)
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(b, &data))
+
+	// durations are numbers of seconds, not human-readable strings
+	assert.Equal(t, 90.0, data["total_uptime"])
+	assert.Equal(t, 10.5, data["total_downtime"])
+	assert.Equal(t, 90.0, data["longest_uptime"])
+	assert.Equal(t, 100.5, data["total_duration"])
+	assert.NotContains(t, data, "longest_downtime")
+}
+
 func getProbeSuccessTests() []struct {
 	name              string
 	showTimestamp     bool
@@ -183,7 +230,7 @@ func TestPrintProbeSuccess(t *testing.T) {
 				stats.userInput.showSourceAddress = true
 			}
 
-			pp.printProbeSuccess(sourceAddr, stats.userInput, streak, rtt)
+			pp.printProbeSuccess(sourceAddr, stats.userInput, streak, rtt, probeInfo{})
 
 			write.Close()
 
@@ -239,25 +286,25 @@ func TestPrintProbeFail(t *testing.T) {
 			name:           "With hostname, no timestamp",
 			showTimestamp:  false,
 			useHostname:    true,
-			expectedOutput: "No reply from %s (%s) on port %d TCP_conn=%d\n",
+			expectedOutput: "No reply from %s (%s) on port %d TCP_conn=%d (connection refused)\n",
 		},
 		{
 			name:           "With hostname, with timestamp",
 			showTimestamp:  true,
 			useHostname:    true,
-			expectedOutput: "%s No reply from %s (%s) on port %d TCP_conn=%d\n",
+			expectedOutput: "%s No reply from %s (%s) on port %d TCP_conn=%d (connection refused)\n",
 		},
 		{
 			name:           "Without hostname, with timestamp",
 			showTimestamp:  true,
 			useHostname:    false,
-			expectedOutput: "%s No reply from %s on port %d TCP_conn=%d\n",
+			expectedOutput: "%s No reply from %s on port %d TCP_conn=%d (connection refused)\n",
 		},
 		{
 			name:           "Without hostname, no timestamp",
 			showTimestamp:  false,
 			useHostname:    false,
-			expectedOutput: "No reply from %s on port %d TCP_conn=%d\n",
+			expectedOutput: "No reply from %s on port %d TCP_conn=%d (connection refused)\n",
 		},
 	}
 
@@ -274,7 +321,7 @@ func TestPrintProbeFail(t *testing.T) {
 				stats.userInput.hostname = ""
 			}
 
-			pp.printProbeFail(stats.userInput, streak)
+			pp.printProbeFail(stats.userInput, streak, errorKindRefused, probeInfo{})
 
 			write.Close()
 
@@ -4,15 +4,21 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
 	"math/rand"
 	"net"
+	"net/http"
 	"net/netip"
 	"os"
 	"os/signal"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,9 +28,8 @@ import (
 var version = "" // 在编译时设置
 
 const (
-	owner      = "pouriyajamshidi"
-	repo       = "tcping"
-	dnsTimeout = 2 * time.Second
+	owner = "pouriyajamshidi"
+	repo  = "tcping"
 )
 
 // printer 是打印机需要实现的一组方法。
@@ -39,26 +44,57 @@ type printer interface {
 	// printProbeSuccess 应该在每次成功探测后打印消息。
 	// hostname 可能为空，表示正在ping一个地址。
 	// streak 是连续成功探测的次数。
-	printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32)
+	// info 包含探测的其他详细信息，例如 TLS 连接。
+	printProbeSuccess(sourceAddr string, userInput userInput, streak uint, rtt float32, info probeInfo)
 
 	// printProbeFail 应该在每次失败探测后打印消息。
 	// hostname 可能为空，表示正在ping一个地址。
 	// streak 是连续失败探测的次数。
-	printProbeFail(userInput userInput, streak uint)
+	// kind 是探测失败的原因。
+	// info 包含探测的其他详细信息，例如 HTTP 响应。
+	printProbeFail(userInput userInput, streak uint, kind errorKind, info probeInfo)
 
 	// printRetryingToResolve 应该打印一条消息，包含它正在尝试解析IP的主机名。
 	//
 	// 这仅在应用 -r 标志时打印。
 	printRetryingToResolve(hostname string)
 
+	// printStateChange 应该在目标开始或停止抖动时打印消息。
+	//
+	// state 是目标的新状态。
+	printStateChange(userInput userInput, state targetState)
+
+	// printCheckResult 应该在 --check 模式下打印目标的健康检查结果。
+	printCheckResult(userInput userInput, result checkResult)
+
+	// printAddressSummary 应该在 --all-addresses 模式下，
+	// 在各地址的统计信息之后打印一个主机名所有地址的摘要，
+	// 包括每个地址的状态和丢包率，以及失败的地址。
+	printAddressSummary(summary addressSummary)
+
+	// printDualStackSummary 应该在 --dual-stack 模式下，
+	// 在两个 IP 版本的统计信息之后打印它们的对比：
+	// 各自的丢包率、RTT 和中断，以及只有一个 IP 版本中断的时段。
+	printDualStackSummary(summary dualStackSummary)
+
+	// printSessions 应该列出 report 子命令读取的文件中保存的会话。
+	printSessions(file string, sessions []reportSession)
+
+	// printReport 应该打印 report 子命令对一个会话的分析：
+	// 每次中断、每小时的可用率和 RTT 直方图。
+	//
+	// 会话的统计信息在此之前由 printStatistics 打印。
+	printReport(r sessionReport)
+
 	// printTotalDownTime 应该打印一个停机时间。
 	//
 	// 当主机不可用一段时间但最新探测成功（变得可用）时调用此函数。
-	printTotalDownTime(downtime time.Duration)
+	printTotalDownTime(userInput userInput, downtime time.Duration)
 
 	// printStatistics 应该打印一条包含有用统计信息的消息。
 	//
 	// 这在退出和用户按"Enter"键时调用。
+	// 探测多个目标时，每个目标都会调用一次。
 	printStatistics(s tcping)
 
 	// printVersion 应该打印当前版本。
@@ -69,6 +105,11 @@ type printer interface {
 	// 例如：使用 -u 标志时的新版本信息。
 	printInfo(format string, args ...any)
 
+	// printWarning 应该打印一条不会终止程序的错误消息。
+	//
+	// 例如：发送告警失败时。与 printError 不同，打印机不应在此退出。
+	printWarning(format string, args ...any)
+
 	// printError 应该打印错误消息。
 	// 打印机还应该在需要时在给定字符串后应用\n。
 	printError(format string, args ...any)
@@ -85,37 +126,107 @@ type tcping struct {
 	ticker                    *time.Ticker // ticker is used to handle time between probes.
 	longestUptime             longestTime
 	longestDowntime           longestTime
-	rtt                       []float32
+	rtt                       rttStats   // rtt keeps the RTT statistics in constant memory
+	phases                    phaseStats // phases keeps the min, max and average duration of every probe phase
 	hostnameChanges           []hostnameChange
+	failuresByKind            map[errorKind]uint  // failuresByKind counts failed probes per failure reason
+	lastTLS                   *tlsInfo            // lastTLS is the TLS connection of the last successful probe in --tls mode
+	exporter                  *prometheusExporter // exporter is nil unless --prometheus is used
+	alerts                    []alertHook         // alerts are the hooks of --alert-command and --alert-webhook
+	dualStack                 *dualStack          // dualStack is shared by the IPv4 and IPv6 targets of a hostname in --dual-stack mode
+	raceAddrs                 []netip.Addr        // raceAddrs are the addresses raced in --happy-eyeballs mode, in the order of RFC 8305
+	race                      raceStats           // race keeps the results of the races in --happy-eyeballs mode
+	dns                       dnsStats            // dns keeps the statistics of the hostname lookups
+	dnsExpiry                 time.Time           // dnsExpiry is when the TTL of the resolved records expires, only known with --resolver
+	srv                       *srvDiscovery       // srv is shared by the targets of an SRV name
+	srvRecord                 srvRecord           // srvRecord is the SRV record the target was found in
+	srvChanges                []srvChange         // srvChanges are the times the target was added to or removed from the SRV records later
+	recentStateChanges        []time.Time         // recentStateChanges are the state changes within --flap-window
+	streakStart               time.Time           // streakStart is the time of the first probe of the current success or failure streak
+	streakElapsed             time.Duration       // streakElapsed is the time spent in the current streak
 	userInput                 userInput
 	ongoingSuccessfulProbes   uint
 	ongoingUnsuccessfulProbes uint
 	totalDowntime             time.Duration
 	totalUptime               time.Duration
+	exportedDowntime          time.Duration // exportedDowntime is the part of totalDowntime sent to the Prometheus exporter
+	exportedUptime            time.Duration // exportedUptime is the part of totalUptime sent to the Prometheus exporter
 	totalSuccessfulProbes     uint
 	totalUnsuccessfulProbes   uint
 	retriedHostnameLookups    uint
+	consecutiveSuccesses      uint
+	consecutiveFailures       uint
+	stateChanges              uint // stateChanges counts the changes between up and down
+	flaps                     uint // flaps counts the times the target started flapping
 	rttResults                rttResult
-	destWasDown               bool // destWasDown is used to determine the duration of a downtime
-	destIsIP                  bool // destIsIP suppresses printing the IP information twice when hostname is not provided
+	pendingDNS                float32 // pendingDNS is the duration of the last hostname lookup in ms, reported with the next probe
+	destWasDown               bool    // destWasDown is used to determine the duration of a downtime
+	flapping                  bool    // flapping is true while the target changes its state too often
+	destIsIP                  bool    // destIsIP suppresses printing the IP information twice when hostname is not provided
 }
 
 type userInput struct {
 	ip                       netip.Addr
 	hostname                 string
 	networkInterface         networkInterface
-	retryHostnameLookupAfter uint // Retry resolving target's hostname after a certain number of failed requests
+	tlsConfig                *tls.Config    // tlsConfig is nil unless --tls is used
+	http                     *httpProbe     // http is nil unless --http is used
+	udp                      *udpProbe      // udp is nil unless --udp is used
+	check                    *checkOptions  // check is nil unless --check is used
+	happyEyeballs            *happyEyeballs // happyEyeballs is nil unless --happy-eyeballs is used
+	resolver                 *resolver      // resolver is nil unless --resolver is used
+	dnsTimeout               time.Duration  // dnsTimeout is the timeout of a hostname lookup
+	retryHostnameLookupAfter uint           // Retry resolving target's hostname after a certain number of failed requests
 	probesBeforeQuit         uint
 	timeout                  time.Duration
 	intervalBetweenProbes    time.Duration
+	flapWindow               time.Duration
+	certExpiryWarning        uint // certExpiryWarning is the number of days before expiry to warn about a certificate
+	downAfter                uint // downAfter is the number of failures in a row to declare the target down
+	upAfter                  uint // upAfter is the number of successes in a row to declare the target up
+	flapThreshold            uint // flapThreshold is the number of state changes within flapWindow to declare flapping, 0 disables it
 	port                     uint16
 	useIPv4                  bool
 	useIPv6                  bool
+	allAddresses             bool     // allAddresses is true when the target is one of the addresses of hostname probed by --all-addresses
+	family                   ipFamily // family is the IP version of the target in --dual-stack mode, empty otherwise
+	srvName                  string   // srvName is the SRV name the target was found through, empty otherwise
 	shouldRetryResolve       bool
+	resolveTTL               bool // resolveTTL is true when the hostname is resolved again once the TTL of its records expired
 	showFailuresOnly         bool
 	showSourceAddress        bool
 }
 
+// target returns the "host:port" label of the probed destination.
+// The IP address is used when no hostname is given.
+//
+// In --all-addresses mode, every address of a hostname is a target of its own,
+// so the address is added, e.g. "example.com:443 (192.0.2.1)".
+// In --dual-stack mode, the IP version is added, e.g. "example.com:443 (IPv6)".
+// The targets of an SRV name are labeled by it, e.g. "_sip._tcp.example.com (sip1.example.com:5060)".
+func (u userInput) target() string {
+	if u.srvName != "" {
+		return fmt.Sprintf("%s (%s)", u.srvName, u.hostPort())
+	}
+	if u.allAddresses {
+		return fmt.Sprintf("%s (%s)", u.hostPort(), u.ip)
+	}
+	if u.family != "" {
+		return fmt.Sprintf("%s (%s)", u.hostPort(), u.family.label())
+	}
+	return u.hostPort()
+}
+
+// hostPort returns the "host:port" the user asked for,
+// without the address or IP version added by target().
+func (u userInput) hostPort() string {
+	host := u.hostname
+	if host == "" {
+		host = u.ip.String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(u.port)))
+}
+
 type genericUserInputArgs struct {
 	retryResolve         *uint
 	probesBeforeQuit     *uint
@@ -124,6 +235,18 @@ type genericUserInputArgs struct {
 	intName              *string
 	showFailuresOnly     *bool
 	showSourceAddress    *bool
+	tls                  *bool
+	tlsOptions           tlsOptions
+	certExpiryWarning    *uint
+	http                 *httpProbe
+	udp                  *udpProbe
+	stateOptions         stateOptions
+	check                *checkOptions
+	happyEyeballs        *happyEyeballs
+	allAddresses         bool
+	dualStack            bool
+	srvWeighted          bool
+	srvInterval          time.Duration
 	args                 []string
 }
 
@@ -143,6 +266,12 @@ type rttResult struct {
 	min        float32
 	max        float32
 	average    float32
+	median     float32
+	p90        float32
+	p95        float32
+	p99        float32
+	stdDev     float32
+	jitter     float32 // jitter is the mean absolute difference between consecutive RTTs
 	hasResults bool
 }
 
@@ -151,25 +280,207 @@ type hostnameChange struct {
 	When time.Time  `json:"when,omitempty"`
 }
 
+// errorKind is a stable category describing why a probe failed.
+type errorKind string
+
+const (
+	// errorKindTimeout means no answer was received before the timeout.
+	errorKindTimeout errorKind = "timeout"
+	// errorKindRefused means the destination answered with a RST.
+	errorKindRefused errorKind = "refused"
+	// errorKindReset means the connection was reset while being established.
+	errorKindReset errorKind = "reset"
+	// errorKindNetUnreachable means the destination network is unreachable.
+	errorKindNetUnreachable errorKind = "network_unreachable"
+	// errorKindNoRoute means there is no route to the destination host.
+	errorKindNoRoute errorKind = "no_route"
+	// errorKindPermission means the connection was denied locally, e.g. by a firewall.
+	errorKindPermission errorKind = "permission_denied"
+	// errorKindTLSHandshake means the TLS handshake failed after connecting.
+	errorKindTLSHandshake errorKind = "tls_handshake"
+	// errorKindTLSCertificate means the certificate of the destination could not be verified.
+	errorKindTLSCertificate errorKind = "tls_certificate"
+	// errorKindHTTPStatus means the HTTP response had an unexpected status code.
+	errorKindHTTPStatus errorKind = "http_status"
+	// errorKindHTTPHeader means a header of the HTTP response did not match.
+	errorKindHTTPHeader errorKind = "http_header"
+	// errorKindHTTPBody means the body of the HTTP response did not match.
+	errorKindHTTPBody errorKind = "http_body"
+	// errorKindHTTPError means no valid HTTP response was received.
+	errorKindHTTPError errorKind = "http_error"
+	// errorKindUnexpectedReply means a UDP reply did not match --udp-expect.
+	errorKindUnexpectedReply errorKind = "unexpected_reply"
+	// errorKindOther is used for everything else.
+	errorKindOther errorKind = "other"
+)
+
+// errorKinds lists all error kinds in the order they are shown in statistics.
+var errorKinds = []errorKind{
+	errorKindTimeout,
+	errorKindRefused,
+	errorKindReset,
+	errorKindNetUnreachable,
+	errorKindNoRoute,
+	errorKindPermission,
+	errorKindTLSHandshake,
+	errorKindTLSCertificate,
+	errorKindHTTPStatus,
+	errorKindHTTPHeader,
+	errorKindHTTPBody,
+	errorKindHTTPError,
+	errorKindUnexpectedReply,
+	errorKindOther,
+}
+
+// description returns a human-readable description of the error kind.
+func (k errorKind) description() string {
+	switch k {
+	case errorKindTimeout:
+		return msg("error-kind.timeout")
+	case errorKindRefused:
+		return msg("error-kind.refused")
+	case errorKindReset:
+		return msg("error-kind.reset")
+	case errorKindNetUnreachable:
+		return msg("error-kind.net-unreachable")
+	case errorKindNoRoute:
+		return msg("error-kind.no-route")
+	case errorKindPermission:
+		return msg("error-kind.permission")
+	case errorKindTLSHandshake:
+		return msg("error-kind.tls-handshake")
+	case errorKindTLSCertificate:
+		return msg("error-kind.tls-certificate")
+	case errorKindHTTPStatus:
+		return msg("error-kind.http-status")
+	case errorKindHTTPHeader:
+		return msg("error-kind.http-header")
+	case errorKindHTTPBody:
+		return msg("error-kind.http-body")
+	case errorKindHTTPError:
+		return msg("error-kind.http-error")
+	case errorKindUnexpectedReply:
+		return msg("error-kind.unexpected-reply")
+	default:
+		return msg("error-kind.other")
+	}
+}
+
+// layer returns the protocol layer the error kind belongs to: "tcp", "udp", "tls" or "http".
+//
+// It tells a closed port apart from an open port with a broken application.
+func (k errorKind) layer() string {
+	switch k {
+	case errorKindTLSHandshake, errorKindTLSCertificate:
+		return "tls"
+	case errorKindHTTPStatus, errorKindHTTPHeader, errorKindHTTPBody, errorKindHTTPError:
+		return "http"
+	case errorKindUnexpectedReply:
+		return "udp"
+	default:
+		return "tcp"
+	}
+}
+
+// errorLayer returns the layer kind belongs to, taking the transport of the probe into account.
+func (u userInput) errorLayer(kind errorKind) string {
+	if layer := kind.layer(); layer != "tcp" || u.udp == nil {
+		return layer
+	}
+	return "udp"
+}
+
+// probeInfo holds the details of a probe beyond its RTT.
+type probeInfo struct {
+	tls       *tlsInfo    // tls is only set in --tls mode, after a successful handshake
+	http      *httpInfo   // http is only set in --http mode, once a response is received
+	udp       *udpInfo    // udp is only set in --udp mode, once a reply is received
+	race      *raceResult // race is only set in --happy-eyeballs mode, once a race is won
+	timings   phaseTimings
+	state     targetState // state is the declared state of the target after the probe
+	downSince time.Time   // downSince is the start of the outage while the target is declared down, even while it's flapping
+}
+
+// failureDescription returns a human-readable reason of a failed probe.
+func failureDescription(kind errorKind, info probeInfo) string {
+	if kind == errorKindHTTPStatus && info.http != nil {
+		return fmt.Sprintf("%s %d", kind.description(), info.http.status) + flappingMarker(info)
+	}
+	return kind.description() + flappingMarker(info)
+}
+
+// classifyDialError maps an error returned by dialing into an errorKind.
+//
+// The errnos differ between Unix and Windows, see dialerror_other.go and dialerror_windows.go.
+// An error that matches none of them is a timeout when the net.Error says so, and other otherwise.
+func classifyDialError(err error) errorKind {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded),
+		errors.Is(err, context.DeadlineExceeded),
+		isAnyError(err, timeoutErrnos):
+		return errorKindTimeout
+	case isAnyError(err, refusedErrnos):
+		return errorKindRefused
+	case isAnyError(err, resetErrnos):
+		return errorKindReset
+	case isAnyError(err, netUnreachableErrnos):
+		return errorKindNetUnreachable
+	case isAnyError(err, noRouteErrnos):
+		return errorKindNoRoute
+	case isAnyError(err, permissionErrnos):
+		return errorKindPermission
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errorKindTimeout
+	default:
+		return errorKindOther
+	}
+}
+
+// isAnyError reports whether err matches any of targets, as in errors.Is.
+func isAnyError(err error, targets []error) bool {
+	return slices.ContainsFunc(targets, func(target error) bool {
+		return errors.Is(err, target)
+	})
+}
+
 // signalHandler catches SIGINT and SIGTERM then prints tcping stats
-func signalHandler(tcping *tcping) {
+func signalHandler(targets []*tcping) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		<-sigChan
-		shutdown(tcping)
+		shutdown(targets)
 	}()
 }
 
+// command is a request from the keyboard to the probe loop of every target.
+type command int
+
+const (
+	// commandPrintStats prints the statistics, when pressing Enter.
+	commandPrintStats command = iota
+	// commandReset starts the statistics over.
+	commandReset
+	// commandPause stops probing until commandResume.
+	commandPause
+	// commandResume continues probing after commandPause.
+	commandResume
+	// commandQuit prints the statistics of every target and exits.
+	// It's handled by main instead of the probe loops.
+	commandQuit
+)
+
 // monitorSTDIN checks stdin to see whether the 'Enter' key was pressed
-func monitorSTDIN(stdinChan chan bool) {
+func monitorSTDIN(commands chan<- command) {
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		input, _ := reader.ReadString('\n')
 
 		if input == "\n" || input == "\r" || input == "\r\n" {
-			stdinChan <- true
+			commands <- commandPrintStats
 		}
 	}
 }
@@ -185,39 +496,90 @@ func (t *tcping) printStats() {
 	} else {
 		calcLongestUptime(t, time.Since(t.startOfUptime))
 	}
-	t.rttResults = calcMinAvgMaxRttTime(t.rtt)
+	t.rttResults = t.rtt.result()
 
 	t.printStatistics(*t)
 }
 
-// shutdown calculates endTime, prints statistics and calls os.Exit(0).
+// shutdown calculates endTime, prints statistics of every target and calls os.Exit.
+// The exit code is 0, or the most severe failed health check in --check mode.
 // This should be used as the main exit-point.
-func shutdown(tcping *tcping) {
-	tcping.endTime = time.Now()
-	tcping.printStats()
+func shutdown(targets []*tcping) {
+	endTime := time.Now()
+	targets = withSRVTargets(targets)
+
+	// the statistics are printed after the dashboard of --tui
+	if tui, ok := unwrapPrinter(targets[0].printer).(*tuiPrinter); ok {
+		tui.close()
+	}
+
+	status := checkHealthy
+	for _, tcping := range targets {
+		tcping.endTime = endTime
+		tcping.printStats()
+
+		if tcping.userInput.check != nil {
+			result := tcping.healthCheck()
+			tcping.printCheckResult(tcping.userInput, result)
+			status = status.worse(result.status)
+		}
+	}
+
+	for _, summary := range addressSummaries(targets) {
+		targets[0].printAddressSummary(summary)
+	}
+
+	for _, summary := range dualStackSummaries(targets) {
+		targets[0].printDualStackSummary(summary)
+	}
+
+	// give the alerts of the last state changes a chance to be delivered
+	waitForAlerts(5 * time.Second)
+
+	// all targets share the same printer
+	p := unwrapPrinter(targets[0].printer)
 
-	// if the printer type is `database`, close it before exiting
-	if db, ok := tcping.printer.(*database); ok {
-		db.conn.Close()
+	// if the printer type is `database`, write the pending probes and close it before exiting
+	if db, ok := p.(*database); ok {
+		db.close()
 	}
 
 	// if the printer type is `csvPrinter`, call the cleanup function before exiting
-	if cp, ok := tcping.printer.(*csvPrinter); ok {
+	if cp, ok := p.(*csvPrinter); ok {
 		cp.cleanup()
 	}
 
-	os.Exit(0)
+	os.Exit(int(status))
+}
+
+// unwrapPrinter returns the printer wrapped by a syncPrinter or an htmlPrinter, or p itself.
+func unwrapPrinter(p printer) printer {
+	for {
+		switch wrapper := p.(type) {
+		case *syncPrinter:
+			p = wrapper.printer
+		case *htmlPrinter:
+			p = wrapper.printer
+		default:
+			return p
+		}
+	}
 }
 
 // usage prints how tcping should be run
 func usage() {
 	executableName := os.Args[0]
 
-	colorLightCyan("\nTCPING 版本 %s\n\n", version)
-	colorRed("%s 命令格式:\n", executableName)
-	colorRed("%s <主机名/ip> <端口号>  例如:\n", executableName)
+	colorLightCyan("\n"+msg("usage.version")+"\n\n", version)
+	colorRed(msg("usage.title")+"\n", executableName)
+	colorRed(msg("usage.targets")+"\n", executableName)
 	colorRed("%s www.example.com 443\n", executableName)
-	colorYellow("\n[可选项]\n")
+	colorRed("%s www.example.com 443 10.10.10.1 22\n", executableName)
+	colorRed(msg("usage.srv")+"\n", executableName)
+	colorRed(msg("usage.profile")+"\n", executableName)
+	colorRed(msg("usage.serve")+"\n", executableName)
+	colorRed(msg("usage.report")+"\n", executableName)
+	colorYellow("\n" + msg("usage.options") + "\n")
 
 	flag.VisitAll(func(f *flag.Flag) {
 		flagName := f.Name
@@ -232,9 +594,14 @@ func usage() {
 }
 
 // setPrinter selects the printer
-func setPrinter(tcping *tcping, outputJSON, prettyJSON *bool, noColor *bool, timeStamp *bool, sourceAddress *bool, outputDb *string, outputCSV *string, args []string) {
+func setPrinter(tcping *tcping, outputJSON, prettyJSON *bool, noColor *bool, useTUI *bool, timeStamp *bool, sourceAddress *bool, useTLS *bool, useHTTP *bool, outputDb *string, outputCSV *string, args []string) {
 	if *prettyJSON && !*outputJSON {
-		colorRed("--pretty 标志在没有 -j 标志的情况下无效。")
+		colorRed(msg("error.pretty-without-json"))
+		usage()
+	}
+
+	if *useTUI && (*outputJSON || *outputDb != "" || *outputCSV != "") {
+		colorRed(msg("error.tui-with-output"))
 		usage()
 	}
 
@@ -244,11 +611,13 @@ func setPrinter(tcping *tcping, outputJSON, prettyJSON *bool, noColor *bool, tim
 		tcping.printer = newDB(*outputDb, args)
 	} else if *outputCSV != "" {
 		var err error
-		tcping.printer, err = newCSVPrinter(*outputCSV, timeStamp, sourceAddress)
+		tcping.printer, err = newCSVPrinter(*outputCSV, timeStamp, sourceAddress, useTLS, useHTTP)
 		if err != nil {
-			tcping.printError("创建CSV文件失败: %s", err)
+			tcping.printError(msg("error.create-csv"), err)
 			os.Exit(1)
 		}
+	} else if *useTUI {
+		tcping.printer = newTUIPrinter(timeStamp)
 	} else if *noColor {
 		tcping.printer = newPlainPrinter(timeStamp)
 	} else {
@@ -265,7 +634,7 @@ func showVersion(tcping *tcping) {
 // setIPFlags ensures that either IPv4 or IPv6 is specified by the user and not both and sets it
 func setIPFlags(tcping *tcping, ip4, ip6 *bool) {
 	if *ip4 && *ip6 {
-		tcping.printError("只能指定一个IP版本")
+		tcping.printError(msg("error.ip-versions"))
 		usage()
 	}
 	if *ip4 {
@@ -276,16 +645,68 @@ func setIPFlags(tcping *tcping, ip4, ip6 *bool) {
 	}
 }
 
+// setResolver sets the DNS server of --resolver, the timeout of the lookups
+// and whether the hostname is resolved again when the TTL of its records expires.
+func setResolver(tcping *tcping, addr string, timeout float64, resolveTTL bool) {
+	if timeout <= 0 {
+		tcping.printError(msg("error.dns-timeout"))
+		os.Exit(1)
+	}
+	tcping.userInput.dnsTimeout = secondsToDuration(timeout)
+
+	if addr != "" {
+		r, err := parseResolver(addr)
+		if err != nil {
+			tcping.printError(msg("error.resolver"), err)
+			os.Exit(1)
+		}
+		tcping.userInput.resolver = r
+	}
+
+	// the system resolver doesn't report the TTLs
+	if resolveTTL && tcping.userInput.resolver == nil {
+		tcping.printError(msg("error.resolve-ttl"))
+		os.Exit(1)
+	}
+	tcping.userInput.resolveTTL = resolveTTL
+}
+
+// setPrometheus starts serving the Prometheus metrics on addr
+func setPrometheus(tcping *tcping, addr string, buckets string) {
+	bucketsMs, err := parsePrometheusBuckets(buckets)
+	if err != nil {
+		tcping.printError(msg("error.prometheus-buckets"), err)
+		os.Exit(1)
+	}
+
+	tcping.exporter = newPrometheusExporter(bucketsMs)
+	if err := tcping.exporter.listen(addr); err != nil {
+		tcping.printError(msg("error.prometheus-start"), err)
+		os.Exit(1)
+	}
+}
+
+// setAlerts creates the alert hooks, of which every target gets its own copy.
+func setAlerts(tcping *tcping, opts alertOptions) {
+	hooks, err := newAlertHooks(opts)
+	if err != nil {
+		tcping.printError(msg("error.alerts"), err)
+		os.Exit(1)
+	}
+
+	tcping.alerts = hooks
+}
+
 // setPort validates and sets the TCP/UDP port range
 func setPort(tcping *tcping, args []string) {
 	port, err := strconv.ParseUint(args[1], 10, 16)
 	if err != nil {
-		tcping.printError("无效的端口号: %s", args[1])
+		tcping.printError(msg("error.invalid-port"), args[1])
 		os.Exit(1)
 	}
 
 	if port < 1 || port > 65535 {
-		tcping.printError("端口应该在 1-65535 范围内")
+		tcping.printError(msg("error.port-range"))
 		os.Exit(1)
 	}
 	tcping.userInput.port = uint16(port)
@@ -305,7 +726,7 @@ func setGenericArgs(tcping *tcping, genericArgs genericUserInputArgs) {
 
 	tcping.userInput.intervalBetweenProbes = secondsToDuration(*genericArgs.secondsBetweenProbes)
 	if tcping.userInput.intervalBetweenProbes < 2*time.Millisecond {
-		tcping.printError("等待间隔应大于 2 毫秒")
+		tcping.printError(msg("error.interval"))
 		os.Exit(1)
 	}
 
@@ -322,47 +743,169 @@ func setGenericArgs(tcping *tcping, genericArgs genericUserInputArgs) {
 		tcping.userInput.shouldRetryResolve = true
 	}
 
+	// an IP address has no TTL
+	if tcping.destIsIP {
+		tcping.userInput.resolveTTL = false
+	}
+
 	if *genericArgs.intName != "" {
-		tcping.userInput.networkInterface = newNetworkInterface(tcping, *genericArgs.intName)
+		var err error
+		tcping.userInput.networkInterface, err = newNetworkInterface(tcping, *genericArgs.intName)
+		if err != nil {
+			tcping.printError("%s", err)
+			os.Exit(1)
+		}
 	}
 
 	tcping.userInput.showFailuresOnly = *genericArgs.showFailuresOnly
 
+	tcping.userInput.check = genericArgs.check
+
+	tcping.userInput.downAfter = genericArgs.stateOptions.downAfter
+	tcping.userInput.upAfter = genericArgs.stateOptions.upAfter
+	tcping.userInput.flapThreshold = genericArgs.stateOptions.flapThreshold
+	tcping.userInput.flapWindow = secondsToDuration(genericArgs.stateOptions.flapWindow)
+
 	tcping.userInput.showSourceAddress = *genericArgs.showSourceAddress
+
+	if *genericArgs.tls {
+		config, err := newTLSConfig(genericArgs.tlsOptions)
+		if err != nil {
+			tcping.printError(msg("error.tls"), err)
+			os.Exit(1)
+		}
+		tcping.userInput.tlsConfig = tlsConfigFor(config, tcping.userInput)
+		tcping.userInput.certExpiryWarning = *genericArgs.certExpiryWarning
+
+		// the --http probe speaks HTTP/1.1 only
+		if genericArgs.http != nil {
+			tcping.userInput.tlsConfig.NextProtos = []string{"http/1.1"}
+		}
+	}
+
+	tcping.userInput.http = genericArgs.http
+	tcping.userInput.udp = genericArgs.udp
+
+	if genericArgs.happyEyeballs != nil {
+		tcping.userInput.happyEyeballs = genericArgs.happyEyeballs
+		tcping.userInput.ip = resolveRace(tcping)
+		tcping.hostnameChanges[0].Addr = tcping.userInput.ip
+	}
 }
 
-// processUserInput 获取并验证用户输入
-func processUserInput(tcping *tcping) {
-	useIPv4 := flag.Bool("4", false, "仅使用IPv4。")
-	useIPv6 := flag.Bool("6", false, "仅使用IPv6。")
-	retryHostnameResolveAfter := flag.Uint("r", 0, "在 <n> 次探测失败后重试解析目标主机名。例如：-r 10 表示10次失败后重试。")
-	probesBeforeQuit := flag.Uint("c", 0, "在 <n> 次探测后停止，无论结果如何。默认无限制。")
-	outputJSON := flag.Bool("j", false, "以JSON格式输出。")
-	prettyJSON := flag.Bool("pretty", false, "在使用json输出格式时使用缩进。没有'-j'标志时无效。")
-	noColor := flag.Bool("no-color", false, "不使用彩色输出。")
-	showTimestamp := flag.Bool("D", false, "在输出中显示时间戳。")
-	saveToCSV := flag.String("csv", "", "保存tcping输出到CSV文件的路径和文件名...如果用户请求统计信息，它将被保存到同名但附加了_stats的文件中。")
-	showVer := flag.Bool("v", false, "显示版本。")
-	checkUpdates := flag.Bool("u", false, "检查更新并退出。")
-	secondsBetweenProbes := flag.Float64("i", 1, "发送探测之间的间隔。允许使用小数点分隔的实数。默认为一秒")
-	timeout := flag.Float64("t", 1, "等待响应的时间，以秒为单位。允许使用实数。0表示无限超时。")
-	outputDB := flag.String("db", "", "保存tcping输出到sqlite数据库的路径和文件名。")
-	interfaceName := flag.String("I", "", "接口名称或地址。")
-	showSourceAddress := flag.Bool("show-source-address", false, "显示用于探测的源地址和端口。")
-	showFailuresOnly := flag.Bool("show-failures-only", false, "仅显示失败的探测。")
-	showHelp := flag.Bool("h", false, "显示帮助信息。")
+// processUserInput 获取并验证用户输入，并为每个目标返回一个 tcping
+func processUserInput(tcping *tcping) []*tcping {
+	useIPv4 := flag.Bool("4", false, msg("flag.4"))
+	useIPv6 := flag.Bool("6", false, msg("flag.6"))
+	retryHostnameResolveAfter := flag.Uint("r", 0, msg("flag.r"))
+	probesBeforeQuit := flag.Uint("c", 0, msg("flag.c"))
+	outputJSON := flag.Bool("j", false, msg("flag.j"))
+	prettyJSON := flag.Bool("pretty", false, msg("flag.pretty"))
+	noColor := flag.Bool("no-color", false, msg("flag.no-color"))
+	useTUI := flag.Bool("tui", false, msg("flag.tui"))
+	showTimestamp := flag.Bool("D", false, msg("flag.D"))
+	saveToCSV := flag.String("csv", "", msg("flag.csv"))
+	showVer := flag.Bool("v", false, msg("flag.v"))
+	checkUpdates := flag.Bool("u", false, msg("flag.u"))
+	secondsBetweenProbes := flag.Float64("i", 1, msg("flag.i"))
+	timeout := flag.Float64("t", 1, msg("flag.t"))
+	outputDB := flag.String("db", "", msg("flag.db"))
+	interfaceName := flag.String("I", "", msg("flag.I"))
+	showSourceAddress := flag.Bool("show-source-address", false, msg("flag.show-source-address"))
+	showFailuresOnly := flag.Bool("show-failures-only", false, msg("flag.show-failures-only"))
+	targetsFile := flag.String("f", "", msg("flag.f"))
+	allAddresses := flag.Bool("all-addresses", false, msg("flag.all-addresses"))
+	dualStack := flag.Bool("dual-stack", false, msg("flag.dual-stack"))
+	useHappyEyeballs := flag.Bool("happy-eyeballs", false, msg("flag.happy-eyeballs"))
+	resolverAddr := flag.String("resolver", "", msg("flag.resolver"))
+	dnsTimeout := flag.Float64("dns-timeout", defaultDNSTimeout, msg("flag.dns-timeout"))
+	resolveTTL := flag.Bool("resolve-ttl", false, msg("flag.resolve-ttl"))
+	attemptDelay := flag.Float64("attempt-delay", defaultAttemptDelay, msg("flag.attempt-delay"))
+	srvWeighted := flag.Bool("srv-weighted", false, msg("flag.srv-weighted"))
+	srvInterval := flag.Float64("srv-interval", defaultSRVInterval, msg("flag.srv-interval"))
+	useTLS := flag.Bool("tls", false, msg("flag.tls"))
+	tlsServerName := flag.String("sni", "", msg("flag.sni"))
+	tlsALPN := flag.String("alpn", "", msg("flag.alpn"))
+	tlsCAFile := flag.String("ca-file", "", msg("flag.ca-file"))
+	tlsCertFile := flag.String("cert", "", msg("flag.cert"))
+	tlsKeyFile := flag.String("key", "", msg("flag.key"))
+	tlsInsecure := flag.Bool("insecure", false, msg("flag.insecure"))
+	certExpiryWarning := flag.Uint("cert-expiry-warning", defaultCertExpiryWarning, msg("flag.cert-expiry-warning"))
+	useHTTP := flag.Bool("http", false, msg("flag.http"))
+	httpMethod := flag.String("http-method", http.MethodGet, msg("flag.http-method"))
+	httpPath := flag.String("http-path", "/", msg("flag.http-path"))
+	httpHost := flag.String("http-host", "", msg("flag.http-host"))
+	var httpHeaders stringsFlag
+	flag.Var(&httpHeaders, "http-header", msg("flag.http-header"))
+	expectStatus := flag.String("expect-status", defaultExpectedStatus, msg("flag.expect-status"))
+	var expectHeaders stringsFlag
+	flag.Var(&expectHeaders, "expect-header", msg("flag.expect-header"))
+	expectBody := flag.String("expect-body", "", msg("flag.expect-body"))
+	useUDP := flag.Bool("udp", false, msg("flag.udp"))
+	udpPayload := flag.String("udp-payload", "", msg("flag.udp-payload"))
+	udpPayloadHex := flag.String("udp-payload-hex", "", msg("flag.udp-payload-hex"))
+	udpPayloadFile := flag.String("udp-payload-file", "", msg("flag.udp-payload-file"))
+	udpPreset := flag.String("udp-preset", "", msg("flag.udp-preset"))
+	udpExpect := flag.String("udp-expect", "", msg("flag.udp-expect"))
+	prometheusAddr := flag.String("prometheus", "", msg("flag.prometheus"))
+	prometheusBuckets := flag.String("prometheus-buckets", defaultPrometheusBuckets, msg("flag.prometheus-buckets"))
+	htmlReport := flag.String("html", "", msg("flag.html"))
+	alertCommand := flag.String("alert-command", "", msg("flag.alert-command"))
+	alertWebhook := flag.String("alert-webhook", "", msg("flag.alert-webhook"))
+	alertWebhookFormat := flag.String("alert-webhook-format", webhookFormatGeneric, msg("flag.alert-webhook-format"))
+	alertRoutingKey := flag.String("alert-routing-key", "", msg("flag.alert-routing-key"))
+	alertThreshold := flag.Uint("alert-threshold", defaultAlertThreshold, msg("flag.alert-threshold"))
+	alertCooldown := flag.Float64("alert-cooldown", defaultAlertCooldown, msg("flag.alert-cooldown"))
+	downAfter := flag.Uint("down-after", 1, msg("flag.down-after"))
+	upAfter := flag.Uint("up-after", 1, msg("flag.up-after"))
+	flapThreshold := flag.Uint("flap-threshold", 0, msg("flag.flap-threshold"))
+	flapWindow := flag.Float64("flap-window", defaultFlapWindow, msg("flag.flap-window"))
+	healthCheck := flag.Bool("check", false, msg("flag.check"))
+	maxLoss := flag.Float64("max-loss", defaultMaxLoss, msg("flag.max-loss"))
+	maxAvgRTT := flag.Float64("max-avg-rtt", 0, msg("flag.max-avg-rtt"))
+	maxP95RTT := flag.Float64("max-p95-rtt", 0, msg("flag.max-p95-rtt"))
+	configPath := flag.String("config", "", msg("flag.config"))
+	profileName := flag.String("p", "", msg("flag.p"))
+	showHelp := flag.Bool("h", false, msg("flag.h"))
+	// the language is already set by main, from --lang or the configuration file
+	flag.String("lang", "", msg("flag.lang"))
 
 	flag.CommandLine.Usage = usage
 
 	permuteArgs(os.Args[1:])
 	flag.Parse()
 
+	// flags given on the command line override the configuration file
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		colorRed(msg("error.read-config")+"\n", err)
+		os.Exit(1)
+	}
+	args, err := cfg.apply(flag.CommandLine, *profileName)
+	if err != nil {
+		colorRed(msg("error.apply-config")+"\n", err)
+		os.Exit(1)
+	}
+
 	// validation for flag and args
-	args := flag.Args()
+	if *targetsFile != "" {
+		fileArgs, err := readTargetsFile(*targetsFile)
+		if err != nil {
+			colorRed(msg("error.read-targets")+"\n", err)
+			os.Exit(1)
+		}
+		args = append(args, fileArgs...)
+	}
+
+	// the dashboard needs every probe for its streaks, packet loss and outages
+	if *useTUI && *showFailuresOnly {
+		colorRed(msg("error.tui-failures-only"))
+		usage()
+	}
 
 	// we need to set printers first, because they're used for
 	// error reporting and other output.
-	setPrinter(tcping, outputJSON, prettyJSON, noColor, showTimestamp, showSourceAddress, outputDB, saveToCSV, args)
+	setPrinter(tcping, outputJSON, prettyJSON, noColor, useTUI, showTimestamp, showSourceAddress, useTLS, useHTTP, outputDB, saveToCSV, args)
 
 	// Handle -v flag
 	if *showVer {
@@ -379,16 +922,133 @@ func processUserInput(tcping *tcping) {
 		checkForUpdates(tcping)
 	}
 
-	// host and port must be specified
-	if len(args) != 2 {
+	// at least one host and port pair, or SRV name, must be specified
+	targetArgs, ok := splitTargets(args)
+	if !ok || len(targetArgs) == 0 {
 		usage()
 	}
 
 	// Check whether both the ipv4 and ipv6 flags are attempted set if ony one, error otherwise.
 	setIPFlags(tcping, useIPv4, useIPv6)
 
-	// Check if the port is valid and set it.
-	setPort(tcping, args)
+	setResolver(tcping, *resolverAddr, *dnsTimeout, *resolveTTL)
+
+	// --dual-stack chooses the addresses of both IP versions itself
+	if *dualStack && (*useIPv4 || *useIPv6 || *allAddresses) {
+		tcping.printError(msg("error.dual-stack"))
+		usage()
+	}
+
+	// the targets of an SRV name are found by the name, not by their addresses
+	if slices.ContainsFunc(args, isSRVName) && (*allAddresses || *dualStack) {
+		tcping.printError(msg("error.srv-mode"))
+		usage()
+	}
+
+	if *srvInterval <= 0 {
+		tcping.printError(msg("error.srv-interval"))
+		os.Exit(1)
+	}
+
+	if *prometheusAddr != "" {
+		setPrometheus(tcping, *prometheusAddr, *prometheusBuckets)
+	}
+
+	if *htmlReport != "" {
+		tcping.printer = newHTMLPrinter(tcping.printer, *htmlReport)
+	}
+
+	if *alertCommand != "" || *alertWebhook != "" || *alertWebhookFormat != webhookFormatGeneric {
+		setAlerts(tcping, alertOptions{
+			command:       *alertCommand,
+			webhookURL:    *alertWebhook,
+			webhookFormat: *alertWebhookFormat,
+			routingKey:    *alertRoutingKey,
+			threshold:     *alertThreshold,
+			cooldown:      *alertCooldown,
+		})
+	}
+
+	var httpCheck *httpProbe
+	if *useHTTP {
+		var err error
+		httpCheck, err = newHTTPProbe(httpOptions{
+			method:        *httpMethod,
+			path:          *httpPath,
+			host:          *httpHost,
+			headers:       httpHeaders,
+			expectStatus:  *expectStatus,
+			expectHeaders: expectHeaders,
+			expectBody:    *expectBody,
+		})
+		if err != nil {
+			tcping.printError(msg("error.http"), err)
+			os.Exit(1)
+		}
+	}
+
+	if *downAfter == 0 || *upAfter == 0 {
+		tcping.printError(msg("error.down-up-after"))
+		os.Exit(1)
+	}
+
+	if *flapWindow <= 0 {
+		tcping.printError(msg("error.flap-window"))
+		os.Exit(1)
+	}
+
+	var check *checkOptions
+	if *healthCheck {
+		check = &checkOptions{
+			maxLoss:   *maxLoss,
+			maxAvgRTT: *maxAvgRTT,
+			maxP95RTT: *maxP95RTT,
+		}
+		if err := check.validate(); err != nil {
+			tcping.printError(msg("error.check"), err)
+			os.Exit(1)
+		}
+
+		if *probesBeforeQuit == 0 {
+			*probesBeforeQuit = defaultCheckProbes
+		}
+	}
+
+	var udpCheck *udpProbe
+	if *useUDP {
+		if *useTLS || *useHTTP {
+			tcping.printError(msg("error.udp-with-tls"))
+			os.Exit(1)
+		}
+
+		var err error
+		udpCheck, err = newUDPProbe(udpOptions{
+			payload:     *udpPayload,
+			payloadHex:  *udpPayloadHex,
+			payloadFile: *udpPayloadFile,
+			preset:      *udpPreset,
+			expect:      *udpExpect,
+		})
+		if err != nil {
+			tcping.printError(msg("error.udp"), err)
+			os.Exit(1)
+		}
+	}
+
+	var raceOptions *happyEyeballs
+	if *useHappyEyeballs {
+		if *useUDP || *allAddresses || *dualStack {
+			tcping.printError(msg("error.happy-eyeballs"))
+			os.Exit(1)
+		}
+
+		if *attemptDelay < minAttemptDelay || *attemptDelay > maxAttemptDelay {
+			tcping.printError(msg("error.attempt-delay"), minAttemptDelay, maxAttemptDelay)
+			os.Exit(1)
+		}
+
+		raceOptions = &happyEyeballs{attemptDelay: secondsToDuration(*attemptDelay)}
+	}
 
 	// set generic args
 	genericArgs := genericUserInputArgs{
@@ -399,10 +1059,170 @@ func processUserInput(tcping *tcping) {
 		intName:              interfaceName,
 		showFailuresOnly:     showFailuresOnly,
 		showSourceAddress:    showSourceAddress,
-		args:                 args,
+		tls:                  useTLS,
+		tlsOptions: tlsOptions{
+			serverName: *tlsServerName,
+			alpn:       *tlsALPN,
+			caFile:     *tlsCAFile,
+			certFile:   *tlsCertFile,
+			keyFile:    *tlsKeyFile,
+			insecure:   *tlsInsecure,
+		},
+		certExpiryWarning: certExpiryWarning,
+		http:              httpCheck,
+		udp:               udpCheck,
+		check:             check,
+		happyEyeballs:     raceOptions,
+		allAddresses:      *allAddresses,
+		dualStack:         *dualStack,
+		srvWeighted:       *srvWeighted,
+		srvInterval:       secondsToDuration(*srvInterval),
+		stateOptions: stateOptions{
+			downAfter:     *downAfter,
+			upAfter:       *upAfter,
+			flapThreshold: *flapThreshold,
+			flapWindow:    *flapWindow,
+		},
+		args: args,
+	}
+
+	return newTargets(tcping, genericArgs)
+}
+
+// newTargets creates a tcping for every host and port pair in genericArgs.args,
+// and for every target found in the SRV records of the SRV names among them.
+//
+// All targets share the printer and the flags already set on base.
+// When there is more than one target, or alerts report errors from their
+// own goroutines, the printer is wrapped in a syncPrinter.
+func newTargets(base *tcping, genericArgs genericUserInputArgs) []*tcping {
+	p := base.printer
+	if len(genericArgs.args) > 2 || len(base.alerts) > 0 || genericArgs.allAddresses || genericArgs.dualStack ||
+		slices.ContainsFunc(genericArgs.args, isSRVName) {
+		p = newSyncPrinter(p)
+	}
+
+	pairs, _ := splitTargets(genericArgs.args)
+
+	var targets []*tcping
+	for _, pair := range pairs {
+		target := &tcping{
+			printer:   p,
+			userInput: base.userInput,
+			exporter:  base.exporter,
+			alerts:    slices.Clone(base.alerts),
+		}
+
+		if len(pair) == 1 {
+			srvTargets, err := newSRVTargets(target, genericArgs, pair[0])
+			if err != nil {
+				target.printError("%s", err)
+				os.Exit(1)
+			}
+
+			target.printInfo(msg("srv.found"), pair[0], len(srvTargets))
+			for _, srvTarget := range srvTargets {
+				if srvTarget.exporter != nil {
+					srvTarget.exporter.register(srvTarget.userInput)
+				}
+				targets = append(targets, srvTarget)
+			}
+			continue
+		}
+
+		// Check if the port is valid and set it.
+		setPort(target, pair)
+
+		targetArgs := genericArgs
+		targetArgs.args = pair
+		setGenericArgs(target, targetArgs)
+
+		if genericArgs.allAddresses && !target.destIsIP {
+			addrs, err := lookupAddresses(target)
+			if err != nil {
+				target.printError("%s", err)
+				os.Exit(1)
+			}
+
+			for _, addrTarget := range addressTargets(target, addrs) {
+				if addrTarget.exporter != nil {
+					addrTarget.exporter.register(addrTarget.userInput)
+				}
+				targets = append(targets, addrTarget)
+			}
+			continue
+		}
+
+		if genericArgs.dualStack {
+			if target.destIsIP {
+				target.printError(msg("error.dual-stack-ip"), target.userInput.ip)
+				os.Exit(1)
+			}
+
+			familyTargets, err := dualStackTargets(target)
+			if err != nil {
+				target.printError("%s", err)
+				os.Exit(1)
+			}
+
+			for _, familyTarget := range familyTargets {
+				if familyTarget.exporter != nil {
+					familyTarget.exporter.register(familyTarget.userInput)
+				}
+				targets = append(targets, familyTarget)
+			}
+			continue
+		}
+
+		if target.exporter != nil {
+			target.exporter.register(target.userInput)
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
+// readTargetsFile reads host and port pairs, and SRV names, from a file.
+//
+// Each line should contain a hostname or an IP address followed by a port,
+// separated by whitespace, or an SRV name alone.
+// Empty lines and lines starting with '#' are ignored.
+// The result is flattened, so that it can be used the same way as flag.Args().
+func readTargetsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var args []string
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 && (len(fields) != 1 || !isSRVName(fields[0])) {
+			return nil, fmt.Errorf(msg("error.targets-line"), path, lineNum, line)
+		}
+		args = append(args, fields...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	setGenericArgs(tcping, genericArgs)
+	if len(args) == 0 {
+		return nil, fmt.Errorf(msg("error.no-targets"), path)
+	}
+
+	return args, nil
 }
 
 /*
@@ -436,6 +1256,90 @@ func permuteArgs(args []string) {
 				fallthrough
 			case "csv":
 				fallthrough
+			case "f":
+				fallthrough
+			case "prometheus":
+				fallthrough
+			case "sni":
+				fallthrough
+			case "http-method":
+				fallthrough
+			case "http-path":
+				fallthrough
+			case "http-host":
+				fallthrough
+			case "http-header":
+				fallthrough
+			case "expect-status":
+				fallthrough
+			case "expect-header":
+				fallthrough
+			case "expect-body":
+				fallthrough
+			case "udp-payload":
+				fallthrough
+			case "udp-payload-hex":
+				fallthrough
+			case "udp-payload-file":
+				fallthrough
+			case "udp-preset":
+				fallthrough
+			case "udp-expect":
+				fallthrough
+			case "alert-command":
+				fallthrough
+			case "alert-webhook":
+				fallthrough
+			case "alert-webhook-format":
+				fallthrough
+			case "alert-routing-key":
+				fallthrough
+			case "alert-threshold":
+				fallthrough
+			case "alert-cooldown":
+				fallthrough
+			case "down-after":
+				fallthrough
+			case "up-after":
+				fallthrough
+			case "flap-threshold":
+				fallthrough
+			case "flap-window":
+				fallthrough
+			case "max-loss":
+				fallthrough
+			case "max-avg-rtt":
+				fallthrough
+			case "max-p95-rtt":
+				fallthrough
+			case "html":
+				fallthrough
+			case "lang":
+				fallthrough
+			case "config":
+				fallthrough
+			case "p":
+				fallthrough
+			case "alpn":
+				fallthrough
+			case "ca-file":
+				fallthrough
+			case "cert":
+				fallthrough
+			case "key":
+				fallthrough
+			case "cert-expiry-warning":
+				fallthrough
+			case "prometheus-buckets":
+				fallthrough
+			case "attempt-delay":
+				fallthrough
+			case "resolver":
+				fallthrough
+			case "dns-timeout":
+				fallthrough
+			case "srv-interval":
+				fallthrough
 			case "r":
 				/* out of index */
 				if len(args) <= i+1 {
@@ -464,9 +1368,8 @@ func permuteArgs(args []string) {
 }
 
 // newNetworkInterface uses the 1st ip address of the interface
-// if any err occurs it calls `tcpStats.printError` and exits with status code 1.
-// or return `networkInterface`
-func newNetworkInterface(tcping *tcping, netInterface string) networkInterface {
+// if any err occurs it is returned, otherwise it returns `networkInterface`
+func newNetworkInterface(tcping *tcping, netInterface string) (networkInterface, error) {
 	var interfaceAddress net.IP
 
 	interfaceAddress = net.ParseIP(netInterface)
@@ -474,14 +1377,12 @@ func newNetworkInterface(tcping *tcping, netInterface string) networkInterface {
 	if interfaceAddress == nil {
 		ief, err := net.InterfaceByName(netInterface)
 		if err != nil {
-			tcping.printError("接口 %s 未找到", netInterface)
-			os.Exit(1)
+			return networkInterface{}, fmt.Errorf(msg("error.interface-not-found"), netInterface)
 		}
 
 		addrs, err := ief.Addrs()
 		if err != nil {
-			tcping.printError("无法获取接口地址")
-			os.Exit(1)
+			return networkInterface{}, msgErr("error.interface-addresses")
 		}
 
 		// Iterating through the available addresses to identify valid IP configurations
@@ -507,8 +1408,7 @@ func newNetworkInterface(tcping *tcping, netInterface string) networkInterface {
 		}
 
 		if interfaceAddress == nil {
-			tcping.printError("无法获取接口的IP地址")
-			os.Exit(1)
+			return networkInterface{}, msgErr("error.interface-ip")
 		}
 	}
 
@@ -531,7 +1431,7 @@ func newNetworkInterface(tcping *tcping, netInterface string) networkInterface {
 		Timeout:   tcping.userInput.timeout, // Set the timeout duration
 	}
 
-	return ni
+	return ni, nil
 }
 
 // compareVersions is used to compare tcping versions
@@ -570,7 +1470,7 @@ func checkForUpdates(tcping *tcping) {
 	/* 来自同一IP的未认证请求每小时限制为60次。 */
 	latestRelease, _, err := c.Repositories.GetLatestRelease(context.Background(), owner, repo)
 	if err != nil {
-		tcping.printError("检查更新失败 %s", err.Error())
+		tcping.printError(msg("update.failed"), err.Error())
 		os.Exit(1)
 	}
 
@@ -579,32 +1479,46 @@ func checkForUpdates(tcping *tcping) {
 	latestVersion := regexp.MustCompile(reg).FindStringSubmatch(latestTagName)
 
 	if len(latestVersion) == 0 {
-		tcping.printError("检查更新失败。版本名称不符合规则: %s", latestTagName)
+		tcping.printError(msg("update.invalid-tag"), latestTagName)
 		os.Exit(1)
 	}
 
 	comparison := compareVersions(version, latestVersion[1])
 
 	if comparison < 0 {
-		tcping.printInfo("发现新版本 %s", latestVersion[1])
-		tcping.printInfo("请从下方URL更新TCPING:")
+		tcping.printInfo(msg("update.found"), latestVersion[1])
+		tcping.printInfo(msg("update.url"))
 		tcping.printInfo("https://github.com/%s/%s/releases/tag/%s",
 			owner, repo, latestTagName)
 	} else if comparison > 0 {
-		tcping.printInfo("当前版本 %s 比最新发布版本 %s 更新",
+		tcping.printInfo(msg("update.newer"),
 			version, latestVersion[1])
 	} else {
-		tcping.printInfo("您使用的是最新版本: %s", version)
+		tcping.printInfo(msg("update.latest"), version)
 	}
 
 	os.Exit(0)
 }
 
 // selectResolvedIP returns a single IPv4 or IPv6 address from the net.IP slice of resolved addresses
-func selectResolvedIP(tcping *tcping, ipAddrs []netip.Addr) netip.Addr {
+func selectResolvedIP(tcping *tcping, ipAddrs []netip.Addr) (netip.Addr, error) {
+	ipList, err := filterResolvedIPs(tcping, ipAddrs)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
 	var index int
+	if len(ipList) > 1 {
+		index = rand.Intn(len(ipList))
+	}
+
+	return ipList[index], nil
+}
+
+// filterResolvedIPs returns the resolved addresses of the IP version chosen by -4 or -6,
+// or all of them, without duplicates.
+func filterResolvedIPs(tcping *tcping, ipAddrs []netip.Addr) ([]netip.Addr, error) {
 	var ipList []netip.Addr
-	var ip netip.Addr
 
 	switch {
 	case tcping.userInput.useIPv4:
@@ -619,135 +1533,184 @@ func selectResolvedIP(tcping *tcping, ipAddrs []netip.Addr) netip.Addr {
 		}
 
 		if len(ipList) == 0 {
-			tcping.printError("无法找到%s的IPv4地址", tcping.userInput.hostname)
-			os.Exit(1)
-		}
-
-		if len(ipList) > 1 {
-			index = rand.Intn(len(ipList))
-		} else {
-			index = 0
+			return nil, fmt.Errorf(msg("error.no-ipv4"), tcping.userInput.hostname)
 		}
 
-		ip, _ = netip.ParseAddr(ipList[index].Unmap().String())
-
 	case tcping.userInput.useIPv6:
 		for _, ip := range ipAddrs {
-			if ip.Is6() {
+			// IPv4-mapped IPv6 addresses are IPv4 addresses
+			if ip.Is6() && !ip.Is4In6() {
 				ipList = append(ipList, ip)
 			}
 		}
 
 		if len(ipList) == 0 {
-			tcping.printError("无法找到%s的IPv6地址", tcping.userInput.hostname)
-			os.Exit(1)
+			return nil, fmt.Errorf(msg("error.no-ipv6"), tcping.userInput.hostname)
 		}
 
-		if len(ipList) > 1 {
-			index = rand.Intn(len(ipList))
-		} else {
-			index = 0
+	default:
+		ipList = ipAddrs
+	}
+
+	var unique []netip.Addr
+	for _, ip := range ipList {
+		ip = ip.Unmap()
+		if !slices.Contains(unique, ip) {
+			unique = append(unique, ip)
 		}
+	}
 
-		ip, _ = netip.ParseAddr(ipList[index].Unmap().String())
+	return unique, nil
+}
 
-	default:
-		if len(ipAddrs) > 1 {
-			index = rand.Intn(len(ipAddrs))
-		} else {
-			index = 0
-		}
+// lookupHostname resolves the hostname with a timeout value of dnsTimeout.
+// Unlike resolveHostname, it reports failures instead of exiting.
+func lookupHostname(tcping *tcping) (netip.Addr, error) {
+	ip, err := netip.ParseAddr(tcping.userInput.hostname)
+	if err == nil {
+		return ip, nil
+	}
 
-		ip, _ = netip.ParseAddr(ipAddrs[index].Unmap().String())
+	ipAddrs, err := lookupNetIP(tcping)
+	if err != nil {
+		return netip.Addr{}, err
 	}
 
-	return ip
+	return selectResolvedIP(tcping, ipAddrs)
+}
+
+// lookupAddresses resolves every address of the hostname
+// of the IP version chosen by -4 or -6, for --all-addresses.
+func lookupAddresses(tcping *tcping) ([]netip.Addr, error) {
+	ipAddrs, err := lookupNetIP(tcping)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterResolvedIPs(tcping, ipAddrs)
+}
+
+// lookupNetIP resolves the hostname with a timeout value of --dns-timeout,
+// through the server of --resolver or the system resolver.
+//
+// The duration of the lookup is kept for the next probe and,
+// like its failures, in the statistics of the lookups.
+func lookupNetIP(tcping *tcping) ([]netip.Addr, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tcping.userInput.dnsTimeout)
+	defer cancel()
+
+	var ipAddrs []netip.Addr
+	var ttl time.Duration
+	var err error
+
+	lookupStart := time.Now()
+	if tcping.userInput.resolver != nil {
+		ipAddrs, ttl, err = tcping.userInput.resolver.lookupNetIP(ctx, tcping.userInput.hostname)
+	} else {
+		ipAddrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", tcping.userInput.hostname)
+	}
+	lookupTime := time.Since(lookupStart)
+
+	tcping.dns.add(nanoToMillisecond(lookupTime.Nanoseconds()), err != nil)
+	if tcping.exporter != nil {
+		tcping.exporter.observeLookup(tcping.userInput, lookupTime, err != nil)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(msg("error.resolve"), tcping.userInput.hostname, err)
+	}
+	tcping.pendingDNS = nanoToMillisecond(lookupTime.Nanoseconds())
+
+	if tcping.userInput.resolver != nil {
+		tcping.dnsExpiry = time.Now().Add(ttl)
+	}
+
+	return ipAddrs, nil
 }
 
 // resolveHostname handles hostname resolution with a timeout value of a second
 func resolveHostname(tcping *tcping) netip.Addr {
-	ip, err := netip.ParseAddr(tcping.userInput.hostname)
-	if err == nil {
-		return ip
+	ip, err := lookupHostname(tcping)
+
+	// Prevent tcping to exit if it has been running for a while
+	if err != nil && (tcping.totalSuccessfulProbes != 0 || tcping.totalUnsuccessfulProbes != 0) {
+		return tcping.userInput.ip
+	} else if err != nil {
+		tcping.printError("%s", err)
+		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
-	defer cancel()
+	return ip
+}
 
-	ipAddrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", tcping.userInput.hostname)
+// resolveRace resolves the addresses to race in --happy-eyeballs mode
+// and returns the first one, like resolveHostname.
+func resolveRace(tcping *tcping) netip.Addr {
+	addrs, err := resolveRaceAddrs(tcping)
 
 	// Prevent tcping to exit if it has been running for a while
 	if err != nil && (tcping.totalSuccessfulProbes != 0 || tcping.totalUnsuccessfulProbes != 0) {
 		return tcping.userInput.ip
 	} else if err != nil {
-		tcping.printError("无法解析%s: %s", tcping.userInput.hostname, err)
+		tcping.printError("%s", err)
 		os.Exit(1)
 	}
 
-	return selectResolvedIP(tcping, ipAddrs)
+	tcping.raceAddrs = addrs
+	return addrs[0]
 }
 
 // retryResolveHostname retries resolving a hostname after certain number of failures
 func retryResolveHostname(tcping *tcping) {
 	if tcping.ongoingUnsuccessfulProbes >= tcping.userInput.retryHostnameLookupAfter {
 		tcping.printRetryingToResolve(tcping.userInput.hostname)
-		tcping.userInput.ip = resolveHostname(tcping)
+		resolveAgain(tcping)
 		tcping.ongoingUnsuccessfulProbes = 0
 		tcping.retriedHostnameLookups++
 
-		// At this point hostnameChanges should have len > 0, but just in case
-		if len(tcping.hostnameChanges) == 0 {
-			return
-		}
-
-		lastAddr := tcping.hostnameChanges[len(tcping.hostnameChanges)-1].Addr
-		if lastAddr != tcping.userInput.ip {
-			tcping.hostnameChanges = append(tcping.hostnameChanges, hostnameChange{
-				Addr: tcping.userInput.ip,
-				When: time.Now(),
-			})
+		if tcping.exporter != nil {
+			tcping.exporter.observeResolveRetry(tcping.userInput)
 		}
 	}
 }
 
-// newLongestTime creates LongestTime structure
-func newLongestTime(startTime time.Time, duration time.Duration) longestTime {
-	return longestTime{
-		start:    startTime,
-		end:      startTime.Add(duration),
-		duration: duration,
+// resolveExpiredHostname resolves the hostname again once the TTL of its records expired,
+// in --resolve-ttl mode.
+func resolveExpiredHostname(tcping *tcping) {
+	if !time.Now().Before(tcping.dnsExpiry) {
+		resolveAgain(tcping)
 	}
 }
 
-// calcMinAvgMaxRttTime calculates min, avg and max RTT values
-func calcMinAvgMaxRttTime(timeArr []float32) rttResult {
-	var sum float32
-	var result rttResult
-
-	arrLen := len(timeArr)
-	// rttResults.min = ^uint(0.0)
-	if arrLen > 0 {
-		result.min = timeArr[0]
+// resolveAgain resolves the hostname again and records the change of its address.
+func resolveAgain(tcping *tcping) {
+	if tcping.userInput.happyEyeballs != nil {
+		tcping.userInput.ip = resolveRace(tcping)
+	} else {
+		tcping.userInput.ip = resolveHostname(tcping)
 	}
 
-	for i := 0; i < arrLen; i++ {
-		sum += timeArr[i]
-
-		if timeArr[i] > result.max {
-			result.max = timeArr[i]
-		}
-
-		if timeArr[i] < result.min {
-			result.min = timeArr[i]
-		}
+	// At this point hostnameChanges should have len > 0, but just in case
+	if len(tcping.hostnameChanges) == 0 {
+		return
 	}
 
-	if arrLen > 0 {
-		result.hasResults = true
-		result.average = sum / float32(arrLen)
+	lastAddr := tcping.hostnameChanges[len(tcping.hostnameChanges)-1].Addr
+	if lastAddr != tcping.userInput.ip {
+		tcping.hostnameChanges = append(tcping.hostnameChanges, hostnameChange{
+			Addr: tcping.userInput.ip,
+			When: time.Now(),
+		})
 	}
+}
 
-	return result
+// newLongestTime creates LongestTime structure
+func newLongestTime(startTime time.Time, duration time.Duration) longestTime {
+	return longestTime{
+		start:    startTime,
+		end:      startTime.Add(duration),
+		duration: duration,
+	}
 }
 
 // calcLongestUptime calculates the longest uptime and sets it to tcpStats.
@@ -810,69 +1773,150 @@ func maxDuration(x, y time.Duration) time.Duration {
 }
 
 // handleConnError processes failed probes
-func (t *tcping) handleConnError(connTime time.Time, elapsed time.Duration) {
-	if !t.destWasDown {
-		t.startOfDowntime = connTime
+func (t *tcping) handleConnError(connTime time.Time, elapsed time.Duration, kind errorKind, info probeInfo) {
+	if t.consecutiveFailures == 0 {
+		t.streakStart = connTime
+		t.streakElapsed = 0
+	}
+	t.consecutiveFailures++
+	t.consecutiveSuccesses = 0
+	t.streakElapsed += elapsed
+
+	if t.destWasDown {
+		t.totalDowntime += elapsed
+		t.ongoingSuccessfulProbes = 0
+	} else {
+		t.totalUptime += elapsed
+	}
+
+	if !t.destWasDown && t.consecutiveFailures >= max(t.userInput.downAfter, 1) {
+		// the target has been down since the first failure of the streak
+		t.totalUptime -= t.streakElapsed
+		t.totalDowntime += t.streakElapsed
+		t.startOfDowntime = t.streakStart
 		uptime := t.startOfDowntime.Sub(t.startOfUptime)
 		calcLongestUptime(t, uptime)
 		t.startOfUptime = time.Time{}
 		t.destWasDown = true
+		t.recordStateChange(connTime)
 	}
+	t.updateFlapping(connTime)
+	info.state = t.state()
+	info.downSince = t.startOfDowntime
 
-	t.totalDowntime += elapsed
 	t.lastUnsuccessfulProbe = connTime
 	t.totalUnsuccessfulProbes++
 	t.ongoingUnsuccessfulProbes++
 
+	if t.failuresByKind == nil {
+		t.failuresByKind = map[errorKind]uint{}
+	}
+	t.failuresByKind[kind]++
+	t.phases.add(info.timings)
+
+	if t.exporter != nil {
+		t.exporter.observeFailure(t.userInput, kind)
+		t.exportState()
+	}
+
 	t.printProbeFail(
 		t.userInput,
 		t.ongoingUnsuccessfulProbes,
+		kind,
+		info,
 	)
+
+	t.alertDown(kind)
 }
 
 // handleConnSuccess processes successful probes
-func (t *tcping) handleConnSuccess(sourceAddr string, rtt float32, connTime time.Time, elapsed time.Duration) {
+func (t *tcping) handleConnSuccess(sourceAddr string, rtt float32, connTime time.Time, elapsed time.Duration, info probeInfo) {
+	if t.consecutiveSuccesses == 0 {
+		t.streakStart = connTime
+		t.streakElapsed = 0
+	}
+	t.consecutiveSuccesses++
+	t.consecutiveFailures = 0
+	t.streakElapsed += elapsed
+
 	if t.destWasDown {
-		t.startOfUptime = connTime
-		downtime := t.startOfUptime.Sub(t.startOfDowntime)
+		t.totalDowntime += elapsed
+	} else {
+		t.totalUptime += elapsed
+		t.ongoingUnsuccessfulProbes = 0
+	}
+
+	recovered := t.destWasDown && t.consecutiveSuccesses >= max(t.userInput.upAfter, 1)
+	var downtime time.Duration
+
+	if recovered {
+		// the target has been up since the first success of the streak
+		t.totalDowntime -= t.streakElapsed
+		t.totalUptime += t.streakElapsed
+		t.startOfUptime = t.streakStart
+		downtime = t.startOfUptime.Sub(t.startOfDowntime)
 		calcLongestDowntime(t, downtime)
-		t.printTotalDownTime(downtime)
+		t.printTotalDownTime(t.userInput, downtime)
 		t.startOfDowntime = time.Time{}
 		t.destWasDown = false
 		t.ongoingUnsuccessfulProbes = 0
-		t.ongoingSuccessfulProbes = 0
+		t.ongoingSuccessfulProbes = t.consecutiveSuccesses - 1
+		t.recordStateChange(connTime)
 	}
+	t.updateFlapping(connTime)
+	info.state = t.state()
+	info.downSince = t.startOfDowntime
 
-	if t.startOfUptime.IsZero() {
+	if t.startOfUptime.IsZero() && !t.destWasDown {
 		t.startOfUptime = connTime
 	}
 
-	t.totalUptime += elapsed
 	t.lastSuccessfulProbe = connTime
 	t.totalSuccessfulProbes++
 	t.ongoingSuccessfulProbes++
-	t.rtt = append(t.rtt, rtt)
+	t.rtt.add(rtt)
+	t.phases.add(info.timings)
+
+	if info.tls != nil {
+		t.lastTLS = info.tls
+	}
 
-	if !t.userInput.showFailuresOnly {
+	if t.exporter != nil {
+		t.exporter.observeSuccess(t.userInput, rtt)
+		t.exportState()
+	}
+
+	// the database computes its statistics from every probe
+	_, isDB := unwrapPrinter(t.printer).(*database)
+	if !t.userInput.showFailuresOnly || isDB {
 		t.printProbeSuccess(
 			sourceAddr,
 			t.userInput,
 			t.ongoingSuccessfulProbes,
 			rtt,
+			info,
 		)
 	}
+
+	if recovered {
+		t.alertUp(downtime)
+	}
 }
 
 // tcpProbe pings a host, TCP style
 func tcpProbe(tcping *tcping) {
 	var err error
 	var conn net.Conn
+	var race *raceResult
 	connStart := time.Now()
 
-	if tcping.userInput.networkInterface.use {
+	switch {
+	case tcping.userInput.happyEyeballs != nil:
+		conn, race, err = tcping.raceConnect()
+	case tcping.userInput.networkInterface.use:
 		// dialer already contains the timeout value
 		conn, err = tcping.userInput.networkInterface.dialer.Dial("tcp", tcping.userInput.networkInterface.remoteAddr.String())
-	} else {
+	default:
 		ipAndPort := netip.AddrPortFrom(tcping.userInput.ip, tcping.userInput.port)
 		conn, err = net.DialTimeout("tcp", ipAndPort.String(), tcping.userInput.timeout)
 	}
@@ -880,51 +1924,262 @@ func tcpProbe(tcping *tcping) {
 	connDuration := time.Since(connStart)
 	rtt := nanoToMillisecond(connDuration.Nanoseconds())
 
-	elapsed := maxDuration(connDuration, tcping.userInput.intervalBetweenProbes)
+	var info probeInfo
+	info.timings[phaseDNS] = tcping.pendingDNS
+	tcping.pendingDNS = 0
+
+	if err != nil {
+		elapsed := maxDuration(connDuration, tcping.userInput.intervalBetweenProbes)
+		tcping.handleConnError(connStart, elapsed, classifyDialError(err), info)
+		<-tcping.ticker.C
+		return
+	}
+
+	var kind errorKind
+	sourceAddr := conn.LocalAddr().String()
+	info.timings[phaseConnect] = rtt
+
+	if race != nil {
+		info.race = race
+		tcping.userInput.ip = race.winner
+		tcping.race.add(*race)
+	}
+
+	if tcping.userInput.tlsConfig != nil {
+		conn, info.tls, err = tlsHandshake(conn, tcping.userInput.tlsConfig, tcping.userInput.timeout)
+		if err != nil {
+			kind = classifyTLSError(err)
+		} else {
+			info.timings[phaseTLS] = info.tls.handshake
+		}
+	}
+
+	if err == nil && tcping.userInput.http != nil {
+		info.http, err = tcping.userInput.http.do(conn, tcping.userInput)
+		if info.http != nil {
+			info.timings[phaseFirstByte] = info.http.ttfb
+		}
+		if err != nil {
+			kind = classifyHTTPError(err)
+		}
+	}
+
+	elapsed := maxDuration(time.Since(connStart), tcping.userInput.intervalBetweenProbes)
 
 	if err != nil {
-		tcping.handleConnError(connStart, elapsed)
+		tcping.handleConnError(connStart, elapsed, kind, info)
 	} else {
-		tcping.handleConnSuccess(conn.LocalAddr().String(), rtt, connStart, elapsed)
-		conn.Close()
+		tcping.handleConnSuccess(sourceAddr, rtt, connStart, elapsed, info)
 	}
+	conn.Close()
+
 	<-tcping.ticker.C
 }
 
-func main() {
-	tcping := &tcping{}
-	processUserInput(tcping)
-	tcping.ticker = time.NewTicker(tcping.userInput.intervalBetweenProbes)
-	defer tcping.ticker.Stop()
+// probe sends a single probe in the mode chosen by the user.
+func probe(tcping *tcping) {
+	if tcping.userInput.udp != nil {
+		udpPing(tcping)
+		return
+	}
 
-	signalHandler(tcping)
+	tcpProbe(tcping)
+}
 
-	tcping.printStart(tcping.userInput.hostname, tcping.userInput.port)
+// resolveBeforeProbe resolves the hostname again after -r failed probes,
+// or once the TTL of its records expired with --resolve-ttl.
+func resolveBeforeProbe(tcping *tcping) {
+	if tcping.userInput.shouldRetryResolve {
+		retryResolveHostname(tcping)
+	}
 
-	stdinchan := make(chan bool)
-	go monitorSTDIN(stdinchan)
+	if tcping.userInput.resolveTTL {
+		resolveExpiredHostname(tcping)
+	}
+}
 
+// probeLoop probes a single target until userInput.probesBeforeQuit
+// is reached, or forever if it's zero.
+//
+// A command received on commands is run after the current probe is finished.
+// A paused target waits for the next command instead of probing.
+func probeLoop(tcping *tcping, commands <-chan command) {
 	var probeCount uint
+	paused := false
 	for {
-		if tcping.userInput.shouldRetryResolve {
-			retryResolveHostname(tcping)
+		for paused {
+			paused = tcping.runCommand(<-commands, paused)
 		}
 
-		tcpProbe(tcping)
+		switch {
+		case tcping.srv != nil:
+			// every target of an SRV name is probed by the loop of the first one
+			tcping.srv.probe()
+		case tcping.dualStack != nil:
+			// both targets of --dual-stack are probed by the loop of the IPv4 target
+			tcping.dualStack.probe()
+		default:
+			resolveBeforeProbe(tcping)
+			probe(tcping)
+		}
 
 		select {
-		case pressedEnter := <-stdinchan:
-			if pressedEnter {
-				tcping.printStats()
-			}
+		case cmd := <-commands:
+			paused = tcping.runCommand(cmd, paused)
 		default:
 		}
 
 		if tcping.userInput.probesBeforeQuit != 0 {
 			probeCount++
 			if probeCount == tcping.userInput.probesBeforeQuit {
-				shutdown(tcping)
+				return
+			}
+		}
+	}
+}
+
+// runCommand runs a command from the keyboard and returns whether the target is paused.
+func (t *tcping) runCommand(cmd command, paused bool) bool {
+	switch cmd {
+	case commandPrintStats:
+		t.printStats()
+		if t.dualStack != nil {
+			t.dualStack.ipv6.printStats()
+			t.printDualStackSummary(t.dualStack.summary())
+		}
+		if t.srv != nil {
+			for _, srvTarget := range t.srv.targets() {
+				if srvTarget != t {
+					srvTarget.printStats()
+				}
+			}
+		}
+	case commandReset:
+		t.resetStats()
+		if t.dualStack != nil {
+			t.dualStack.reset()
+		}
+		if t.srv != nil {
+			t.srv.reset(t)
+		}
+	case commandPause:
+		return true
+	case commandResume:
+		return false
+	}
+	return paused
+}
+
+// resetStats starts the statistics of the target over, keeping its configuration.
+//
+// A target that is down stays down from the time of the reset, so that its recovery
+// is still reported and sends the up alerts of the outage, which also lets
+// the hooks alert again at the next outage.
+func (t *tcping) resetStats() {
+	now := time.Now()
+	var startOfDowntime time.Time
+	if t.destWasDown {
+		startOfDowntime = now
+	}
+
+	*t = tcping{
+		printer:         t.printer,
+		ticker:          t.ticker,
+		exporter:        t.exporter,
+		alerts:          t.alerts,
+		dualStack:       t.dualStack,
+		raceAddrs:       t.raceAddrs,
+		dnsExpiry:       t.dnsExpiry,
+		srv:             t.srv,
+		srvRecord:       t.srvRecord,
+		userInput:       t.userInput,
+		destIsIP:        t.destIsIP,
+		destWasDown:     t.destWasDown,
+		startOfDowntime: startOfDowntime,
+		startTime:       now,
+		hostnameChanges: []hostnameChange{{t.userInput.ip, now}},
+	}
+
+	// the statistics saved by --db leave out the probes before the reset
+	if db, ok := unwrapPrinter(t.printer).(*database); ok {
+		if err := db.markReset(t.userInput); err != nil {
+			db.printError("\n"+msg("db.write-probes-failed"), db.dbPath, err)
+		}
+	}
+}
+
+func main() {
+	if err := setLanguage(os.Args[1:]); err != nil {
+		colorRed("%s\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		report(os.Args[2:])
+		return
+	}
+
+	targets := processUserInput(&tcping{})
+
+	signalHandler(targets)
+
+	commandChans := make([]chan command, len(targets))
+	for i, tcping := range targets {
+		tcping.ticker = time.NewTicker(tcping.userInput.intervalBetweenProbes)
+		defer tcping.ticker.Stop()
+
+		commandChans[i] = make(chan command, 4)
+
+		tcping.printStart(tcping.userInput.hostname, tcping.userInput.port)
+	}
+
+	// the keys are read by the dashboard of --tui, or only Enter is
+	commands := make(chan command)
+	if tui, ok := unwrapPrinter(targets[0].printer).(*tuiPrinter); ok {
+		if err := tui.start(commands); err != nil {
+			tui.printError("%s", err)
+			os.Exit(1)
+		}
+	} else {
+		go monitorSTDIN(commands)
+	}
+
+	go func() {
+		for cmd := range commands {
+			if cmd == commandQuit {
+				shutdown(targets)
 			}
+			for _, commandChan := range commandChans {
+				select {
+				case commandChan <- cmd:
+				default:
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i, tcping := range targets {
+		// the IPv6 target of --dual-stack is probed by the loop of the IPv4 target
+		if tcping.dualStack != nil && tcping.dualStack.ipv6 == tcping {
+			continue
 		}
+		// the targets of an SRV name are probed by the loop of the first one
+		if tcping.srv != nil && tcping.srv.first() != tcping {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probeLoop(tcping, commandChans[i])
+		}()
 	}
+
+	wg.Wait()
+	shutdown(targets)
 }
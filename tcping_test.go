@@ -1,8 +1,13 @@
 package main
 
 import (
+	"errors"
 	"net"
 	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -209,7 +214,8 @@ func TestSelectResolvedIPv4(t *testing.T) {
 	)
 
 	t.Run("IPv4 Selection", func(t *testing.T) {
-		actual := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
+		actual, err := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
+		assert.NoError(t, err)
 
 		if !actual.IsValid() {
 			t.Errorf("Expected an IP but got invalid address")
@@ -234,7 +240,8 @@ func TestSelectResolvedIPv6(t *testing.T) {
 	)
 
 	t.Run("IPv6 Selection", func(t *testing.T) {
-		actual := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
+		actual, err := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
+		assert.NoError(t, err)
 		if !actual.IsValid() {
 			t.Errorf("Expected an IP but got invalid address")
 		}
@@ -277,3 +284,190 @@ func TestSecondsToDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestReadTargetsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.txt")
+	content := `# backends
+127.0.0.1 80
+
+example.com	443
+  ::1 22  
+`
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	args, err := readTargetsFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1", "80", "example.com", "443", "::1", "22"}, args)
+}
+
+func TestReadTargetsFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing port", content: "127.0.0.1\n"},
+		{name: "too many fields", content: "127.0.0.1 80 443\n"},
+		{name: "no targets", content: "# nothing here\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.txt")
+			err := os.WriteFile(path, []byte(tt.content), 0644)
+			assert.NoError(t, err)
+
+			_, err = readTargetsFile(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewTargets(t *testing.T) {
+	var (
+		retryResolve         uint = 0
+		probesBeforeQuit     uint = 3
+		timeout                   = 1.0
+		secondsBetweenProbes      = 1.0
+		intName                   = ""
+		showFailuresOnly          = false
+		showSourceAddress         = false
+		useTLS                    = false
+	)
+
+	base := &tcping{printer: &dummyPrinter{}}
+	targets := newTargets(base, genericUserInputArgs{
+		retryResolve:         &retryResolve,
+		probesBeforeQuit:     &probesBeforeQuit,
+		timeout:              &timeout,
+		secondsBetweenProbes: &secondsBetweenProbes,
+		intName:              &intName,
+		showFailuresOnly:     &showFailuresOnly,
+		showSourceAddress:    &showSourceAddress,
+		tls:                  &useTLS,
+		args:                 []string{"127.0.0.1", "80", "::1", "443"},
+	})
+
+	assert.Len(t, targets, 2)
+
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), targets[0].userInput.ip)
+	assert.Equal(t, uint16(80), targets[0].userInput.port)
+	assert.Equal(t, "127.0.0.1:80", targets[0].userInput.target())
+
+	assert.Equal(t, netip.MustParseAddr("::1"), targets[1].userInput.ip)
+	assert.Equal(t, uint16(443), targets[1].userInput.port)
+	assert.Equal(t, "[::1]:443", targets[1].userInput.target())
+
+	for _, target := range targets {
+		assert.True(t, target.destIsIP)
+		assert.Equal(t, uint(3), target.userInput.probesBeforeQuit)
+		assert.IsType(t, &syncPrinter{}, target.printer)
+		assert.Len(t, target.hostnameChanges, 1)
+	}
+
+	// targets must not share their state
+	targets[0].totalSuccessfulProbes++
+	assert.Zero(t, targets[1].totalSuccessfulProbes)
+}
+
+func TestProbeLoopMultipleTargets(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	up := createTestStats(t)
+	up.ticker = time.NewTicker(time.Nanosecond)
+	up.userInput.probesBeforeQuit = 5
+
+	// nothing listens on this port
+	down := createTestStats(t)
+	down.ticker = time.NewTicker(time.Nanosecond)
+	down.userInput.port = 12346
+	down.userInput.probesBeforeQuit = 3
+
+	var wg sync.WaitGroup
+	for _, target := range []*tcping{up, down} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probeLoop(target, make(chan command))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint(5), up.totalSuccessfulProbes)
+	assert.Zero(t, up.totalUnsuccessfulProbes)
+
+	assert.Zero(t, down.totalSuccessfulProbes)
+	assert.Equal(t, uint(3), down.totalUnsuccessfulProbes)
+}
+
+func TestClassifyDialError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errorKind
+	}{
+		{
+			name: "refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			want: errorKindRefused,
+		},
+		{
+			name: "reset",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNRESET)},
+			want: errorKindReset,
+		},
+		{
+			name: "network unreachable",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ENETUNREACH)},
+			want: errorKindNetUnreachable,
+		},
+		{
+			name: "no route to host",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)},
+			want: errorKindNoRoute,
+		},
+		{
+			name: "permission denied",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EACCES)},
+			want: errorKindPermission,
+		},
+		{
+			name: "os timeout",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ETIMEDOUT)},
+			want: errorKindTimeout,
+		},
+		{
+			name: "deadline exceeded",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			want: errorKindTimeout,
+		},
+		{
+			name: "other",
+			err:  errors.New("something else"),
+			want: errorKindOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyDialError(tt.err))
+		})
+	}
+}
+
+func TestProbeFailCountsErrorKind(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+
+	// nothing listens on this port, so the connection is refused
+	stats.userInput.port = 12346
+
+	for i := 0; i < 3; i++ {
+		tcpProbe(stats)
+	}
+
+	assert.Equal(t, uint(3), stats.failuresByKind[errorKindRefused])
+}
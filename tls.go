@@ -0,0 +1,162 @@
+// tls.go contains the logic of --tls, timing the TLS handshake and inspecting the certificate
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultCertExpiryWarning is the default number of days before a certificate
+// expires, from which on a warning is shown.
+const defaultCertExpiryWarning = 30
+
+// tlsOptions holds the flags of the --tls probe mode.
+type tlsOptions struct {
+	serverName string
+	alpn       string // alpn is a comma-separated list of protocols
+	caFile     string
+	certFile   string
+	keyFile    string
+	insecure   bool
+}
+
+// tlsInfo describes the TLS connection of a successful probe.
+type tlsInfo struct {
+	notAfter  time.Time
+	version   string
+	cipher    string
+	subject   string
+	alpn      string  // alpn is the negotiated protocol, if any
+	handshake float32 // handshake is the duration of the handshake in milliseconds
+}
+
+// daysLeft returns the number of whole days until the certificate expires.
+// It's negative when the certificate has already expired.
+func (i tlsInfo) daysLeft() int {
+	return int(math.Floor(time.Until(i.notAfter).Hours() / 24))
+}
+
+// certExpiring reports whether the certificate expires within
+// the number of days the user asked to be warned about.
+func (i tlsInfo) certExpiring(u userInput) bool {
+	return i.daysLeft() < int(u.certExpiryWarning)
+}
+
+// newTLSConfig creates the TLS configuration of the --tls probe mode.
+//
+// ServerName is left empty unless --sni is used, so that every target
+// can use its own hostname through tlsConfigFor.
+func newTLSConfig(opts tlsOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         opts.serverName,
+		InsecureSkipVerify: opts.insecure,
+	}
+
+	for _, proto := range strings.Split(opts.alpn, ",") {
+		if proto = strings.TrimSpace(proto); proto != "" {
+			config.NextProtos = append(config.NextProtos, proto)
+		}
+	}
+
+	if opts.caFile != "" {
+		pem, err := os.ReadFile(opts.caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(msg("tls.no-certificates"), opts.caFile)
+		}
+	}
+
+	if opts.certFile != "" || opts.keyFile != "" {
+		if opts.certFile == "" || opts.keyFile == "" {
+			return nil, msgErr("tls.cert-key")
+		}
+
+		cert, err := tls.LoadX509KeyPair(opts.certFile, opts.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// tlsConfigFor returns the TLS configuration for a single target,
+// using its hostname, or its IP address, as the server name.
+func tlsConfigFor(config *tls.Config, u userInput) *tls.Config {
+	config = config.Clone()
+	if config.ServerName == "" {
+		config.ServerName = u.hostname
+	}
+	if config.ServerName == "" {
+		config.ServerName = u.ip.String()
+	}
+	return config
+}
+
+// tlsHandshake performs a TLS handshake over conn within the timeout of the probe.
+// The returned connection wraps conn and should be used and closed instead of it.
+func tlsHandshake(conn net.Conn, config *tls.Config, timeout time.Duration) (*tls.Conn, *tlsInfo, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	handshakeStart := time.Now()
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return tlsConn, nil, err
+	}
+
+	state := tlsConn.ConnectionState()
+	info := &tlsInfo{
+		version:   tls.VersionName(state.Version),
+		cipher:    tls.CipherSuiteName(state.CipherSuite),
+		alpn:      state.NegotiatedProtocol,
+		handshake: nanoToMillisecond(time.Since(handshakeStart).Nanoseconds()),
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		info.subject = leaf.Subject.String()
+		info.notAfter = leaf.NotAfter
+	}
+
+	return tlsConn, info, nil
+}
+
+// classifyTLSError maps a handshake error to an errorKind.
+func classifyTLSError(err error) errorKind {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidErr      x509.CertificateInvalidError
+	)
+
+	if errors.As(err, &verificationErr) || errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return errorKindTLSCertificate
+	}
+
+	if kind := classifyDialError(err); kind != errorKindOther {
+		return kind
+	}
+
+	return errorKindTLSHandshake
+}
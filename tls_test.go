@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSTestStats creates stats that probe srv in --tls mode,
+// trusting the certificate of srv.
+func newTLSTestStats(t *testing.T, srv *httptest.Server) *tcping {
+	addrPort := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	config, err := newTLSConfig(tlsOptions{caFile: caFile, alpn: "h2, http/1.1"})
+	require.NoError(t, err)
+
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.ip = addrPort.Addr()
+	stats.userInput.port = addrPort.Port()
+	stats.userInput.certExpiryWarning = defaultCertExpiryWarning
+	stats.userInput.tlsConfig = tlsConfigFor(config, stats.userInput)
+
+	return stats
+}
+
+func TestTLSProbeSuccess(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	stats := newTLSTestStats(t, srv)
+	tcpProbe(stats)
+
+	require.Equal(t, uint(1), stats.totalSuccessfulProbes)
+	require.NotNil(t, stats.lastTLS)
+	assert.Equal(t, "TLS 1.3", stats.lastTLS.version)
+	assert.Equal(t, "h2", stats.lastTLS.alpn)
+	assert.NotEmpty(t, stats.lastTLS.cipher)
+	assert.Equal(t, srv.Certificate().NotAfter, stats.lastTLS.notAfter)
+	assert.False(t, stats.lastTLS.certExpiring(stats.userInput))
+}
+
+func TestTLSProbeUntrustedCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	stats := newTLSTestStats(t, srv)
+	stats.userInput.tlsConfig = tlsConfigFor(&tls.Config{}, stats.userInput)
+	tcpProbe(stats)
+
+	assert.Equal(t, uint(1), stats.failuresByKind[errorKindTLSCertificate])
+	assert.Nil(t, stats.lastTLS)
+}
+
+func TestTLSProbeHandshakeFailure(t *testing.T) {
+	// testServerListen closes every connection right away
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.tlsConfig = tlsConfigFor(&tls.Config{}, stats.userInput)
+	tcpProbe(stats)
+
+	assert.Equal(t, uint(1), stats.totalUnsuccessfulProbes)
+	assert.Zero(t, stats.failuresByKind[errorKindTLSCertificate])
+	assert.Zero(t, stats.failuresByKind[errorKindRefused])
+}
+
+func TestNewTLSConfigInvalid(t *testing.T) {
+	_, err := newTLSConfig(tlsOptions{certFile: "client.pem"})
+	assert.Error(t, err)
+
+	emptyCA := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyCA, []byte("not a certificate"), 0o600))
+	_, err = newTLSConfig(tlsOptions{caFile: emptyCA})
+	assert.Error(t, err)
+}
+
+func TestTLSConfigFor(t *testing.T) {
+	config := &tls.Config{}
+
+	withHostname := tlsConfigFor(config, userInput{hostname: "example.com", ip: netip.MustParseAddr("127.0.0.1")})
+	assert.Equal(t, "example.com", withHostname.ServerName)
+
+	withIP := tlsConfigFor(config, userInput{ip: netip.MustParseAddr("127.0.0.1")})
+	assert.Equal(t, "127.0.0.1", withIP.ServerName)
+
+	withSNI := tlsConfigFor(&tls.Config{ServerName: "sni.example.com"}, userInput{hostname: "example.com"})
+	assert.Equal(t, "sni.example.com", withSNI.ServerName)
+
+	assert.Empty(t, config.ServerName)
+}
+
+func TestCertExpiring(t *testing.T) {
+	u := userInput{certExpiryWarning: 30}
+
+	assert.True(t, tlsInfo{notAfter: time.Now().Add(10 * 24 * time.Hour)}.certExpiring(u))
+	assert.True(t, tlsInfo{notAfter: time.Now().Add(-time.Hour)}.certExpiring(u))
+	assert.False(t, tlsInfo{notAfter: time.Now().Add(60 * 24 * time.Hour)}.certExpiring(u))
+}
@@ -0,0 +1,580 @@
+// tui.go contains the full-screen live dashboard of --tui
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gookit/color"
+	"golang.org/x/term"
+)
+
+const (
+	// tuiRefreshInterval is the time between two redraws of the dashboard.
+	tuiRefreshInterval = 100 * time.Millisecond
+	// tuiSparklineLength is the number of recent probes shown in the RTT sparkline.
+	tuiSparklineLength = 40
+	// tuiLossWindow is the number of recent probes of the rolling packet loss.
+	tuiLossWindow = 100
+	// tuiLogLength is the number of outages, hostname changes and messages kept per list.
+	tuiLogLength = 5
+	// tuiTargetWidth is the width of the target column, in terminal cells.
+	tuiTargetWidth = 36
+	// tuiStateWidth is the width of the state column, long enough for every language.
+	tuiStateWidth = 10
+	// tuiHistogramWidth is the width of the largest bar of the RTT histogram, in terminal cells.
+	tuiHistogramWidth = 30
+)
+
+// sparklineLevels are the bars of the RTT sparkline, from the lowest to the highest RTT.
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// tuiProbe is the result of a probe kept for the sparkline and the rolling loss.
+type tuiProbe struct {
+	success bool
+	rtt     float32
+}
+
+// tuiOutage is a period in which a target was declared down, see --down-after and --up-after.
+type tuiOutage struct {
+	start time.Time
+	end   time.Time // end is zero while the outage is ongoing
+}
+
+// tuiTarget is what the dashboard shows for a target.
+type tuiTarget struct {
+	target    string
+	hostname  string
+	ip        string
+	state     targetState
+	success   bool // success is the result of the last probe
+	streak    uint
+	lastRTT   float32
+	probes    uint
+	recent    []tuiProbe // recent are the last tuiLossWindow probes
+	histogram []histogramBucket
+	outages   []tuiOutage
+	changes   []string // changes are the hostname resolution changes
+}
+
+// tuiPrinter is a full-screen dashboard, redrawn in place instead of scrolling,
+// with a row per target and the details of the selected target.
+//
+// While the dashboard isn't shown, e.g. for the statistics on exit,
+// everything is printed by the fallback printer instead.
+type tuiPrinter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	fallback printer
+	targets  []*tuiTarget
+	messages []string
+	bounds   []float64 // bounds are the upper bounds of the RTT histogram in ms
+	selected int
+	paused   bool
+	open     bool
+	done     chan struct{}
+	restore  func() // restore brings the terminal back from raw mode
+}
+
+func newTUIPrinter(showTimestamp *bool) *tuiPrinter {
+	bounds, _ := parsePrometheusBuckets(defaultPrometheusBuckets)
+	return &tuiPrinter{
+		out:      os.Stdout,
+		fallback: newColorPrinter(showTimestamp),
+		bounds:   bounds,
+	}
+}
+
+// start shows the dashboard and sends the commands of the pressed keys to commands.
+func (p *tuiPrinter) start(commands chan<- command) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return msgErr("tui.no-terminal")
+	}
+
+	// keys are read one by one, without waiting for Enter
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf(msg("tui.terminal-failed"), err)
+	}
+
+	p.mu.Lock()
+	p.open = true
+	p.done = make(chan struct{})
+	p.restore = func() { term.Restore(fd, state) }
+	// alternate screen, hidden cursor
+	fmt.Fprint(p.out, "\x1b[?1049h\x1b[?25l")
+	p.mu.Unlock()
+
+	go p.refresh()
+	go p.readKeys(os.Stdin, commands)
+
+	return nil
+}
+
+// close hides the dashboard and restores the terminal.
+func (p *tuiPrinter) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.open {
+		return
+	}
+
+	p.open = false
+	close(p.done)
+	fmt.Fprint(p.out, "\x1b[?25h\x1b[?1049l")
+	p.restore()
+}
+
+// refresh redraws the dashboard until it's closed.
+func (p *tuiPrinter) refresh() {
+	ticker := time.NewTicker(tuiRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		if p.open {
+			// in raw mode, a new line needs a carriage return as well
+			screen := strings.ReplaceAll(p.render(), "\n", "\x1b[K\r\n")
+			fmt.Fprint(p.out, "\x1b[H"+screen+"\x1b[J")
+		}
+		p.mu.Unlock()
+	}
+}
+
+// readKeys reads the pressed keys from r and sends their commands to commands.
+func (p *tuiPrinter) readKeys(r io.Reader, commands chan<- command) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for _, key := range buf[:n] {
+			if cmd, ok := p.handleKey(key); ok {
+				commands <- cmd
+			}
+		}
+	}
+}
+
+// handleKey applies a pressed key to the dashboard, and returns
+// the command for the probe loops, if there is one.
+//
+//   - p or space pauses or resumes probing
+//   - r resets the statistics
+//   - Tab selects the next target
+//   - q or Ctrl-C quits
+func (p *tuiPrinter) handleKey(key byte) (command, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch key {
+	case 'p', 'P', ' ':
+		p.paused = !p.paused
+		if p.paused {
+			return commandPause, true
+		}
+		return commandResume, true
+
+	case 'r', 'R':
+		for _, t := range p.targets {
+			*t = tuiTarget{
+				target:    t.target,
+				hostname:  t.hostname,
+				ip:        t.ip,
+				state:     t.state,
+				histogram: newHistogram(p.bounds),
+			}
+		}
+		p.messages = appendLog(p.messages, time.Now().Format(timeFormat)+" "+msg("tui.reset"))
+		return commandReset, true
+
+	case '\t':
+		if len(p.targets) > 0 {
+			p.selected = (p.selected + 1) % len(p.targets)
+		}
+
+	case 'q', 'Q', 3:
+		return commandQuit, true
+	}
+
+	return 0, false
+}
+
+// target returns the dashboard of the target of userInput.
+// The caller should hold p.mu.
+func (p *tuiPrinter) target(userInput userInput) *tuiTarget {
+	target := userInput.target()
+	for _, t := range p.targets {
+		if t.target == target {
+			return t
+		}
+	}
+
+	t := &tuiTarget{
+		target:    target,
+		hostname:  userInput.hostname,
+		ip:        userInput.ip.String(),
+		state:     stateUp,
+		histogram: newHistogram(p.bounds),
+	}
+	p.targets = append(p.targets, t)
+	return t
+}
+
+// addProbe adds the result of a probe to the dashboard of its target.
+func (p *tuiPrinter) addProbe(userInput userInput, streak uint, success bool, rtt float32, info probeInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	t := p.target(userInput)
+
+	if ip := userInput.ip.String(); ip != t.ip {
+		t.changes = appendLog(t.changes, now.Format(timeFormat)+" "+msgf("report.from-to", t.ip, ip))
+		t.ip = ip
+	}
+
+	// the outage is ended by printTotalDownTime when the target is declared up again
+	if !info.downSince.IsZero() && (len(t.outages) == 0 || t.outages[len(t.outages)-1].start != info.downSince) {
+		t.outages = appendLog(t.outages, tuiOutage{start: info.downSince})
+	}
+
+	t.state = info.state
+	t.success = success
+	t.streak = streak
+	t.probes++
+
+	t.recent = appendWindow(t.recent, tuiProbe{success: success, rtt: rtt}, tuiLossWindow)
+	if success {
+		t.lastRTT = rtt
+		addToHistogram(t.histogram, rtt)
+	}
+}
+
+// addMessage shows a message at the bottom of the dashboard.
+func (p *tuiPrinter) addMessage(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = appendLog(p.messages, time.Now().Format(timeFormat)+" "+fmt.Sprintf(format, args...))
+}
+
+// isOpen reports whether the dashboard is shown.
+func (p *tuiPrinter) isOpen() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.open
+}
+
+// appendLog appends entry to log, keeping the last tuiLogLength entries.
+func appendLog[T any](log []T, entry T) []T {
+	return appendWindow(log, entry, tuiLogLength)
+}
+
+// appendWindow appends entry to window, keeping the last size entries.
+func appendWindow[T any](window []T, entry T, size int) []T {
+	window = append(window, entry)
+	if excess := len(window) - size; excess > 0 {
+		window = append(window[:0], window[excess:]...)
+	}
+	return window
+}
+
+// rollingLoss returns the packet loss of the recent probes, in percent.
+func (t *tuiTarget) rollingLoss() float64 {
+	if len(t.recent) == 0 {
+		return 0
+	}
+
+	var failures int
+	for _, probe := range t.recent {
+		if !probe.success {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(t.recent)) * 100
+}
+
+// sparkline draws the RTTs of the probes relative to the highest one.
+// A failed probe is drawn as "x".
+func sparkline(probes []tuiProbe) string {
+	var maxRTT float32
+	for _, probe := range probes {
+		if probe.success {
+			maxRTT = max(maxRTT, probe.rtt)
+		}
+	}
+
+	var b strings.Builder
+	for _, probe := range probes {
+		switch {
+		case !probe.success:
+			b.WriteRune('x')
+		case maxRTT == 0:
+			b.WriteRune(sparklineLevels[0])
+		default:
+			level := int(math.Round(float64(probe.rtt/maxRTT) * float64(len(sparklineLevels)-1)))
+			b.WriteRune(sparklineLevels[level])
+		}
+	}
+	return b.String()
+}
+
+// displayWidth returns the number of terminal cells s takes,
+// where CJK characters take two cells.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F,
+			r >= 0x2E80 && r <= 0xA4CF,
+			r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF,
+			r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// padRight pads s with spaces to width terminal cells.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-displayWidth(s), 0))
+}
+
+// render draws the dashboard. The caller should hold p.mu.
+func (p *tuiPrinter) render() string {
+	var b strings.Builder
+
+	b.WriteString(color.LightCyan.Sprintf(msg("usage.version"), version))
+	b.WriteString("  " + msg("tui.keys"))
+	if p.paused {
+		b.WriteString(color.LightYellow.Sprintf("  [%s]", msg("tui.paused")))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(color.Yellow.Sprint(padRight("  "+msg("tui.target"), tuiTargetWidth+3) + padRight(msg("tui.state"), tuiStateWidth) +
+		padRight(msg("tui.streak"), 10) + padRight("rtt", 11) + padRight(msg("stats.loss-rate"), 13) + padRight(msg("stats.probes"), 8) +
+		msgf("tui.trend", tuiSparklineLength)))
+	b.WriteString("\n")
+
+	for i, t := range p.targets {
+		marker := "  "
+		if i == p.selected && len(p.targets) > 1 {
+			marker = "> "
+		}
+
+		label := t.target
+		if t.hostname != "" && t.hostname != t.ip {
+			label = fmt.Sprintf("%s (%s)", t.target, t.ip)
+		}
+
+		stateColor := color.Green
+		switch t.state {
+		case stateDown:
+			stateColor = color.Red
+		case stateFlapping:
+			stateColor = color.LightYellow
+		}
+
+		streak := msgf("tui.successes", t.streak)
+		if !t.success {
+			streak = msgf("tui.failures", t.streak)
+		}
+		if t.probes == 0 {
+			streak = "-"
+		}
+
+		rtt := "-"
+		if t.lastRTT > 0 {
+			rtt = fmt.Sprintf("%.1f ms", t.lastRTT)
+		}
+
+		loss := t.rollingLoss()
+		lossColor := color.Green
+		if loss > 0 {
+			lossColor = color.Red
+		}
+
+		b.WriteString(marker + padRight(label, tuiTargetWidth) + " ")
+		b.WriteString(stateColor.Sprint(padRight(t.state.description(), tuiStateWidth)))
+		b.WriteString(padRight(streak, 10) + padRight(rtt, 11))
+		b.WriteString(lossColor.Sprint(padRight(fmt.Sprintf("%.2f%%", loss), 13)))
+		b.WriteString(padRight(fmt.Sprint(t.probes), 8))
+		b.WriteString(color.LightBlue.Sprint(sparkline(t.recent[max(len(t.recent)-tuiSparklineLength, 0):])))
+		b.WriteString("\n")
+	}
+
+	if len(p.targets) > 0 {
+		p.renderDetails(&b, p.targets[min(p.selected, len(p.targets)-1)])
+	}
+
+	if len(p.messages) > 0 {
+		b.WriteString(color.Yellow.Sprintf("\n%s:\n", msg("tui.messages")))
+		for _, m := range p.messages {
+			b.WriteString("  " + m + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// renderDetails draws the RTT histogram, the outages and the hostname changes of t.
+func (p *tuiPrinter) renderDetails(b *strings.Builder, t *tuiTarget) {
+	b.WriteString(color.Yellow.Sprintf("\n%s %s:\n", t.target, msg("report.histogram")))
+
+	// only the buckets from the lowest to the highest RTT are drawn
+	first, last := -1, -1
+	for i, bucket := range t.histogram {
+		if bucket.Count > 0 {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first == -1 {
+		b.WriteString("  " + msg("report.none") + "\n")
+	} else {
+		largest := largestCount(t.histogram)
+		for _, bucket := range t.histogram[first : last+1] {
+			fmt.Fprintf(b, "  ≤ %-6s %6d %s\n", bucket.Le, bucket.Count,
+				color.LightBlue.Sprint(histogramBar(bucket.Count, largest, tuiHistogramWidth)))
+		}
+	}
+
+	b.WriteString(color.Yellow.Sprintf("\n%s:\n", msgf("tui.outages", tuiLogLength)))
+	if len(t.outages) == 0 {
+		b.WriteString("  " + msg("report.none") + "\n")
+	}
+	for _, o := range t.outages {
+		if o.end.IsZero() {
+			b.WriteString(color.Red.Sprintf("  %s\n", msgf("tui.ongoing-outage", o.start.Format(timeFormat), durationToString(time.Since(o.start)))))
+		} else {
+			fmt.Fprintf(b, "  %s %s\n", msgf("report.from-to", o.start.Format(timeFormat), o.end.Format(timeFormat)), durationToString(o.end.Sub(o.start)))
+		}
+	}
+
+	b.WriteString(color.Yellow.Sprintf("\n%s:\n", msg("stats.hostname-changes")))
+	if len(t.changes) == 0 {
+		b.WriteString("  " + msg("report.none") + "\n")
+	}
+	for _, change := range t.changes {
+		b.WriteString("  " + change + "\n")
+	}
+}
+
+func (p *tuiPrinter) printStart(hostname string, port uint16) {
+	if !p.isOpen() {
+		p.fallback.printStart(hostname, port)
+	}
+}
+
+func (p *tuiPrinter) printProbeSuccess(_ string, userInput userInput, streak uint, rtt float32, info probeInfo) {
+	p.addProbe(userInput, streak, true, rtt, info)
+}
+
+func (p *tuiPrinter) printProbeFail(userInput userInput, streak uint, _ errorKind, info probeInfo) {
+	p.addProbe(userInput, streak, false, 0, info)
+}
+
+func (p *tuiPrinter) printRetryingToResolve(hostname string) {
+	p.addMessage(msg("probe.retrying"), hostname)
+}
+
+func (p *tuiPrinter) printTotalDownTime(userInput userInput, downtime time.Duration) {
+	p.mu.Lock()
+	t := p.target(userInput)
+	if len(t.outages) > 0 && t.outages[len(t.outages)-1].end.IsZero() {
+		o := &t.outages[len(t.outages)-1]
+		o.end = o.start.Add(downtime)
+	}
+	p.mu.Unlock()
+
+	p.addMessage(msg("tui.recovered"), userInput.target(), durationToString(downtime))
+}
+
+func (p *tuiPrinter) printStateChange(userInput userInput, state targetState) {
+	p.mu.Lock()
+	p.target(userInput).state = state
+	p.mu.Unlock()
+
+	p.addMessage(msg("tui.state-change"), userInput.target(), state.description())
+}
+
+// printCheckResult is printed by the fallback printer after the statistics on exit.
+func (p *tuiPrinter) printCheckResult(userInput userInput, result checkResult) {
+	p.fallback.printCheckResult(userInput, result)
+}
+
+// printAddressSummary is printed by the fallback printer after the statistics on exit.
+func (p *tuiPrinter) printAddressSummary(summary addressSummary) {
+	p.fallback.printAddressSummary(summary)
+}
+
+// printDualStackSummary is printed by the fallback printer after the statistics on exit.
+func (p *tuiPrinter) printDualStackSummary(summary dualStackSummary) {
+	p.fallback.printDualStackSummary(summary)
+}
+
+// printStatistics is ignored while the dashboard is shown, as it's always up to date.
+func (p *tuiPrinter) printStatistics(t tcping) {
+	if !p.isOpen() {
+		p.fallback.printStatistics(t)
+	}
+}
+
+func (p *tuiPrinter) printSessions(file string, sessions []reportSession) {
+	p.fallback.printSessions(file, sessions)
+}
+
+func (p *tuiPrinter) printReport(r sessionReport) {
+	p.fallback.printReport(r)
+}
+
+func (p *tuiPrinter) printVersion() {
+	p.fallback.printVersion()
+}
+
+func (p *tuiPrinter) printInfo(format string, args ...any) {
+	if p.isOpen() {
+		p.addMessage(format, args...)
+		return
+	}
+	p.fallback.printInfo(format, args...)
+}
+
+func (p *tuiPrinter) printWarning(format string, args ...any) {
+	if p.isOpen() {
+		p.addMessage(format, args...)
+		return
+	}
+	p.fallback.printWarning(format, args...)
+}
+
+func (p *tuiPrinter) printError(format string, args ...any) {
+	if p.isOpen() {
+		p.addMessage(format, args...)
+		return
+	}
+	p.fallback.printError(format, args...)
+}
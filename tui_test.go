@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTUIPrinter creates a tuiPrinter writing to a buffer.
+func newTestTUIPrinter() *tuiPrinter {
+	p := newTUIPrinter(new(bool))
+	p.out = &bytes.Buffer{}
+	p.fallback = &dummyPrinter{}
+	return p
+}
+
+func TestTUIProbes(t *testing.T) {
+	p := newTestTUIPrinter()
+	u := userInput{hostname: "example.com", ip: netip.MustParseAddr("192.0.2.1"), port: 443}
+
+	downSince := time.Now()
+	p.printProbeSuccess("", u, 1, 10, probeInfo{state: stateUp})
+	p.printProbeFail(u, 1, errorKindTimeout, probeInfo{state: stateDown, downSince: downSince})
+	p.printProbeFail(u, 2, errorKindTimeout, probeInfo{state: stateDown, downSince: downSince})
+
+	require.Len(t, p.targets, 1)
+	target := p.targets[0]
+	assert.Equal(t, stateDown, target.state)
+	assert.Equal(t, uint(2), target.streak)
+	assert.Equal(t, uint(3), target.probes)
+	assert.InDelta(t, 66.67, target.rollingLoss(), 0.01)
+	require.Len(t, target.outages, 1)
+	assert.True(t, target.outages[0].end.IsZero())
+
+	u.ip = netip.MustParseAddr("192.0.2.2")
+	p.printTotalDownTime(u, time.Minute)
+	p.printProbeSuccess("", u, 1, 20, probeInfo{state: stateUp})
+
+	require.Len(t, target.outages, 1)
+	assert.Equal(t, downSince.Add(time.Minute), target.outages[0].end)
+	require.Len(t, target.changes, 1)
+	assert.Contains(t, target.changes[0], "from 192.0.2.1 to 192.0.2.2")
+	assert.Equal(t, uint(2), target.histogram[3].Count+target.histogram[4].Count)
+}
+
+func TestTUIOutagesFollowTheDeclaredState(t *testing.T) {
+	p := newTestTUIPrinter()
+	stats := createTestStats(t)
+	stats.printer = p
+	stats.userInput.downAfter = 2
+	stats.userInput.upAfter = 2
+
+	// a single failure doesn't make the target down
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 1, time.Now(), time.Second, probeInfo{})
+	target := p.targets[0]
+	assert.Empty(t, target.outages)
+
+	start := time.Now()
+	stats.handleConnError(start, time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnError(start.Add(time.Second), time.Second, errorKindTimeout, probeInfo{})
+	require.Len(t, target.outages, 1)
+	assert.Equal(t, start, target.outages[0].start, "the outage starts at the first failure")
+
+	// nor does a single success end the outage, even while the target is flapping
+	stats.flapping = true
+	stats.handleConnSuccess("", 1, start.Add(2*time.Second), time.Second, probeInfo{})
+	stats.handleConnError(start.Add(3*time.Second), time.Second, errorKindTimeout, probeInfo{})
+	require.Len(t, target.outages, 1)
+	assert.True(t, target.outages[0].end.IsZero())
+
+	stats.handleConnSuccess("", 1, start.Add(4*time.Second), time.Second, probeInfo{})
+	stats.handleConnSuccess("", 1, start.Add(5*time.Second), time.Second, probeInfo{})
+	require.Len(t, target.outages, 1)
+	assert.Equal(t, start.Add(4*time.Second), target.outages[0].end)
+}
+
+func TestTUIRender(t *testing.T) {
+	// the Chinese messages are wider than their length, and should still be aligned
+	useLanguage(t, languageChinese)
+	color.Disable()
+
+	p := newTestTUIPrinter()
+	for _, port := range []uint16{443, 22} {
+		u := userInput{ip: netip.MustParseAddr("192.0.2.1"), port: port}
+		p.printProbeSuccess("", u, 1, 10, probeInfo{state: stateUp})
+		p.printProbeFail(u, 1, errorKindRefused, probeInfo{state: stateDown, downSince: time.Now()})
+	}
+	// messages are shown on the dashboard instead of being printed
+	p.open = true
+	p.printError("出错了")
+
+	screen := p.render()
+	lines := strings.Split(screen, "\n")
+
+	assert.Contains(t, lines[3], "> 192.0.2.1:443")
+	assert.Contains(t, lines[3], "离线")
+	assert.Contains(t, lines[3], "失败 1")
+	assert.Contains(t, lines[3], "50.00%")
+	assert.Contains(t, lines[3], "█x")
+	assert.Contains(t, lines[4], "  192.0.2.1:22")
+	assert.Contains(t, screen, "192.0.2.1:443 rtt 直方图")
+	assert.Contains(t, screen, "进行中")
+	assert.Contains(t, screen, "出错了")
+
+	// the columns are aligned regardless of the width of the characters
+	assert.Equal(t, displayWidth(lines[2][:strings.Index(lines[2], "状态")]), displayWidth(lines[3][:strings.Index(lines[3], "离线")]))
+}
+
+func TestTUIHandleKey(t *testing.T) {
+	p := newTestTUIPrinter()
+	for _, port := range []uint16{443, 22} {
+		p.printProbeSuccess("", userInput{ip: netip.MustParseAddr("192.0.2.1"), port: port}, 1, 10, probeInfo{})
+	}
+
+	cmd, ok := p.handleKey('p')
+	assert.True(t, ok)
+	assert.Equal(t, commandPause, cmd)
+	assert.True(t, p.paused)
+
+	cmd, _ = p.handleKey(' ')
+	assert.Equal(t, commandResume, cmd)
+	assert.False(t, p.paused)
+
+	_, ok = p.handleKey('\t')
+	assert.False(t, ok)
+	assert.Equal(t, 1, p.selected)
+	p.handleKey('\t')
+	assert.Equal(t, 0, p.selected)
+
+	cmd, _ = p.handleKey('r')
+	assert.Equal(t, commandReset, cmd)
+	assert.Zero(t, p.targets[0].probes)
+	assert.Equal(t, "192.0.2.1:443", p.targets[0].target)
+
+	for _, key := range []byte{'q', 3} {
+		cmd, ok = p.handleKey(key)
+		assert.True(t, ok)
+		assert.Equal(t, commandQuit, cmd)
+	}
+
+	_, ok = p.handleKey('z')
+	assert.False(t, ok)
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁x█▅", sparkline([]tuiProbe{{true, 0}, {false, 0}, {true, 8}, {true, 5}}))
+	assert.Equal(t, "▁▁", sparkline([]tuiProbe{{true, 0}, {true, 0}}))
+}
+
+func TestAppendWindow(t *testing.T) {
+	var window []int
+	for i := range 5 {
+		window = appendWindow(window, i, 3)
+	}
+	assert.Equal(t, []int{2, 3, 4}, window)
+}
+
+func TestPadRight(t *testing.T) {
+	assert.Equal(t, "ab  ", padRight("ab", 4))
+	assert.Equal(t, "在线  ", padRight("在线", 6))
+	assert.Equal(t, "abcdef", padRight("abcdef", 4))
+}
+
+func TestRunCommand(t *testing.T) {
+	stats := createTestStats(t)
+	stats.handleConnError(time.Now(), time.Second, errorKindTimeout, probeInfo{})
+	stats.handleConnSuccess("", 5, time.Now(), time.Second, probeInfo{})
+
+	assert.True(t, stats.runCommand(commandPause, false))
+	assert.True(t, stats.runCommand(commandPrintStats, true))
+	assert.False(t, stats.runCommand(commandResume, true))
+
+	printer := stats.printer
+	userInput := stats.userInput
+	assert.False(t, stats.runCommand(commandReset, false))
+	assert.Zero(t, stats.totalSuccessfulProbes)
+	assert.Zero(t, stats.totalUnsuccessfulProbes)
+	assert.Zero(t, stats.stateChanges)
+	assert.Equal(t, stateUp, stats.state())
+	assert.Equal(t, printer, stats.printer)
+	assert.Equal(t, userInput, stats.userInput)
+	assert.Len(t, stats.hostnameChanges, 1)
+	assert.False(t, stats.startTime.IsZero())
+}
@@ -0,0 +1,200 @@
+// udp.go contains the logic of --udp, probing a target with UDP datagrams
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxUDPReplySize is the size of the buffer a reply is read into.
+const maxUDPReplySize = 64 * 1024
+
+// errUnexpectedReply is returned when a reply does not match --udp-expect.
+var errUnexpectedReply = errors.New("unexpected reply")
+
+// udpPresets are ready-made payloads for common UDP services.
+var udpPresets = map[string][]byte{
+	// dns is a recursive query for the NS records of the root zone.
+	"dns": {
+		0x74, 0x63, // ID
+		0x01, 0x00, // flags: standard query, recursion desired
+		0x00, 0x01, // QDCOUNT
+		0x00, 0x00, // ANCOUNT
+		0x00, 0x00, // NSCOUNT
+		0x00, 0x00, // ARCOUNT
+		0x00,       // QNAME: the root zone
+		0x00, 0x02, // QTYPE: NS
+		0x00, 0x01, // QCLASS: IN
+	},
+	// ntp is an NTPv3 client request.
+	"ntp": append([]byte{0x1b}, make([]byte, 47)...),
+}
+
+// udpInfo describes the reply of a probe in --udp mode.
+type udpInfo struct {
+	size int // size is the length of the reply in bytes
+}
+
+// udpProbe holds the payload and the expected reply of the --udp probe mode.
+type udpProbe struct {
+	payload []byte
+	expect  *regexp.Regexp // expect is nil when any reply is accepted
+}
+
+// udpOptions holds the flags of the --udp probe mode.
+// At most one of the payload options can be set.
+type udpOptions struct {
+	payload     string
+	payloadHex  string
+	payloadFile string
+	preset      string
+	expect      string
+}
+
+// newUDPProbe validates the flags of the --udp probe mode.
+func newUDPProbe(opts udpOptions) (*udpProbe, error) {
+	sources := 0
+	for _, s := range []string{opts.payload, opts.payloadHex, opts.payloadFile, opts.preset} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, msgErr("udp.payloads")
+	}
+
+	p := &udpProbe{}
+
+	switch {
+	case opts.payload != "":
+		p.payload = []byte(opts.payload)
+
+	case opts.payloadHex != "":
+		s := strings.Join(strings.Fields(opts.payloadHex), "")
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+		payload, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf(msg("udp.invalid-hex"), err)
+		}
+		p.payload = payload
+
+	case opts.payloadFile != "":
+		payload, err := os.ReadFile(opts.payloadFile)
+		if err != nil {
+			return nil, err
+		}
+		p.payload = payload
+
+	case opts.preset != "":
+		payload, ok := udpPresets[strings.ToLower(opts.preset)]
+		if !ok {
+			return nil, fmt.Errorf(msg("udp.unknown-preset"), opts.preset)
+		}
+		p.payload = payload
+	}
+
+	if opts.expect != "" {
+		re, err := regexp.Compile(opts.expect)
+		if err != nil {
+			return nil, fmt.Errorf(msg("error.invalid-regexp"), opts.expect, err)
+		}
+		p.expect = re
+	}
+
+	return p, nil
+}
+
+// dialUDP creates a UDP socket connected to the target,
+// bound to the address of the interface given with -I, if any.
+func dialUDP(u userInput) (net.Conn, error) {
+	addr := netip.AddrPortFrom(u.ip, u.port).String()
+
+	if !u.networkInterface.use {
+		return net.DialTimeout("udp", addr, u.timeout)
+	}
+
+	dialer := u.networkInterface.dialer
+	if local, ok := dialer.LocalAddr.(*net.TCPAddr); ok {
+		dialer.LocalAddr = &net.UDPAddr{IP: local.IP}
+	}
+
+	return dialer.Dial("udp", addr)
+}
+
+// do sends the payload over conn and waits for a reply within the timeout.
+// It returns the time between sending the payload and receiving the reply in ms.
+func (p *udpProbe) do(conn net.Conn, timeout time.Duration) (float32, *udpInfo, error) {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	sendTime := time.Now()
+	if _, err := conn.Write(p.payload); err != nil {
+		return 0, nil, err
+	}
+
+	reply := make([]byte, maxUDPReplySize)
+	n, err := conn.Read(reply)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rtt := nanoToMillisecond(time.Since(sendTime).Nanoseconds())
+	info := &udpInfo{size: n}
+
+	if p.expect != nil && !p.expect.Match(reply[:n]) {
+		return rtt, info, errUnexpectedReply
+	}
+
+	return rtt, info, nil
+}
+
+// classifyUDPError maps an error of the UDP exchange to an errorKind.
+func classifyUDPError(err error) errorKind {
+	if errors.Is(err, errUnexpectedReply) {
+		return errorKindUnexpectedReply
+	}
+
+	return classifyDialError(err)
+}
+
+// udpPing pings a host, UDP style.
+//
+// A probe succeeds when a reply, matching --udp-expect if given,
+// is received within the timeout.
+func udpPing(tcping *tcping) {
+	var info probeInfo
+	info.timings[phaseDNS] = tcping.pendingDNS
+	tcping.pendingDNS = 0
+
+	probeStart := time.Now()
+
+	conn, err := dialUDP(tcping.userInput)
+	if err != nil {
+		elapsed := maxDuration(time.Since(probeStart), tcping.userInput.intervalBetweenProbes)
+		tcping.handleConnError(probeStart, elapsed, classifyDialError(err), info)
+		<-tcping.ticker.C
+		return
+	}
+
+	var rtt float32
+	rtt, info.udp, err = tcping.userInput.udp.do(conn, tcping.userInput.timeout)
+
+	elapsed := maxDuration(time.Since(probeStart), tcping.userInput.intervalBetweenProbes)
+
+	if err != nil {
+		tcping.handleConnError(probeStart, elapsed, classifyUDPError(err), info)
+	} else {
+		tcping.handleConnSuccess(conn.LocalAddr().String(), rtt, probeStart, elapsed, info)
+	}
+	conn.Close()
+
+	<-tcping.ticker.C
+}
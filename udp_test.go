@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUDPServer starts a UDP server on a random port that answers
+// every datagram with reply, or not at all if reply is nil.
+func testUDPServer(t *testing.T, reply []byte) netip.AddrPort {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxUDPReplySize)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return netip.MustParseAddrPort(conn.LocalAddr().String())
+}
+
+// newUDPTestStats creates stats that probe addr in --udp mode.
+func newUDPTestStats(t *testing.T, addr netip.AddrPort, opts udpOptions) *tcping {
+	udp, err := newUDPProbe(opts)
+	require.NoError(t, err)
+
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.ip = addr.Addr()
+	stats.userInput.port = addr.Port()
+	stats.userInput.timeout = 200 * time.Millisecond
+	stats.userInput.udp = udp
+
+	return stats
+}
+
+func TestUDPProbeSuccess(t *testing.T) {
+	addr := testUDPServer(t, []byte("pong"))
+
+	stats := newUDPTestStats(t, addr, udpOptions{payload: "ping", expect: "^po"})
+	probe(stats)
+
+	assert.Equal(t, uint(1), stats.totalSuccessfulProbes)
+	assert.True(t, stats.rtt.result().hasResults)
+}
+
+func TestUDPProbeUnexpectedReply(t *testing.T) {
+	addr := testUDPServer(t, []byte("error"))
+
+	stats := newUDPTestStats(t, addr, udpOptions{preset: "ntp", expect: "^ok"})
+	probe(stats)
+
+	assert.Equal(t, uint(1), stats.failuresByKind[errorKindUnexpectedReply])
+	assert.Equal(t, "udp", stats.userInput.errorLayer(errorKindUnexpectedReply))
+}
+
+func TestUDPProbeTimeout(t *testing.T) {
+	addr := testUDPServer(t, nil)
+
+	stats := newUDPTestStats(t, addr, udpOptions{preset: "dns"})
+	probe(stats)
+
+	assert.Equal(t, uint(1), stats.failuresByKind[errorKindTimeout])
+	assert.Equal(t, "udp", stats.userInput.errorLayer(errorKindTimeout))
+}
+
+func TestNewUDPProbePayloads(t *testing.T) {
+	p, err := newUDPProbe(udpOptions{payloadHex: "0xde ad BE ef"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, p.payload)
+
+	file := filepath.Join(t.TempDir(), "payload.bin")
+	require.NoError(t, os.WriteFile(file, []byte{1, 2, 3}, 0o600))
+	p, err = newUDPProbe(udpOptions{payloadFile: file})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, p.payload)
+
+	p, err = newUDPProbe(udpOptions{preset: "NTP"})
+	require.NoError(t, err)
+	assert.Len(t, p.payload, 48)
+	assert.Equal(t, byte(0x1b), p.payload[0])
+
+	p, err = newUDPProbe(udpOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, p.payload)
+	assert.Nil(t, p.expect)
+}
+
+func TestNewUDPProbeInvalid(t *testing.T) {
+	for _, opts := range []udpOptions{
+		{payload: "a", preset: "dns"},
+		{payloadHex: "xyz"},
+		{payloadFile: filepath.Join(t.TempDir(), "missing")},
+		{preset: "smtp"},
+		{expect: "("},
+	} {
+		_, err := newUDPProbe(opts)
+		assert.Error(t, err, opts)
+	}
+}